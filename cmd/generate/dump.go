@@ -0,0 +1,48 @@
+package generate
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/generator/dump"
+	"github.com/jimmidyson/kube-client-gen/pkg/ir"
+)
+
+func newDumpCommand(run generatorRunner) *cobra.Command {
+	var irFile, irFormat string
+
+	cmd := &cobra.Command{
+		Use:   "dump",
+		Short: "Intermediate representation of the loaded packages",
+		Run: func(cmd *cobra.Command, args []string) {
+			// Dumping to - is dumping with --stdout, whatever the file is
+			// called.
+			if irFile == "-" {
+				if *archiveFile != "" || *verify {
+					config.Logger.Crit("--ir-file - cannot be combined with --archive or --verify")
+					os.Exit(1)
+				}
+				*toStdout = true
+				irFile = "ir." + irFormat
+			}
+			run("dump", func(c generator.Config) generator.Generator {
+				return dump.New(dump.Config{
+					Config: c,
+					IRFile: irFile,
+					Format: irFormat,
+				})
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&irFile, "ir-file", "ir.json", "file to write the IR to, or - for stdout as with --stdout")
+	cmd.Flags().StringVar(&irFormat, "format", ir.FormatJSON, "IR format, one of json or yaml")
+
+	return cmd
+}
+
+func init() {
+	addGeneratorCommand(newDumpCommand)
+}
@@ -1,14 +1,21 @@
 package generate
 
 import (
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/ir"
 	"github.com/jimmidyson/kube-client-gen/pkg/loader"
 	"github.com/jimmidyson/kube-client-gen/pkg/log"
+	"github.com/jimmidyson/kube-client-gen/pkg/project"
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
 )
 
 var (
@@ -61,35 +68,355 @@ var (
 			}
 			logger.SetHandler(log15.LvlFilterHandler(logLvl, log.Log.GetHandler()))
 
+			p, err := readProject(logger)
+			if err != nil {
+				logger.Error("failed to read project file", "error", err)
+				os.Exit(1)
+			}
+			if p != nil {
+				applyProject(cmd.Flags(), p)
+			}
+			proj = p
+
 			config = generator.Config{
 				Logger:          logger,
 				Force:           *force,
 				OutputDirectory: *outputDirectory,
 			}
 
-			ldr := loader.New(*packages, logger)
-			pkgs, err := ldr.Load()
+			if *headerFile != "" {
+				header, err := os.ReadFile(*headerFile)
+				if err != nil {
+					logger.Error("failed to read header file", "error", err)
+					os.Exit(1)
+				}
+				config.Header = string(header)
+			}
+
+			if *typeMappingsFile != "" {
+				mappings, err := typemap.ReadFile(*typeMappingsFile)
+				if err != nil {
+					logger.Error("failed to read type mappings", "error", err)
+					os.Exit(1)
+				}
+				config.TypeMappings = mappings.Targets
+			} else if proj != nil {
+				config.TypeMappings = proj.TypeMappings
+			}
+
+			if err := openArchive(); err != nil {
+				logger.Error("failed to open archive", "error", err)
+				os.Exit(1)
+			}
+
+			pkgs, err := loadPackages(logger)
 			if err != nil {
 				logger.Error("failed to parse packages", "error", err)
 				os.Exit(1)
 			}
 			parsedPackages = pkgs
 		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			if err := closeArchive(); err != nil {
+				config.Logger.Crit("failed to write archive", "error", err)
+				os.Exit(1)
+			}
+			if failed {
+				os.Exit(1)
+			}
+		},
 	}
 
-	packages        *[]string
-	verbose         *bool
-	outputDirectory *string
-	force           *bool
+	projectFile      *string
+	packages         *[]string
+	verbose          *bool
+	outputDirectory  *string
+	force            *bool
+	verify           *bool
+	keepGoing        *bool
+	headerFile       *string
+	archiveFile      *string
+	archiveFormat    *string
+	toStdout         *bool
+	moduleDir        *string
+	modFlag          *string
+	fromIR           *string
+	typeMappingsFile *string
+	typeClosure      *bool
+	includes         *[]string
+	excludes         *[]string
+	roots            *[]string
 
 	defaultLogLevel = log15.LvlInfo
 	config          generator.Config
+	proj            *project.Config
 	parsedPackages  []loader.Package
+	// failed is set when a verified generator's output differs from the
+	// output directory, or when a generator found problems with some types
+	// but kept going.
+	failed bool
+	// archive receives the files of every generator run when writing an
+	// archive, and archiveWriter is the file it is written to.
+	archive       generator.ArchiveOutput
+	archiveWriter io.WriteCloser
 )
 
 func init() {
+	projectFile = RootCmd.PersistentFlags().String("config", "", "project file to read, defaults to "+project.DefaultFile+" if it exists; flags override its values")
 	packages = RootCmd.PersistentFlags().StringSliceP("package", "p", defaultAPIPackages, "packages to generate JSON schema for")
 	verbose = RootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
 	outputDirectory = RootCmd.PersistentFlags().StringP("output-directory", "o", "", "the directory to output generated files to")
 	force = RootCmd.PersistentFlags().BoolP("force", "f", false, "force overwrite of existing files")
+	verify = RootCmd.PersistentFlags().Bool("verify", false, "render in memory and print a diff against the output directory instead of writing files, exiting non-zero if they differ")
+	keepGoing = RootCmd.PersistentFlags().Bool("keep-going", false, "write the files of all types that can be generated when others have problems, exiting non-zero after reporting them")
+	archiveFile = RootCmd.PersistentFlags().String("archive", "", "write generated files to this zip or tar archive instead of the output directory, or - for stdout")
+	archiveFormat = RootCmd.PersistentFlags().String("archive-format", "", "archive format, one of zip, tar or tgz, defaulting to the format matching the --archive extension, or zip")
+	toStdout = RootCmd.PersistentFlags().Bool("stdout", false, "write the contents of the generated file to stdout instead of the output directory, for generators that write a single file")
+	headerFile = RootCmd.PersistentFlags().String("header-file", "", "license header to add to generated files, such as header.txt, in the comment syntax of each format")
+	moduleDir = RootCmd.PersistentFlags().StringP("dir", "C", "", "directory within the Go module to resolve packages from")
+	modFlag = RootCmd.PersistentFlags().String("mod", "", "module download mode to use when resolving packages: readonly, vendor or mod")
+	typeClosure = RootCmd.PersistentFlags().Bool("closure", false, "also generate types from other packages that types in the requested packages refer to")
+	includes = RootCmd.PersistentFlags().StringSlice("include", nil, "only generate types whose package qualified name, such as k8s.io/kubernetes/pkg/api/v1.Pod, matches one of these glob patterns")
+	excludes = RootCmd.PersistentFlags().StringSlice("exclude", nil, "do not generate types whose package qualified name matches one of these glob patterns")
+	roots = RootCmd.PersistentFlags().StringSlice("root", nil, "only generate types reachable from types whose kind, such as Deployment, or package qualified name matches one of these glob patterns")
+	typeMappingsFile = RootCmd.PersistentFlags().String("type-mappings", "", "JSON or YAML file mapping Go types to the types to generate for them, per target")
+	fromIR = RootCmd.PersistentFlags().String("from-ir", "", "load packages from a JSON or YAML IR file written by dump instead of type-checking them")
+}
+
+func loadPackages(logger log15.Logger) ([]loader.Package, error) {
+	if *fromIR != "" {
+		logger.Debug("loading packages from IR", "file", *fromIR)
+		doc, err := ir.ReadFile(*fromIR)
+		if err != nil {
+			return nil, err
+		}
+		return doc.ToLoader()
+	}
+
+	var loaderOpts []loader.Option
+	if *moduleDir != "" {
+		loaderOpts = append(loaderOpts, loader.WithDir(*moduleDir))
+	}
+	if *modFlag != "" {
+		loaderOpts = append(loaderOpts, loader.WithBuildFlags("-mod="+*modFlag))
+	}
+	if *typeClosure {
+		loaderOpts = append(loaderOpts, loader.WithTypeClosure())
+	}
+	if len(*includes) > 0 {
+		loaderOpts = append(loaderOpts, loader.WithIncludes(*includes...))
+	}
+	if len(*excludes) > 0 {
+		loaderOpts = append(loaderOpts, loader.WithExcludes(*excludes...))
+	}
+	if len(*roots) > 0 {
+		loaderOpts = append(loaderOpts, loader.WithRoots(*roots...))
+	}
+
+	return loader.New(*packages, logger, loaderOpts...).Load()
+}
+
+// runLoaded runs a generator with the configuration of the command line over
+// the packages it loaded.
+func runLoaded(name string, newGenerator func(generator.Config) generator.Generator) {
+	runGenerator(config, parsedPackages, name, newGenerator)
+}
+
+// runGenerator runs the generator created by newGenerator with c over pkgs,
+// writing its files to the output directory or, when verifying, comparing
+// them with it. Files written to the output directory are recorded in the
+// generator's manifest there, and files of the previous manifest that
+// are no longer generated are deleted. A generator that found problems with
+// some types writes nothing, unless keeping going, when it writes everything
+// else and the command exits non-zero once done.
+func runGenerator(c generator.Config, pkgs []loader.Package, name string, newGenerator func(generator.Config) generator.Generator) {
+	var out generator.Output
+	var recorder *generator.ManifestOutput
+	switch {
+	case *verify:
+		c.Force = true
+	case archive != nil:
+		out = generator.NewPrefixOutput(archivePrefix(c.OutputDirectory), archive)
+		c.Force = true
+	case *toStdout:
+		out = generator.NewWriterOutput(os.Stdout)
+		c.Force = true
+	default:
+		recorder = generator.NewManifestOutput(name, generator.NewFileOutput(c.OutputDirectory, c.Force))
+		out = recorder
+	}
+
+	// Files are staged in memory, so nothing is written unless the generator
+	// succeeds or keeps going past the problems it found.
+	mem := generator.NewMemoryOutput()
+	c.Output = mem
+
+	var previous *generator.Manifest
+	if *verify || recorder != nil {
+		var err error
+		previous, err = generator.ReadManifest(c.OutputDirectory, name)
+		if err != nil {
+			c.Logger.Crit("failed to read manifest", "type", name, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	err := newGenerator(c).Generate(pkgs)
+	var diags generator.DiagnosticsError
+	if errors.As(err, &diags) {
+		for _, d := range diags {
+			c.Logger.Error(d.String(), "type", name)
+		}
+		if !*keepGoing {
+			c.Logger.Crit("failed to generate, use --keep-going to generate all but the types with problems", "type", name, "problems", len(diags))
+			os.Exit(1)
+		}
+		c.Logger.Error("generated all but the types with problems", "type", name, "problems", len(diags))
+		failed = true
+	} else if err != nil {
+		c.Logger.Crit("failed to generate", "type", name, "error", err)
+		os.Exit(1)
+	}
+	partial := diags != nil
+
+	if !*verify {
+		if *toStdout && len(mem.Files) > 1 {
+			c.Logger.Crit("--stdout takes a single file, use --archive - to stream the files of this generator", "type", name, "files", len(mem.Files))
+			os.Exit(1)
+		}
+		if err := mem.CopyTo(out); err != nil {
+			c.Logger.Crit("failed to write generated files", "type", name, "error", err)
+			os.Exit(1)
+		}
+		if recorder != nil {
+			writeManifest(c, recorder.Manifest(), previous, partial)
+		}
+		return
+	}
+	upToDate, err := generator.Verify(mem.Files, previous, c.OutputDirectory, os.Stdout)
+	if err != nil {
+		c.Logger.Crit("failed to verify", "type", name, "error", err)
+		os.Exit(1)
+	}
+	if !upToDate {
+		c.Logger.Error("generated files are out of date", "type", name, "outputDirectory", c.OutputDirectory)
+		failed = true
+	}
+}
+
+// writeManifest prunes the files of the previous manifest that were not
+// generated again and writes the new manifest. Runs that write no files, such
+// as dumping the IR to stdout, leave the previous manifest in place. Partial
+// runs, which skipped types with problems, prune nothing and keep the files of
+// the previous manifest in the new one.
+func writeManifest(c generator.Config, m, previous *generator.Manifest, partial bool) {
+	if len(m.Files) == 0 {
+		return
+	}
+	if partial {
+		if previous != nil {
+			for name, hash := range previous.Files {
+				if _, ok := m.Files[name]; !ok {
+					m.Files[name] = hash
+				}
+			}
+		}
+		previous = nil
+	}
+
+	deleted, modified, err := m.Prune(c.OutputDirectory, previous)
+	for _, name := range deleted {
+		c.Logger.Info("deleted stale file", "file", name)
+	}
+	for _, name := range modified {
+		c.Logger.Warn("keeping stale file that was changed since it was generated", "file", name)
+	}
+	if err != nil {
+		c.Logger.Crit("failed to prune stale files", "error", err)
+		os.Exit(1)
+	}
+
+	if err := m.Write(c.OutputDirectory); err != nil {
+		c.Logger.Crit("failed to write manifest", "error", err)
+		os.Exit(1)
+	}
+}
+
+func openArchive() error {
+	if *archiveFile == "" {
+		return nil
+	}
+	if *verify || *toStdout {
+		return errors.New("--archive cannot be combined with --verify or --stdout")
+	}
+
+	format := *archiveFormat
+	if format == "" {
+		format = archiveFormatOf(*archiveFile)
+	}
+
+	var w io.WriteCloser = nopCloser{os.Stdout}
+	if *archiveFile != "-" {
+		f, err := os.Create(*archiveFile)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create archive %s", *archiveFile)
+		}
+		w = f
+	}
+
+	switch format {
+	case "zip":
+		archive = generator.NewZipOutput(w)
+	case "tar":
+		archive = generator.NewTarOutput(w, false)
+	case "tgz":
+		archive = generator.NewTarOutput(w, true)
+	default:
+		_ = w.Close()
+		return errors.Errorf("unknown archive format %s", format)
+	}
+	archiveWriter = w
+	return nil
+}
+
+func closeArchive() error {
+	if archive == nil {
+		return nil
+	}
+	err := archive.Close()
+	if closeErr := archiveWriter.Close(); err == nil {
+		err = errors.Wrapf(closeErr, "failed to close archive %s", *archiveFile)
+	}
+	archive, archiveWriter = nil, nil
+	return err
+}
+
+func archiveFormatOf(file string) string {
+	switch {
+	case strings.HasSuffix(file, ".tar"):
+		return "tar"
+	case strings.HasSuffix(file, ".tar.gz"), strings.HasSuffix(file, ".tgz"):
+		return "tgz"
+	default:
+		return "zip"
+	}
+}
+
+// archivePrefix returns the directory in the archive for files generated to
+// dir, so targets of a project file keep their output directories relative
+// to the project's.
+func archivePrefix(dir string) string {
+	rel, err := filepath.Rel(*outputDirectory, dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
 }
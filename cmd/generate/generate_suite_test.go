@@ -0,0 +1,26 @@
+package generate_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Generate Suite")
+}
+
+var binary string
+
+var _ = BeforeSuite(func() {
+	var err error
+	binary, err = gexec.Build("github.com/jimmidyson/kube-client-gen")
+	Expect(err).NotTo(HaveOccurred())
+})
+
+var _ = AfterSuite(func() {
+	gexec.CleanupBuildArtifacts()
+})
@@ -0,0 +1,114 @@
+package generate_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "generate")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	cli := func(args ...string) (string, int) {
+		cmd := exec.Command(binary, args...)
+		var stdout bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = GinkgoWriter
+		err := cmd.Run()
+		if _, ok := err.(*exec.ExitError); !ok {
+			Expect(err).NotTo(HaveOccurred())
+		}
+		return stdout.String(), cmd.ProcessState.ExitCode()
+	}
+
+	run := func(args ...string) (string, int) {
+		return cli(append([]string{
+			"jsonschema",
+			"-C", "../../pkg/generator/testdata/module",
+			"-p", "k8s.io/kubernetes/pkg/api/v1",
+			"-o", dir,
+		}, args...)...)
+	}
+
+	It("exits non-zero and writes nothing when verifying files that are out of date", func() {
+		stdout, code := run("--verify")
+		Expect(code).To(Equal(1))
+		Expect(stdout).To(HavePrefix("--- /dev/null\n+++ b/kube-schema.json\n"))
+
+		entries, err := os.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("exits zero when verifying files that are up to date", func() {
+		_, code := run()
+		Expect(code).To(Equal(0))
+		schema, err := os.ReadFile(filepath.Join(dir, "kube-schema.json"))
+		Expect(err).NotTo(HaveOccurred())
+
+		stdout, code := run("--verify")
+		Expect(code).To(Equal(0))
+		Expect(stdout).To(BeEmpty())
+
+		Expect(os.WriteFile(filepath.Join(dir, "kube-schema.json"), []byte("{}\n"), 0644)).To(Succeed())
+		stdout, code = run("--verify")
+		Expect(code).To(Equal(1))
+		Expect(stdout).To(HavePrefix("--- a/kube-schema.json\n+++ b/kube-schema.json\n"))
+		Expect(os.ReadFile(filepath.Join(dir, "kube-schema.json"))).To(Equal([]byte("{}\n")))
+		Expect(schema).NotTo(Equal([]byte("{}\n")))
+	})
+
+	It("generates each target of the project file with its own options and packages", func() {
+		module, err := filepath.Abs("../../pkg/generator/testdata/module")
+		Expect(err).NotTo(HaveOccurred())
+		projectFile := filepath.Join(dir, "kube-client-gen.yaml")
+		Expect(os.WriteFile(projectFile, []byte(`version: 1
+packages:
+  core:
+    - k8s.io/kubernetes/pkg/api/unversioned
+    - k8s.io/kubernetes/pkg/api/v1
+  apps:
+    - k8s.io/kubernetes/pkg/apis/apps/v1
+dir: `+module+`
+outputDirectory: `+dir+`
+targets:
+  - name: core
+    generator: jsonschema
+    outputDirectory: core
+    packages: [core]
+    options:
+      title: Core
+  - name: all
+    generator: jsonschema
+    outputDirectory: all
+`), 0644)).To(Succeed())
+
+		_, code := cli("generate", "--config", projectFile)
+		Expect(code).To(Equal(0))
+
+		core, err := os.ReadFile(filepath.Join(dir, "core", "kube-schema.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(core)).To(ContainSubstring(`"title": "Core"`))
+		Expect(string(core)).To(ContainSubstring(`"k8s.io.kubernetes.pkg.api.v1.Pod"`))
+		Expect(string(core)).NotTo(ContainSubstring("Deployment"))
+
+		all, err := os.ReadFile(filepath.Join(dir, "all", "kube-schema.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(all)).To(ContainSubstring(`"title": "Kubernetes"`))
+		Expect(string(all)).To(ContainSubstring("Deployment"))
+	})
+})
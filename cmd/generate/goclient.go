@@ -0,0 +1,33 @@
+package generate
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/generator/goclient"
+)
+
+func newGoClientCommand(run generatorRunner) *cobra.Command {
+	var pkg string
+
+	cmd := &cobra.Command{
+		Use:   "goclient",
+		Short: "Go typed clientset",
+		Run: func(cmd *cobra.Command, args []string) {
+			run("goclient", func(c generator.Config) generator.Generator {
+				return goclient.New(goclient.Config{
+					Config:  c,
+					Package: pkg,
+				})
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&pkg, "clientset-package", "", "Go import path of the output directory, which the generated packages import each other by")
+
+	return cmd
+}
+
+func init() {
+	addGeneratorCommand(newGoClientCommand)
+}
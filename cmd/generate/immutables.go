@@ -2,47 +2,67 @@ package generate
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
 	"github.com/jimmidyson/kube-client-gen/pkg/generator/immutables"
 )
 
-var (
-	immutablesCmd = &cobra.Command{
+const (
+	defaultJavaRootPackage          = "io.fabric8.kubernetes.types"
+	defaultJavaRootOpenShiftPackage = "io.fabric8.openshift.types"
+)
+
+var defaultStylesClass = strings.Join([]string{defaultJavaRootPackage, "common", "ImmutablesStyle"}, ".")
+
+func newImmutablesCommand(run generatorRunner) *cobra.Command {
+	var javaRootPackage, javaRootOpenShiftPackage, stylesClass, parentPOM, parent string
+
+	cmd := &cobra.Command{
 		Use:   "immutables",
 		Short: "Java Immutables",
 		Run: func(cmd *cobra.Command, args []string) {
-			immConfig := immutables.Config{
-				Config:                   config,
-				JavaRootPackage:          *javaRootPackage,
-				StyleClass:               *stylesClass,
-				JavaRootOpenShiftPackage: *javaRootOpenShiftPackage,
-			}
-			gen := immutables.New(immConfig)
-			err := gen.Generate(parsedPackages)
-			if err != nil {
-				config.Logger.Crit("failed to generate", "type", "immutables", "error", err)
-				os.Exit(1)
-			}
+			run("immutables", func(c generator.Config) generator.Generator {
+				p, err := readParent(c.OutputDirectory, parentPOM, parent)
+				if err != nil {
+					c.Logger.Crit("failed to read parent POM", "error", err)
+					os.Exit(1)
+				}
+				return immutables.New(immutables.Config{
+					Config:                   c,
+					JavaRootPackage:          javaRootPackage,
+					StyleClass:               stylesClass,
+					JavaRootOpenShiftPackage: javaRootOpenShiftPackage,
+					Parent:                   p,
+				})
+			})
 		},
 	}
 
-	defaultJavaRootPackage = "io.fabric8.kubernetes.types"
-	javaRootPackage        *string
+	cmd.Flags().StringVarP(&javaRootPackage, "java-root-package", "j", defaultJavaRootPackage, "root java package to generate Kubernetes classes in")
+	cmd.Flags().StringVar(&javaRootOpenShiftPackage, "java-root-openshift-package", defaultJavaRootOpenShiftPackage, "root java package to generate OpenShift classes in")
+	cmd.Flags().StringVarP(&stylesClass, "styles-class", "s", defaultStylesClass, "default immutables styles class")
+	cmd.Flags().StringVar(&parentPOM, "parent-pom", "", "parent POM of the generated modules (default pom.xml in the output directory)")
+	cmd.Flags().StringVar(&parent, "parent", "", "coordinates of the parent POM as groupId:artifactId:version, instead of reading it")
 
-	defaultJavaRootOpenShiftPackage = "io.fabric8.openshift.types"
-	javaRootOpenShiftPackage        *string
+	return cmd
+}
 
-	defaultStylesClass = strings.Join([]string{defaultJavaRootPackage, "common", "ImmutablesStyle"}, ".")
-	stylesClass        *string
-)
+// readParent returns the coordinates given with --parent, or else those of the
+// parent POM file, which defaults to pom.xml in the output directory.
+func readParent(outputDirectory, pomFile, coordinates string) (immutables.POM, error) {
+	if coordinates != "" {
+		return immutables.ParsePOMCoordinates(coordinates)
+	}
+	if pomFile == "" {
+		pomFile = filepath.Join(outputDirectory, "pom.xml")
+	}
+	return immutables.ReadPOM(pomFile)
+}
 
 func init() {
-	javaRootPackage = immutablesCmd.Flags().StringP("java-root-package", "j", defaultJavaRootPackage, "root java package to generate Kubernetes classes in")
-	javaRootOpenShiftPackage = immutablesCmd.Flags().String("java-root-openshift-package", defaultJavaRootOpenShiftPackage, "root java package to generate OpenShift classes in")
-	stylesClass = immutablesCmd.Flags().StringP("styles-class", "s", defaultStylesClass, "default immutables styles class")
-
-	RootCmd.AddCommand(immutablesCmd)
+	addGeneratorCommand(newImmutablesCommand)
 }
@@ -0,0 +1,40 @@
+package generate
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/generator/jsonschema"
+)
+
+const (
+	defaultSchemaFile  = "kube-schema.json"
+	defaultSchemaTitle = "Kubernetes"
+)
+
+func newJSONSchemaCommand(run generatorRunner) *cobra.Command {
+	var schemaFile, schemaTitle string
+
+	cmd := &cobra.Command{
+		Use:   "jsonschema",
+		Short: "JSON Schema",
+		Run: func(cmd *cobra.Command, args []string) {
+			run("jsonschema", func(c generator.Config) generator.Generator {
+				return jsonschema.New(jsonschema.Config{
+					Config:     c,
+					SchemaFile: schemaFile,
+					Title:      schemaTitle,
+				})
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&schemaFile, "schema-file", defaultSchemaFile, "name of the JSON schema file to generate in the output directory")
+	cmd.Flags().StringVar(&schemaTitle, "title", defaultSchemaTitle, "title of the generated JSON schema")
+
+	return cmd
+}
+
+func init() {
+	addGeneratorCommand(newJSONSchemaCommand)
+}
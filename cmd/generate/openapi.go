@@ -0,0 +1,48 @@
+package generate
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/generator/openapi"
+)
+
+const (
+	defaultOpenAPIFile    = "openapi.json"
+	defaultOpenAPITitle   = "Kubernetes"
+	defaultOpenAPIVersion = "unversioned"
+)
+
+func newOpenAPICommand(run generatorRunner) *cobra.Command {
+	var (
+		openAPIFile, openAPITitle, openAPIVersion string
+		openAPIPaths                              bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "OpenAPI v3",
+		Run: func(cmd *cobra.Command, args []string) {
+			run("openapi", func(c generator.Config) generator.Generator {
+				return openapi.New(openapi.Config{
+					Config:      c,
+					OpenAPIFile: openAPIFile,
+					Title:       openAPITitle,
+					Version:     openAPIVersion,
+					Paths:       openAPIPaths,
+				})
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&openAPIFile, "openapi-file", defaultOpenAPIFile, "name of the OpenAPI document to generate in the output directory")
+	cmd.Flags().StringVar(&openAPITitle, "title", defaultOpenAPITitle, "title of the generated OpenAPI document")
+	cmd.Flags().StringVar(&openAPIVersion, "api-version", defaultOpenAPIVersion, "version of the generated OpenAPI document")
+	cmd.Flags().BoolVar(&openAPIPaths, "paths", false, "generate REST paths for types with generated clients")
+
+	return cmd
+}
+
+func init() {
+	addGeneratorCommand(newOpenAPICommand)
+}
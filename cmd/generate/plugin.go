@@ -0,0 +1,46 @@
+package generate
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/plugin"
+)
+
+// RegisterPlugins adds a command for each kube-client-gen-<name> plugin on the
+// PATH. Built in generators take precedence over plugins of the same name.
+func RegisterPlugins() {
+	plugins := plugin.Discover()
+	for _, name := range plugin.Names(plugins) {
+		if cmd, _, err := RootCmd.Find([]string{name}); err == nil && cmd != RootCmd {
+			continue
+		}
+		name, executable := name, plugins[name]
+		addGeneratorCommand(func(run generatorRunner) *cobra.Command {
+			return pluginCommand(name, executable, run)
+		})
+	}
+}
+
+func pluginCommand(name, executable string, run generatorRunner) *cobra.Command {
+	var params map[string]string
+
+	cmd := &cobra.Command{
+		Use:   name,
+		Short: "Plugin " + executable,
+		Run: func(cmd *cobra.Command, args []string) {
+			run(name, func(c generator.Config) generator.Generator {
+				return plugin.New(plugin.Config{
+					Config:     c,
+					Name:       name,
+					Executable: executable,
+					Parameters: params,
+				})
+			})
+		},
+	}
+
+	cmd.Flags().StringToStringVarP(&params, "param", "P", nil, "plugin parameter as key=value, may be repeated")
+
+	return cmd
+}
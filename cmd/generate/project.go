@@ -0,0 +1,170 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	"github.com/jimmidyson/kube-client-gen/pkg/project"
+)
+
+// generatorRunner runs the generator that a generator command creates from
+// its options.
+type generatorRunner func(name string, newGenerator func(generator.Config) generator.Generator)
+
+// generatorCommands creates a new command for each generator, by name, so
+// that the generate command can run a generator once per target with the
+// target's options, configuration and packages.
+var generatorCommands = map[string]func(generatorRunner) *cobra.Command{}
+
+func addGeneratorCommand(newCommand func(generatorRunner) *cobra.Command) {
+	cmd := newCommand(runLoaded)
+	generatorCommands[cmd.Name()] = newCommand
+	RootCmd.AddCommand(cmd)
+}
+
+// readProject reads the project file given with --config, or the default
+// project file if it exists. It returns nil if there is no project file.
+func readProject(logger log15.Logger) (*project.Config, error) {
+	fp := *projectFile
+	if fp == "" {
+		if _, err := os.Stat(project.DefaultFile); err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, errors.Wrapf(err, "failed to check if project file %s exists", project.DefaultFile)
+		}
+		fp = project.DefaultFile
+	}
+	logger.Debug("reading project file", "file", fp)
+	return project.ReadFile(fp)
+}
+
+// applyProject sets the values of flags that were not given on the command
+// line from the project file.
+func applyProject(flags *pflag.FlagSet, p *project.Config) {
+	if !flags.Changed("package") && len(p.Packages) > 0 {
+		*packages = p.PackagesOf(nil)
+	}
+	if !flags.Changed("dir") && p.Dir != "" {
+		*moduleDir = p.Dir
+	}
+	if !flags.Changed("mod") && p.Mod != "" {
+		*modFlag = p.Mod
+	}
+	if !flags.Changed("from-ir") && p.FromIR != "" {
+		*fromIR = p.FromIR
+	}
+	if !flags.Changed("closure") && p.Closure {
+		*typeClosure = true
+	}
+	if !flags.Changed("include") && p.Include != nil {
+		*includes = p.Include
+	}
+	if !flags.Changed("exclude") && p.Exclude != nil {
+		*excludes = p.Exclude
+	}
+	if !flags.Changed("root") && p.Roots != nil {
+		*roots = p.Roots
+	}
+	if !flags.Changed("header-file") && p.HeaderFile != "" {
+		*headerFile = p.HeaderFile
+	}
+	if !flags.Changed("output-directory") && p.OutputDirectory != "" {
+		*outputDirectory = p.OutputDirectory
+	}
+	if !flags.Changed("force") && p.Force {
+		*force = true
+	}
+}
+
+func newGenerateCommand() *cobra.Command {
+	var targets []string
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate every target of the project file",
+		Run: func(cmd *cobra.Command, args []string) {
+			if proj == nil {
+				config.Logger.Crit("no project file, create " + project.DefaultFile + " or use --config")
+				os.Exit(1)
+			}
+
+			selected := map[string]bool{}
+			for _, name := range targets {
+				selected[name] = true
+			}
+			for _, t := range proj.Targets {
+				delete(selected, t.Name)
+			}
+			for name := range selected {
+				config.Logger.Crit("unknown target", "target", name)
+				os.Exit(1)
+			}
+
+			// Restrict targets to their package sets only when the packages
+			// come from the project file.
+			usePackageSets := !cmd.Flags().Changed("package") && *fromIR == ""
+
+			for _, t := range proj.Targets {
+				if len(targets) > 0 && !contains(targets, t.Name) {
+					continue
+				}
+
+				targetConfig := config
+				targetConfig.Logger = config.Logger.New("target", t.Name)
+				targetConfig.OutputDirectory = t.OutputDirectory
+				if !filepath.IsAbs(t.OutputDirectory) {
+					targetConfig.OutputDirectory = filepath.Join(config.OutputDirectory, t.OutputDirectory)
+				}
+				targetPackages := parsedPackages
+				if usePackageSets && len(t.Packages) > 0 {
+					targetPackages = loader.Select(parsedPackages, proj.PackagesOf(t.Packages))
+				}
+
+				newCommand, ok := generatorCommands[t.Generator]
+				if !ok {
+					config.Logger.Crit("unknown generator", "target", t.Name, "generator", t.Generator)
+					os.Exit(1)
+				}
+				targetCmd := newCommand(func(name string, newGenerator func(generator.Config) generator.Generator) {
+					runGenerator(targetConfig, targetPackages, name, newGenerator)
+				})
+				targetArgs, err := t.Args()
+				if err == nil {
+					err = targetCmd.ParseFlags(targetArgs)
+				}
+				if err != nil {
+					config.Logger.Crit("invalid target options", "target", t.Name, "error", err)
+					os.Exit(1)
+				}
+
+				targetConfig.Logger.Info("generating target", "generator", t.Generator, "outputDirectory", targetConfig.OutputDirectory)
+				targetCmd.Run(targetCmd, nil)
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&targets, "target", "t", nil, "only generate these targets")
+
+	return cmd
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	RootCmd.AddCommand(newGenerateCommand())
+}
@@ -0,0 +1,33 @@
+package generate
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/generator/proto"
+)
+
+func newProtoCommand(run generatorRunner) *cobra.Command {
+	var protoFile string
+
+	cmd := &cobra.Command{
+		Use:   "proto",
+		Short: "Protobuf definitions",
+		Run: func(cmd *cobra.Command, args []string) {
+			run("proto", func(c generator.Config) generator.Generator {
+				return proto.New(proto.Config{
+					Config:    c,
+					ProtoFile: protoFile,
+				})
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&protoFile, "proto-file", "generated.proto", "name of the proto file to write in each package directory")
+
+	return cmd
+}
+
+func init() {
+	addGeneratorCommand(newProtoCommand)
+}
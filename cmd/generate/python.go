@@ -0,0 +1,35 @@
+package generate
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/generator/python"
+)
+
+func newPythonCommand(run generatorRunner) *cobra.Command {
+	var rootPackage, style string
+
+	cmd := &cobra.Command{
+		Use:   "python",
+		Short: "Python models",
+		Run: func(cmd *cobra.Command, args []string) {
+			run("python", func(c generator.Config) generator.Generator {
+				return python.New(python.Config{
+					Config:      c,
+					RootPackage: rootPackage,
+					Style:       style,
+				})
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&rootPackage, "python-root-package", "r", "kubernetes_models", "root python package to generate models in")
+	cmd.Flags().StringVar(&style, "style", python.StylePydantic, "model style, one of pydantic or dataclass")
+
+	return cmd
+}
+
+func init() {
+	addGeneratorCommand(newPythonCommand)
+}
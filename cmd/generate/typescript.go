@@ -0,0 +1,33 @@
+package generate
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/generator/typescript"
+)
+
+func newTypeScriptCommand(run generatorRunner) *cobra.Command {
+	var declarations bool
+
+	cmd := &cobra.Command{
+		Use:   "typescript",
+		Short: "TypeScript interfaces",
+		Run: func(cmd *cobra.Command, args []string) {
+			run("typescript", func(c generator.Config) generator.Generator {
+				return typescript.New(typescript.Config{
+					Config:       c,
+					Declarations: declarations,
+				})
+			})
+		},
+	}
+
+	cmd.Flags().BoolVarP(&declarations, "declarations", "d", false, "generate .d.ts declaration files instead of .ts modules")
+
+	return cmd
+}
+
+func init() {
+	addGeneratorCommand(newTypeScriptCommand)
+}
@@ -3,6 +3,7 @@ package main
 import "github.com/jimmidyson/kube-client-gen/cmd/generate"
 
 func main() {
+	generate.RegisterPlugins()
 	if err := generate.RootCmd.Execute(); err != nil {
 		panic(err)
 	}
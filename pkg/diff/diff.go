@@ -0,0 +1,223 @@
+// Package diff renders line based unified diffs.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around each change.
+const context = 3
+
+// maxTable bounds the size of the table used to find the longest common
+// subsequence of lines. Larger inputs are diffed as a removal of all old
+// lines followed by an insertion of all new lines.
+const maxTable = 1 << 22
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	// old and new are the indices of the line in the old and new text. Only
+	// the one for the side the line is on is meaningful for deletes and
+	// inserts.
+	old, new int
+}
+
+// Unified returns the unified diff between old and new, labelled with oldName
+// and newName, or an empty string if they are the same.
+func Unified(oldName, newName, old, new string) string {
+	if old == new {
+		return ""
+	}
+
+	a, b := splitLines(old), splitLines(new)
+	ops := lineOps(a, b)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	for start := 0; start < len(ops); {
+		for start < len(ops) && ops[start].kind == opEqual {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk until there is a run of unchanged lines long enough
+		// to separate it from the next change.
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == opEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				break
+			}
+			end = run
+		}
+
+		from := max(start-context, 0)
+		to := min(end+context, len(ops))
+		writeHunk(&out, a, b, ops[from:to])
+		start = to
+	}
+
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, a, b []string, ops []op) {
+	oldStart, newStart := -1, -1
+	oldCount, newCount := 0, 0
+	for _, o := range ops {
+		if o.kind != opInsert {
+			if oldStart < 0 {
+				oldStart = o.old
+			}
+			oldCount++
+		}
+		if o.kind != opDelete {
+			if newStart < 0 {
+				newStart = o.new
+			}
+			newCount++
+		}
+	}
+	if oldStart < 0 {
+		oldStart = ops[0].old
+	}
+	if newStart < 0 {
+		newStart = ops[0].new
+	}
+
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, o := range ops {
+		switch o.kind {
+		case opEqual:
+			writeLine(out, ' ', b[o.new])
+		case opDelete:
+			writeLine(out, '-', a[o.old])
+		case opInsert:
+			writeLine(out, '+', b[o.new])
+		}
+	}
+}
+
+// hunkRange formats the zero based start and count of a hunk. An empty range
+// refers to the line before it.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+func writeLine(out *strings.Builder, prefix byte, line string) {
+	out.WriteByte(prefix)
+	out.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		out.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// splitLines splits s into lines, keeping the line endings.
+func splitLines(s string) []string {
+	var lines []string
+	for s != "" {
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			lines = append(lines, s)
+			break
+		}
+		lines = append(lines, s[:i+1])
+		s = s[i+1:]
+	}
+	return lines
+}
+
+// lineOps returns the edits turning a into b, with deletions before
+// insertions within each change.
+func lineOps(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, op{opEqual, i, i})
+	}
+	ops = append(ops, middleOps(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix)...)
+	for i := 0; i < suffix; i++ {
+		ops = append(ops, op{opEqual, len(a) - suffix + i, len(b) - suffix + i})
+	}
+	return ops
+}
+
+func middleOps(a, b []string, offset int) []op {
+	n, m := len(a), len(b)
+	var ops []op
+
+	if n*m > maxTable {
+		for i := range a {
+			ops = append(ops, op{opDelete, offset + i, offset})
+		}
+		for j := range b {
+			ops = append(ops, op{opInsert, offset + n, offset + j})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			ops = append(ops, op{opEqual, offset + i, offset + j})
+			i++
+			j++
+		case j == m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{opDelete, offset + i, offset + j})
+			i++
+		default:
+			ops = append(ops, op{opInsert, offset + i, offset + j})
+			j++
+		}
+	}
+	return ops
+}
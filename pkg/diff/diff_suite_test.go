@@ -1,4 +1,4 @@
-package B_test
+package diff_test
 
 import (
 	. "github.com/onsi/ginkgo"
@@ -7,7 +7,7 @@ import (
 	"testing"
 )
 
-func TestB(t *testing.T) {
+func TestDiff(t *testing.T) {
 	RegisterFailHandler(Fail)
-	RunSpecs(t, "B Suite")
+	RunSpecs(t, "Diff Suite")
 }
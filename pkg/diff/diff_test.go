@@ -0,0 +1,76 @@
+package diff_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/jimmidyson/kube-client-gen/pkg/diff"
+)
+
+func lines(from, to int) string {
+	var b strings.Builder
+	for i := from; i <= to; i++ {
+		b.WriteString(strings.Repeat("x", i) + "\n")
+	}
+	return b.String()
+}
+
+var _ = Describe("Diff", func() {
+	It("is empty for equal texts", func() {
+		Expect(Unified("a/f", "b/f", "a\nb\n", "a\nb\n")).To(BeEmpty())
+	})
+
+	It("shows changes with context", func() {
+		old := "1\n2\n3\n4\n5\n6\n7\n8\n"
+		new := "1\n2\n3\n4\nfive\n6\n7\n8\n"
+		Expect(Unified("a/f", "b/f", old, new)).To(Equal(`--- a/f
++++ b/f
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`))
+	})
+
+	It("splits distant changes into hunks", func() {
+		old := lines(1, 20)
+		new := strings.Replace(strings.Replace(old, "x\n", "y\n", 1), strings.Repeat("x", 20)+"\n", "", 1)
+		Expect(Unified("a/f", "b/f", old, new)).To(Equal(`--- a/f
++++ b/f
+@@ -1,4 +1,4 @@
+-x
++y
+ xx
+ xxx
+ xxxx
+@@ -17,4 +17,3 @@
+ xxxxxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxxxxxx
+-xxxxxxxxxxxxxxxxxxxx
+`))
+	})
+
+	It("shows added and removed files", func() {
+		Expect(Unified("/dev/null", "b/f", "", "a\nb\n")).To(Equal("--- /dev/null\n+++ b/f\n@@ -0,0 +1,2 @@\n+a\n+b\n"))
+		Expect(Unified("a/f", "/dev/null", "a\n", "")).To(Equal("--- a/f\n+++ /dev/null\n@@ -1 +0,0 @@\n-a\n"))
+	})
+
+	It("marks missing newlines at the end of file", func() {
+		Expect(Unified("a/f", "b/f", "a\nb", "a\nb\n")).To(Equal(`--- a/f
++++ b/f
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`))
+	})
+})
@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// archiveTime is the modification time of every archive entry, so archives
+// of the same files are byte for byte identical.
+var archiveTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ArchiveOutput is an Output that writes files to an archive. Close must be
+// called once all files are written to complete the archive. It does not
+// close the underlying writer.
+type ArchiveOutput interface {
+	Output
+	io.Closer
+}
+
+// NewZipOutput returns an ArchiveOutput writing a zip archive, such as a jar,
+// to w.
+func NewZipOutput(w io.Writer) ArchiveOutput {
+	return &zipOutput{
+		w:       zip.NewWriter(w),
+		written: map[string]struct{}{},
+	}
+}
+
+type zipOutput struct {
+	w       *zip.Writer
+	written map[string]struct{}
+}
+
+func (o *zipOutput) WriteFile(name string, data []byte) error {
+	name, err := archiveName(o.written, name)
+	if err != nil {
+		return err
+	}
+
+	f, err := o.w.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: archiveTime,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to add file %s to zip archive", name)
+	}
+	_, err = f.Write(data)
+	return errors.Wrapf(err, "failed to write file %s to zip archive", name)
+}
+
+func (o *zipOutput) Close() error {
+	return errors.Wrap(o.w.Close(), "failed to complete zip archive")
+}
+
+// NewTarOutput returns an ArchiveOutput writing a tar archive to w, gzip
+// compressed if compress is set.
+func NewTarOutput(w io.Writer, compress bool) ArchiveOutput {
+	o := &tarOutput{
+		written: map[string]struct{}{},
+	}
+	if compress {
+		o.gz = gzip.NewWriter(w)
+		w = o.gz
+	}
+	o.w = tar.NewWriter(w)
+	return o
+}
+
+type tarOutput struct {
+	w       *tar.Writer
+	gz      *gzip.Writer
+	written map[string]struct{}
+}
+
+func (o *tarOutput) WriteFile(name string, data []byte) error {
+	name, err := archiveName(o.written, name)
+	if err != nil {
+		return err
+	}
+
+	err = o.w.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  archiveTime,
+		Format:   tar.FormatPAX,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to add file %s to tar archive", name)
+	}
+	_, err = o.w.Write(data)
+	return errors.Wrapf(err, "failed to write file %s to tar archive", name)
+}
+
+func (o *tarOutput) Close() error {
+	if err := o.w.Close(); err != nil {
+		return errors.Wrap(err, "failed to complete tar archive")
+	}
+	if o.gz != nil {
+		return errors.Wrap(o.gz.Close(), "failed to complete gzip stream")
+	}
+	return nil
+}
+
+// archiveName cleans name, rejecting names already in the archive as entries
+// cannot be overwritten.
+func archiveName(written map[string]struct{}, name string) (string, error) {
+	name, err := CleanName(name)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := written[name]; ok {
+		return "", errors.Errorf("file %s is already in the archive", name)
+	}
+	written[name] = struct{}{}
+	return name, nil
+}
@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// Diagnostic is a problem with a loaded type that keeps a generator from
+// generating it, such as a field of an unsupported type.
+type Diagnostic struct {
+	// Position is where the problem is in the Go source, if known.
+	Position token.Position
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	if d.Position.Filename == "" {
+		return d.Message
+	}
+	return fmt.Sprintf("%s:%d: %s", d.Position.Filename, d.Position.Line, d.Message)
+}
+
+// Diagnostics collects the problems found while generating, so they can be
+// reported together. Generators skip what they cannot generate, generate
+// everything else, and return the collected problems from Err.
+type Diagnostics struct {
+	list []Diagnostic
+}
+
+// Addf records a problem at pos.
+func (d *Diagnostics) Addf(pos token.Position, format string, args ...interface{}) {
+	d.list = append(d.list, Diagnostic{Position: pos, Message: fmt.Sprintf(format, args...)})
+}
+
+// Len returns the number of problems recorded.
+func (d *Diagnostics) Len() int {
+	return len(d.list)
+}
+
+// Err returns the recorded problems, ordered by position, as a
+// DiagnosticsError, or nil if there are none.
+func (d *Diagnostics) Err() error {
+	if len(d.list) == 0 {
+		return nil
+	}
+	list := append(DiagnosticsError(nil), d.list...)
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i].Position, list[j].Position
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Line < b.Line
+	})
+	return list
+}
+
+// DiagnosticsError is returned by generators that generated all they could
+// but found problems with some types.
+type DiagnosticsError []Diagnostic
+
+func (e DiagnosticsError) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, d := range e {
+		lines = append(lines, d.String())
+	}
+	return fmt.Sprintf("%d problem(s) found:\n%s", len(e), strings.Join(lines, "\n"))
+}
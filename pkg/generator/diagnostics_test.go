@@ -0,0 +1,35 @@
+package generator_test
+
+import (
+	"go/token"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/jimmidyson/kube-client-gen/pkg/generator"
+)
+
+var _ = Describe("Diagnostics", func() {
+	It("has no error without problems", func() {
+		var diags Diagnostics
+		Expect(diags.Len()).To(Equal(0))
+		Expect(diags.Err()).NotTo(HaveOccurred())
+	})
+
+	It("reports every problem ordered by position", func() {
+		var diags Diagnostics
+		diags.Addf(token.Position{Filename: "b.go", Line: 3}, "field %s.%s: unsupported type", "B", "F")
+		diags.Addf(token.Position{Filename: "a.go", Line: 12}, "second")
+		diags.Addf(token.Position{Filename: "a.go", Line: 4}, "first")
+		diags.Addf(token.Position{}, "enum E: unsupported type")
+		Expect(diags.Len()).To(Equal(4))
+
+		err := diags.Err()
+		Expect(err).To(BeAssignableToTypeOf(DiagnosticsError{}))
+		Expect(err.Error()).To(Equal(`4 problem(s) found:
+enum E: unsupported type
+a.go:4: first
+a.go:12: second
+b.go:3: field B.F: unsupported type`))
+	})
+})
@@ -0,0 +1,43 @@
+package dump
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/ir"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+func New(c Config) generator.Generator {
+	c.Logger.Debug("creating generator", "type", "dump")
+	return &dumpGenerator{
+		config: c,
+	}
+}
+
+type Config struct {
+	generator.Config
+
+	// IRFile is the file to write the IR to, relative to the output
+	// directory.
+	IRFile string
+	// Format is the IR format, json or yaml.
+	Format string
+}
+
+type dumpGenerator struct {
+	config Config
+}
+
+var _ generator.Generator = &dumpGenerator{}
+
+func (g *dumpGenerator) Generate(pkgs []loader.Package) error {
+	g.config.Logger.Debug("generating")
+
+	data, err := ir.FromLoader(pkgs).Marshal(g.config.Format)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode IR")
+	}
+
+	return g.config.Output.WriteFile(g.config.IRFile, data)
+}
@@ -1,4 +1,4 @@
-package A_test
+package dump_test
 
 import (
 	. "github.com/onsi/ginkgo"
@@ -7,7 +7,7 @@ import (
 	"testing"
 )
 
-func TestA(t *testing.T) {
+func TestDump(t *testing.T) {
 	RegisterFailHandler(Fail)
-	RunSpecs(t, "A Suite")
+	RunSpecs(t, "Dump Suite")
 }
@@ -0,0 +1,74 @@
+package dump_test
+
+import (
+	"bytes"
+
+	"github.com/inconshreveable/log15"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	. "github.com/jimmidyson/kube-client-gen/pkg/generator/dump"
+	"github.com/jimmidyson/kube-client-gen/pkg/ir"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+var _ = Describe("Dump", func() {
+	var (
+		logger log15.Logger
+		out    *generator.MemoryOutput
+		pkgs   []loader.Package
+	)
+
+	BeforeEach(func() {
+		logger = log15.New()
+		logger.SetHandler(log15.DiscardHandler())
+
+		out = generator.NewMemoryOutput()
+
+		var err error
+		pkgs, err = loader.New([]string{
+			"k8s.io/kubernetes/pkg/api/unversioned",
+			"k8s.io/kubernetes/pkg/api/v1",
+		}, logger, loader.WithDir("../testdata/module")).Load()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	generate := func(file, format string) error {
+		return New(Config{Config: generator.Config{Logger: logger, Output: out}, IRFile: file, Format: format}).Generate(pkgs)
+	}
+
+	It("writes the IR of the loaded packages", func() {
+		Expect(generate("ir/ir.json", "json")).To(Succeed())
+
+		Expect(out.Files).To(HaveLen(1))
+		doc, err := ir.Unmarshal(out.Files["ir/ir.json"])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc).To(Equal(ir.FromLoader(pkgs)))
+		Expect(doc.Packages).To(HaveLen(2))
+		Expect(doc.Packages[1].Path).To(Equal("k8s.io/kubernetes/pkg/api/v1"))
+		Expect(doc.Packages[1].Enums[0].Name).To(Equal("PodPhase"))
+	})
+
+	It("writes YAML", func() {
+		Expect(generate("ir.yaml", "yaml")).To(Succeed())
+
+		Expect(string(out.Files["ir.yaml"])).To(HavePrefix("version: 1\npackages:\n"))
+
+		doc, err := ir.Unmarshal(out.Files["ir.yaml"])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc).To(Equal(ir.FromLoader(pkgs)))
+	})
+
+	It("streams the IR through a writer output", func() {
+		var buf bytes.Buffer
+		Expect(New(Config{Config: generator.Config{Logger: logger, Output: generator.NewWriterOutput(&buf)}, IRFile: "ir.yaml", Format: "yaml"}).Generate(pkgs)).To(Succeed())
+		Expect(buf.String()).To(HavePrefix("version: 1\npackages:\n"))
+	})
+
+	It("rejects unknown formats", func() {
+		Expect(generate("ir.xml", "xml")).To(HaveOccurred())
+		Expect(out.Files).To(BeEmpty())
+	})
+})
@@ -4,12 +4,23 @@ import (
 	"github.com/inconshreveable/log15"
 
 	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
 )
 
 type Config struct {
 	Logger          log15.Logger
 	Force           bool
 	OutputDirectory string
+	// Output receives the generated files, named relative to
+	// OutputDirectory.
+	Output Output
+	// Header is the license header to add to generated files, in the
+	// comment syntax of each format that has one. Files get no header if it
+	// is empty.
+	Header string
+	// TypeMappings holds type mappings for each target, which generators
+	// merge over their default mappings.
+	TypeMappings map[string]typemap.Table
 }
 
 type Generator interface {
@@ -1,4 +1,4 @@
-package testsuite_test
+package generator_test
 
 import (
 	. "github.com/onsi/ginkgo"
@@ -7,7 +7,7 @@ import (
 	"testing"
 )
 
-func TestTestsuite(t *testing.T) {
+func TestGenerator(t *testing.T) {
 	RegisterFailHandler(Fail)
-	RunSpecs(t, "Testsuite Suite")
+	RunSpecs(t, "Generator Suite")
 }
@@ -0,0 +1,484 @@
+package goclient
+
+import (
+	"bytes"
+	"go/format"
+	"path"
+	"sort"
+	"strconv"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+// generatedComment marks the generated files as generated for Go tools,
+// which expect it in this exact form.
+const generatedComment = "// Code generated by kube-client-gen. DO NOT EDIT.\n"
+
+// clientVerbs are the verbs of generated clients, as named in +genclient
+// markers.
+var clientVerbs = []string{"get", "list", "create", "update", "delete", "watch"}
+
+const groupTemplateText = generatedComment + `
+// Package {{.Name}} is the typed client of the API group version {{.APIVersion}}.
+package {{.Name}}
+
+import (
+	"context"
+
+	"{{.Package}}/rest"
+{{range .Imports}}
+	{{.Alias}} "{{.Path}}"{{end}}
+)
+
+// Interface is the client of the API group version {{.APIVersion}}.
+type Interface interface {{"{"}}{{range .Kinds}}
+{{if .Namespaced}}	// {{.Plural}} returns the client of {{.Resource}} in namespace, or in all
+	// namespaces if it is empty.
+	{{.Plural}}(namespace string) {{.Name}}Interface{{else}}	// {{.Plural}} returns the client of {{.Resource}}.
+	{{.Plural}}() {{.Name}}Interface{{end}}{{end}}
+}
+{{range .Kinds}}
+// {{.Name}}Interface reads and writes objects of kind {{.Name}}.
+type {{.Name}}Interface interface {{"{"}}{{if .Verbs.get}}
+	// Get returns the object called name.
+	Get(ctx context.Context, name string) (*{{.Type}}, error){{end}}{{if .Verbs.list}}
+	// List returns the objects that match opts.
+	List(ctx context.Context, opts rest.ListOptions) (*{{.List}}, error){{end}}{{if .Verbs.create}}
+	// Create creates obj and returns it as created.
+	Create(ctx context.Context, obj *{{.Type}}) (*{{.Type}}, error){{end}}{{if .Verbs.update}}
+	// Update replaces the object with the name of obj and returns it as
+	// updated.
+	Update(ctx context.Context, obj *{{.Type}}) (*{{.Type}}, error){{end}}{{if .Verbs.delete}}
+	// Delete deletes the object called name.
+	Delete(ctx context.Context, name string) error{{end}}{{if .Verbs.watch}}
+	// Watch watches the objects that match opts.
+	Watch(ctx context.Context, opts rest.ListOptions) (rest.Watcher[{{.Type}}], error){{end}}
+}
+{{end}}
+type client struct {
+	client *rest.Client
+}
+
+// New returns the client of {{.APIVersion}} that sends its requests with c.
+func New(c *rest.Client) Interface {
+	return &client{client: c}
+}
+{{$apiPath := .APIPath}}{{range .Kinds}}
+func (c *client) {{.Plural}}({{if .Namespaced}}namespace string{{end}}) {{.Name}}Interface {
+	return rest.NewResource[{{.Type}}, {{.List}}](c.client, "{{$apiPath}}", "{{.Resource}}", {{.Namespaced}}, {{if .Namespaced}}namespace{{else}}""{{end}})
+}
+{{end}}`
+
+const fakeGroupTemplateText = generatedComment + `
+// Package fake is the fake typed client of the API group version
+// {{.APIVersion}}, for tests.
+package fake
+
+import (
+	"{{.Package}}/tracker"
+	"{{.Package}}/typed/{{.Name}}"
+{{range .Imports}}
+	{{.Alias}} "{{.Path}}"{{end}}
+)
+
+// Client is the fake client of the API group version {{.APIVersion}}, which
+// reads and writes objects in a tracker.
+type Client struct {
+	Tracker *tracker.Tracker
+}
+
+var _ {{.Name}}.Interface = &Client{}
+
+// New returns the fake client of {{.APIVersion}} backed by t.
+func New(t *tracker.Tracker) *Client {
+	return &Client{Tracker: t}
+}
+{{$apiPath := .APIPath}}{{$name := .Name}}{{range .Kinds}}
+func (c *Client) {{.Plural}}({{if .Namespaced}}namespace string{{end}}) {{$name}}.{{.Name}}Interface {
+	return tracker.NewResource[{{.Type}}, {{.List}}](c.Tracker, "{{$apiPath}}", "{{.Resource}}", {{.Namespaced}}, {{if .Namespaced}}namespace{{else}}""{{end}})
+}
+{{end}}
+// Add adds obj to t if it is an object of a kind of {{.APIVersion}}, and
+// reports whether it is.
+func Add(t *tracker.Tracker, obj interface{}) (bool, error) {
+	switch obj.(type) {{"{"}}{{range .Kinds}}
+	case *{{.Type}}:
+		return true, t.Add("{{$apiPath}}", "{{.Resource}}", obj){{end}}
+	}
+	return false, nil
+}
+`
+
+const clientsetTemplateText = generatedComment + `
+// Package {{.Name}} is the clientset of the typed clients of all API group
+// versions.
+package {{.Name}}
+
+import (
+	"{{.Package}}/rest"{{range .Groups}}
+	"{{$.Package}}/typed/{{.Name}}"{{end}}
+)
+
+// Interface is the typed clients of all API group versions.
+type Interface interface {{"{"}}{{range .Groups}}
+	{{.GoName}}() {{.Name}}.Interface{{end}}
+}
+
+// Clientset is the typed clients of all API group versions, sending their
+// requests with a shared rest.Client.
+type Clientset struct {{"{"}}{{range .Groups}}
+	{{.Field}} {{.Name}}.Interface{{end}}
+}
+
+var _ Interface = &Clientset{}
+
+// NewForConfig returns the clientset for the API server of c.
+func NewForConfig(c *rest.Config) (*Clientset, error) {
+	client, err := rest.NewForConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	return New(client), nil
+}
+
+// New returns the clientset that sends its requests with c.
+func New(c *rest.Client) *Clientset {
+	return &Clientset{{"{"}}{{range .Groups}}
+		{{.Field}}: {{.Name}}.New(c),{{end}}
+	}
+}
+{{range .Groups}}
+// {{.GoName}} returns the client of the API group version {{.APIVersion}}.
+func (c *Clientset) {{.GoName}}() {{.Name}}.Interface {
+	return c.{{.Field}}
+}
+{{end}}`
+
+const fakeClientsetTemplateText = generatedComment + `
+// Package fake is the fake clientset, for tests.
+package fake
+
+import (
+	"fmt"
+
+	clientset "{{.Package}}"
+	"{{.Package}}/tracker"{{range .Groups}}
+	"{{$.Package}}/typed/{{.Name}}"
+	fake{{.Name}} "{{$.Package}}/typed/{{.Name}}/fake"{{end}}
+)
+
+// Clientset is the fake clientset, whose clients read and write objects in
+// an in-memory tracker.
+type Clientset struct {
+	tracker *tracker.Tracker
+}
+
+var _ clientset.Interface = &Clientset{}
+
+// NewSimpleClientset returns a fake clientset with a tracker holding
+// objects, which must be pointers to objects of client-enabled kinds. It
+// panics if they are not.
+func NewSimpleClientset(objects ...interface{}) *Clientset {
+	t := tracker.New()
+	for _, obj := range objects {
+		if err := add(t, obj); err != nil {
+			panic(err)
+		}
+	}
+	return &Clientset{tracker: t}
+}
+
+// Tracker returns the tracker of the clientset, to add objects to or to
+// inspect.
+func (c *Clientset) Tracker() *tracker.Tracker {
+	return c.tracker
+}
+{{range .Groups}}
+// {{.GoName}} returns the fake client of the API group version {{.APIVersion}}.
+func (c *Clientset) {{.GoName}}() {{.Name}}.Interface {
+	return fake{{.Name}}.New(c.tracker)
+}
+{{end}}
+func add(t *tracker.Tracker, obj interface{}) error {
+	for _, add := range []func(*tracker.Tracker, interface{}) (bool, error){{"{"}}{{range .Groups}}
+		fake{{.Name}}.Add,{{end}}
+	} {
+		if ok, err := add(t, obj); ok {
+			return err
+		}
+	}
+	return fmt.Errorf("no client for objects of type %T", obj)
+}
+`
+
+var (
+	groupTemplate         = template.Must(template.New("group").Parse(groupTemplateText))
+	fakeGroupTemplate     = template.Must(template.New("fakeGroup").Parse(fakeGroupTemplateText))
+	clientsetTemplate     = template.Must(template.New("clientset").Parse(clientsetTemplateText))
+	fakeClientsetTemplate = template.Must(template.New("fakeClientset").Parse(fakeClientsetTemplateText))
+)
+
+func New(c Config) generator.Generator {
+	c.Logger.Debug("creating generator", "type", "goclient")
+	return &goClientGenerator{
+		config: c,
+	}
+}
+
+type Config struct {
+	generator.Config
+
+	// Package is the Go import path of the output directory, which the
+	// generated packages import each other by.
+	Package string
+}
+
+type goClientGenerator struct {
+	config Config
+	diags  generator.Diagnostics
+}
+
+var _ generator.Generator = &goClientGenerator{}
+
+// group is the typed client package of an API group version.
+type group struct {
+	Package    string
+	Group      string
+	Version    string
+	APIVersion string
+	APIPath    string
+	// Name is the name of the package, such as appsv1, and GoName the name
+	// of its method in the clientset, such as AppsV1.
+	Name    string
+	GoName  string
+	Field   string
+	Imports []goImport
+	Kinds   []kind
+}
+
+type goImport struct {
+	Alias string
+	Path  string
+}
+
+// kind is the client of a client-enabled kind.
+type kind struct {
+	Name string
+	// Plural is the name of the client's method in its group client, such
+	// as Ingresses.
+	Plural     string
+	Resource   string
+	Namespaced bool
+	// Type and List are the Go types of the objects and lists of the kind,
+	// with List being struct{} if the kind has no list type.
+	Type  string
+	List  string
+	Verbs map[string]bool
+
+	pkgPath string
+	hasList bool
+}
+
+func (g *goClientGenerator) Generate(pkgs []loader.Package) error {
+	g.config.Logger.Debug("generating")
+
+	if g.config.Package == "" {
+		return errors.New("no clientset package given for the generated clients")
+	}
+	g.diags = generator.Diagnostics{}
+
+	groups := g.groups(pkgs)
+	if len(groups) == 0 {
+		g.config.Logger.Warn("no client-enabled types found")
+		return g.diags.Err()
+	}
+
+	for _, grp := range groups {
+		g.config.Logger.Debug("generating for group version", "apiVersion", grp.APIVersion, "package", grp.Name)
+		dir := path.Join("typed", grp.Name)
+		if err := g.writeFile(path.Join(dir, "client.go"), groupTemplate, grp); err != nil {
+			return err
+		}
+		if err := g.writeFile(path.Join(dir, "fake", "client.go"), fakeGroupTemplate, grp); err != nil {
+			return err
+		}
+	}
+
+	p := struct {
+		Package string
+		Name    string
+		Groups  []*group
+	}{
+		Package: g.config.Package,
+		Name:    packageName(path.Base(g.config.Package)),
+		Groups:  groups,
+	}
+	for _, f := range []struct {
+		name string
+		tmpl *template.Template
+	}{
+		{"clientset.go", clientsetTemplate},
+		{path.Join("fake", "clientset.go"), fakeClientsetTemplate},
+		{path.Join("rest", "rest.go"), restTemplate},
+		{path.Join("tracker", "tracker.go"), trackerTemplate},
+	} {
+		if err := g.writeFile(f.name, f.tmpl, p); err != nil {
+			return err
+		}
+	}
+
+	return g.diags.Err()
+}
+
+// groups returns the typed client packages of the client-enabled kinds,
+// ordered by API group and version, recording problems with kinds and
+// leaving out those that have any.
+func (g *goClientGenerator) groups(pkgs []loader.Package) []*group {
+	byAPIVersion := map[string]*group{}
+	for _, pkg := range pkgs {
+		listTypes := map[string]struct{}{}
+		for _, typ := range pkg.Types {
+			listTypes[typ.Name] = struct{}{}
+		}
+
+		for _, typ := range pkg.Types {
+			if !typ.GenerateClient {
+				continue
+			}
+			if typ.Version == "" {
+				g.diags.Addf(typ.Position, "type %s: client-enabled type has no API version", typ.Name)
+				continue
+			}
+
+			grp := byAPIVersion[typ.APIVersion()]
+			if grp == nil {
+				grp = &group{
+					Package:    g.config.Package,
+					Group:      typ.Group,
+					Version:    typ.Version,
+					APIVersion: typ.APIVersion(),
+					APIPath:    "/apis/" + typ.APIVersion(),
+				}
+				if typ.Group == "" {
+					grp.APIPath = "/api/" + typ.Version
+				}
+				byAPIVersion[typ.APIVersion()] = grp
+			}
+
+			k, ok := g.kind(grp, pkg, typ, listTypes)
+			if ok {
+				grp.Kinds = append(grp.Kinds, k)
+			}
+		}
+	}
+
+	groups := make([]*group, 0, len(byAPIVersion))
+	for _, grp := range byAPIVersion {
+		if len(grp.Kinds) > 0 {
+			groups = append(groups, grp)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Group != groups[j].Group {
+			return groups[i].Group < groups[j].Group
+		}
+		return groups[i].Version < groups[j].Version
+	})
+
+	names := groupNames(groups)
+	for _, grp := range groups {
+		grp.Name, grp.GoName = names[grp.APIVersion][0], names[grp.APIVersion][1]
+		grp.Field = lowerFirst(grp.GoName)
+		sort.Slice(grp.Kinds, func(i, j int) bool { return grp.Kinds[i].Name < grp.Kinds[j].Name })
+		grp.resolveTypes()
+	}
+	return groups
+}
+
+// kind returns the client of typ. It reports false if typ has no verbs or
+// problems, recording the problems.
+func (g *goClientGenerator) kind(grp *group, pkg loader.Package, typ loader.Type, listTypes map[string]struct{}) (kind, bool) {
+	markers := typ.Markers.Client()
+	k := kind{
+		Name:       typ.Name,
+		Plural:     loader.Plural(typ.Name),
+		Resource:   typ.Resource(),
+		Namespaced: typ.Namespaced,
+		Verbs:      map[string]bool{},
+		pkgPath:    loader.StripVendor(pkg.Path),
+	}
+	hasVerbs := false
+	for _, verb := range clientVerbs {
+		k.Verbs[verb] = markers.HasVerb(verb)
+		hasVerbs = hasVerbs || k.Verbs[verb]
+	}
+	if !hasVerbs {
+		g.config.Logger.Debug("ignoring client-enabled type without verbs", "type", typ.Name)
+		return k, false
+	}
+
+	for _, other := range grp.Kinds {
+		if other.Name == typ.Name {
+			g.diags.Addf(typ.Position, "type %s: kind %s of API version %s is also declared in %s", typ.Name, typ.Name, grp.APIVersion, other.pkgPath)
+			return k, false
+		}
+	}
+
+	// Lists are read as the list type of the kind, without which there is
+	// nothing to read them as.
+	_, k.hasList = listTypes[typ.Name+"List"]
+	if k.Verbs["list"] && !k.hasList {
+		g.config.Logger.Warn("generating client without list, as the list type is not loaded", "type", typ.Name, "list", typ.Name+"List")
+		k.Verbs["list"] = false
+	}
+	return k, true
+}
+
+// resolveTypes sets the Go types of the kinds of grp, importing their
+// packages under aliases named after the last two elements of their import
+// paths, such as corev1, or corev1api where that clashes with the names its
+// files use.
+func (grp *group) resolveTypes() {
+	aliases := map[string]string{}
+	taken := map[string]bool{"context": true, "rest": true, "tracker": true, "fake": true, "client": true, grp.Name: true}
+	for i, k := range grp.Kinds {
+		alias, ok := aliases[k.pkgPath]
+		if !ok {
+			base := packageName(path.Base(path.Dir(k.pkgPath)) + path.Base(k.pkgPath))
+			alias = base
+			for n := 1; taken[alias]; n++ {
+				alias = base + "api"
+				if n > 1 {
+					alias += strconv.Itoa(n)
+				}
+			}
+			taken[alias] = true
+			aliases[k.pkgPath] = alias
+			grp.Imports = append(grp.Imports, goImport{Alias: alias, Path: k.pkgPath})
+		}
+
+		grp.Kinds[i].Type = alias + "." + k.Name
+		grp.Kinds[i].List = "struct{}"
+		if k.hasList {
+			grp.Kinds[i].List = alias + "." + k.Name + "List"
+		}
+	}
+}
+
+func (g *goClientGenerator) writeFile(name string, tmpl *template.Template, data interface{}) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return errors.Wrapf(err, "failed to render %s", name)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return errors.Wrapf(err, "failed to format %s", name)
+	}
+
+	contents := g.config.WithHeader(generator.SlashComment, "", "", src)
+	return g.config.Output.WriteFile(name, contents)
+}
@@ -0,0 +1,13 @@
+package goclient_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGoClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GoClient Suite")
+}
@@ -0,0 +1,107 @@
+package goclient_test
+
+import (
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/inconshreveable/log15"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	. "github.com/jimmidyson/kube-client-gen/pkg/generator/goclient"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+const clientsetPackage = "k8s.io/kubernetes/pkg/client/clientset"
+
+var _ = Describe("GoClient", func() {
+	var logger log15.Logger
+
+	BeforeEach(func() {
+		logger = log15.New()
+		logger.SetHandler(log15.DiscardHandler())
+	})
+
+	generate := func(opts ...loader.Option) *generator.MemoryOutput {
+		pkgs, err := loader.New([]string{
+			"k8s.io/kubernetes/pkg/api/v1",
+			"k8s.io/kubernetes/pkg/apis/apps/v1",
+		}, logger, append([]loader.Option{loader.WithDir("../testdata/module")}, opts...)...).Load()
+		Expect(err).NotTo(HaveOccurred())
+
+		out := generator.NewMemoryOutput()
+		err = New(Config{Config: generator.Config{Logger: logger, Output: out}, Package: clientsetPackage}).Generate(pkgs)
+		Expect(err).NotTo(HaveOccurred())
+		return out
+	}
+
+	It("writes formatted clients and fakes for each group version", func() {
+		out := generate()
+		Expect(out.Files).To(HaveKey("clientset.go"))
+		Expect(out.Files).To(HaveKey("fake/clientset.go"))
+		Expect(out.Files).To(HaveKey("rest/rest.go"))
+		Expect(out.Files).To(HaveKey("tracker/tracker.go"))
+		Expect(out.Files).To(HaveKey("typed/corev1/fake/client.go"))
+		Expect(out.Files).To(HaveKey("typed/appsv1/client.go"))
+
+		for name, contents := range out.Files {
+			_, err := parser.ParseFile(token.NewFileSet(), name, contents, parser.ParseComments)
+			Expect(err).NotTo(HaveOccurred(), name)
+			Expect(format.Source(contents)).To(Equal(contents), name)
+		}
+
+		core := string(out.Files["typed/corev1/client.go"])
+		Expect(core).To(ContainSubstring(`rest.NewResource[apiv1.Pod, apiv1.PodList](c.client, "/api/v1", "pods", true, namespace)`))
+		Expect(core).To(ContainSubstring(`rest.NewResource[apiv1.Endpoints, apiv1.EndpointsList](c.client, "/api/v1", "endpoints", true, namespace)`))
+		Expect(core).To(ContainSubstring(`rest.NewResource[apiv1.Node, apiv1.NodeList](c.client, "/api/v1", "nodes", false, "")`))
+		Expect(core).To(ContainSubstring("Nodes() NodeInterface"))
+	})
+
+	It("warns about kinds listed without a list type", func() {
+		var warnings []string
+		logger.SetHandler(log15.FuncHandler(func(r *log15.Record) error {
+			if r.Lvl == log15.LvlWarn {
+				warnings = append(warnings, r.Msg)
+			}
+			return nil
+		}))
+
+		out := generate(loader.WithExcludes("k8s.io/kubernetes/pkg/api/v1.NodeList"))
+		Expect(warnings).To(ConsistOf("generating client without list, as the list type is not loaded"))
+		core := string(out.Files["typed/corev1/client.go"])
+		Expect(core).To(ContainSubstring("type NodeInterface interface"))
+		Expect(strings.Count(core, "List(ctx context.Context, opts rest.ListOptions)")).To(Equal(2), "only endpoints and pods are listed")
+	})
+
+	It("writes clients and fakes that treat objects as the API server would", func() {
+		if _, err := exec.LookPath("go"); err != nil {
+			Skip("go command not found")
+		}
+
+		dir, err := os.MkdirTemp("", "goclient")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		Expect(os.CopyFS(dir, os.DirFS("../testdata/module"))).To(Succeed())
+		clientset := filepath.Join(dir, strings.TrimPrefix(clientsetPackage, "k8s.io/kubernetes/"))
+		Expect(generate().CopyTo(generator.NewFileOutput(clientset, false))).To(Succeed())
+		for test, pkgDir := range map[string]string{"fake_test.go": "fake", "clientset_test.go": "."} {
+			contents, err := os.ReadFile(filepath.Join("testdata", test))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(clientset, pkgDir, test), contents, 0644)).To(Succeed())
+		}
+
+		cmd := exec.Command("go", "test", "./...")
+		cmd.Dir = clientset
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+		output, err := cmd.CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(output))
+	})
+})
@@ -0,0 +1,855 @@
+package goclient
+
+import (
+	"text/template"
+)
+
+// restTemplateText is the rest package, the HTTP client shared by the typed
+// clients.
+const restTemplateText = generatedComment + `
+// Package rest is the HTTP client shared by the typed clients.
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Config is the configuration of a client.
+type Config struct {
+	// Host is the URL of the API server, such as https://localhost:6443.
+	Host string
+	// BearerToken is sent in the Authorization header of requests, if set.
+	BearerToken string
+	// HTTPClient sends the requests. http.DefaultClient is used if it is
+	// nil.
+	HTTPClient *http.Client
+}
+
+// Client sends requests to an API server.
+type Client struct {
+	host        string
+	bearerToken string
+	httpClient  *http.Client
+}
+
+// NewForConfig returns a client for the API server of c.
+func NewForConfig(c *Config) (*Client, error) {
+	if c.Host == "" {
+		return nil, fmt.Errorf("no host configured")
+	}
+	if _, err := url.Parse(c.Host); err != nil {
+		return nil, fmt.Errorf("invalid host %q: %v", c.Host, err)
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		host:        strings.TrimSuffix(c.Host, "/"),
+		bearerToken: c.BearerToken,
+		httpClient:  httpClient,
+	}, nil
+}
+
+// ListOptions select the objects to list or watch.
+type ListOptions struct {
+	LabelSelector   string
+	FieldSelector   string
+	ResourceVersion string
+	Limit           int64
+	Continue        string
+}
+
+func (o ListOptions) query() url.Values {
+	q := url.Values{}
+	if o.LabelSelector != "" {
+		q.Set("labelSelector", o.LabelSelector)
+	}
+	if o.FieldSelector != "" {
+		q.Set("fieldSelector", o.FieldSelector)
+	}
+	if o.ResourceVersion != "" {
+		q.Set("resourceVersion", o.ResourceVersion)
+	}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.FormatInt(o.Limit, 10))
+	}
+	if o.Continue != "" {
+		q.Set("continue", o.Continue)
+	}
+	return q
+}
+
+// StatusError is returned for requests the API server did not succeed with.
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.Code, e.Message)
+}
+
+// NewNotFound returns the error for an object that does not exist.
+func NewNotFound(resource, name string) *StatusError {
+	return &StatusError{Code: http.StatusNotFound, Message: fmt.Sprintf("%s %q not found", resource, name)}
+}
+
+// NewAlreadyExists returns the error for an object that already exists.
+func NewAlreadyExists(resource, name string) *StatusError {
+	return &StatusError{Code: http.StatusConflict, Message: fmt.Sprintf("%s %q already exists", resource, name)}
+}
+
+// IsNotFound reports whether err is a StatusError for an object that does
+// not exist.
+func IsNotFound(err error) bool {
+	e, ok := err.(*StatusError)
+	return ok && e.Code == http.StatusNotFound
+}
+
+// IsAlreadyExists reports whether err is a StatusError for an object that
+// already exists.
+func IsAlreadyExists(err error) bool {
+	e, ok := err.(*StatusError)
+	return ok && e.Code == http.StatusConflict
+}
+
+// EventType is the type of a watch event.
+type EventType string
+
+const (
+	Added    EventType = "ADDED"
+	Modified EventType = "MODIFIED"
+	Deleted  EventType = "DELETED"
+	Error    EventType = "ERROR"
+)
+
+// Event is a change to a watched object. The Object of Error events is nil.
+type Event[T any] struct {
+	Type   EventType
+	Object *T
+}
+
+// Watcher delivers the events of a watch until it is stopped.
+type Watcher[T any] interface {
+	// Stop ends the watch and closes the result channel.
+	Stop()
+	ResultChan() <-chan Event[T]
+}
+
+// ObjectName returns the namespace and name of obj from its metadata.
+func ObjectName(obj interface{}) (namespace, name string, err error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return "", "", err
+	}
+	var o map[string]interface{}
+	if err := json.Unmarshal(data, &o); err != nil {
+		return "", "", err
+	}
+	meta, _ := o["metadata"].(map[string]interface{})
+	namespace, _ = meta["namespace"].(string)
+	name, _ = meta["name"].(string)
+	return namespace, name, nil
+}
+
+// WriteNamespace returns the namespace that a client in namespace, or in
+// all namespaces if it is empty, writes an object of objectNamespace to: the
+// namespace of the client, or else that of the object. Objects of
+// namespaced resources need a namespace, which must be the client's if both
+// have one; those of cluster-scoped resources have none.
+func WriteNamespace(namespaced bool, namespace, objectNamespace string) (string, error) {
+	switch {
+	case !namespaced:
+		return "", nil
+	case objectNamespace == "" && namespace == "":
+		return "", &StatusError{Code: http.StatusUnprocessableEntity, Message: "object has no namespace"}
+	case objectNamespace == "":
+		return namespace, nil
+	case namespace != "" && objectNamespace != namespace:
+		return "", &StatusError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("namespace %q of object does not match namespace %q", objectNamespace, namespace),
+		}
+	default:
+		return objectNamespace, nil
+	}
+}
+
+// Resource is a client for the objects of type T, with the list type L, of
+// a resource. Clients of namespaced resources are bound to a namespace, or
+// to all namespaces if it is empty; those of cluster-scoped resources have
+// none.
+type Resource[T any, L any] struct {
+	client     *Client
+	apiPath    string
+	resource   string
+	namespaced bool
+	namespace  string
+}
+
+// NewResource returns a client for the resource at apiPath, such as
+// /api/v1 or /apis/apps/v1, in namespace if the resource is namespaced.
+func NewResource[T any, L any](c *Client, apiPath, resource string, namespaced bool, namespace string) *Resource[T, L] {
+	return &Resource[T, L]{client: c, apiPath: apiPath, resource: resource, namespaced: namespaced, namespace: namespace}
+}
+
+func (r *Resource[T, L]) url(namespace, name string, query url.Values) string {
+	u := r.client.host + r.apiPath
+	if namespace != "" {
+		u += "/namespaces/" + url.PathEscape(namespace)
+	}
+	u += "/" + r.resource
+	if name != "" {
+		u += "/" + url.PathEscape(name)
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+func (r *Resource[T, L]) do(ctx context.Context, method, u string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	resp, err := r.send(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (r *Resource[T, L]) send(ctx context.Context, method, u string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.client.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+r.client.bearerToken)
+	}
+	resp, err := r.client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		var status map[string]interface{}
+		var message string
+		if json.Unmarshal(data, &status) == nil {
+			message, _ = status["message"].(string)
+		}
+		if message == "" {
+			message = strings.TrimSpace(string(data))
+		}
+		return nil, &StatusError{Code: resp.StatusCode, Message: message}
+	}
+	return resp, nil
+}
+
+// Get returns the object called name.
+func (r *Resource[T, L]) Get(ctx context.Context, name string) (*T, error) {
+	out := new(T)
+	if err := r.do(ctx, http.MethodGet, r.url(r.namespace, name, nil), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List returns the objects that match opts.
+func (r *Resource[T, L]) List(ctx context.Context, opts ListOptions) (*L, error) {
+	out := new(L)
+	if err := r.do(ctx, http.MethodGet, r.url(r.namespace, "", opts.query()), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// target returns the namespace obj is written to, as WriteNamespace does,
+// and its name.
+func (r *Resource[T, L]) target(obj interface{}) (namespace, name string, err error) {
+	namespace, name, err = ObjectName(obj)
+	if err != nil {
+		return "", "", err
+	}
+	namespace, err = WriteNamespace(r.namespaced, r.namespace, namespace)
+	return namespace, name, err
+}
+
+// Create creates obj, in the namespace of the client or else its own, and
+// returns it as created.
+func (r *Resource[T, L]) Create(ctx context.Context, obj *T) (*T, error) {
+	namespace, _, err := r.target(obj)
+	if err != nil {
+		return nil, err
+	}
+	out := new(T)
+	if err := r.do(ctx, http.MethodPost, r.url(namespace, "", nil), obj, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Update replaces the object with the namespace, as for Create, and name of
+// obj and returns it as updated.
+func (r *Resource[T, L]) Update(ctx context.Context, obj *T) (*T, error) {
+	namespace, name, err := r.target(obj)
+	if err != nil {
+		return nil, err
+	}
+	out := new(T)
+	if err := r.do(ctx, http.MethodPut, r.url(namespace, name, nil), obj, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete deletes the object called name.
+func (r *Resource[T, L]) Delete(ctx context.Context, name string) error {
+	return r.do(ctx, http.MethodDelete, r.url(r.namespace, name, nil), nil, nil)
+}
+
+// Watch watches the objects that match opts, until ctx is done or the
+// watcher is stopped.
+func (r *Resource[T, L]) Watch(ctx context.Context, opts ListOptions) (Watcher[T], error) {
+	q := opts.query()
+	q.Set("watch", "true")
+	ctx, cancel := context.WithCancel(ctx)
+	resp, err := r.send(ctx, http.MethodGet, r.url(r.namespace, "", q), nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	w := &streamWatcher[T]{cancel: cancel, result: make(chan Event[T])}
+	go w.receive(ctx, resp.Body)
+	return w, nil
+}
+
+type streamWatcher[T any] struct {
+	cancel context.CancelFunc
+	result chan Event[T]
+}
+
+func (w *streamWatcher[T]) Stop() {
+	w.cancel()
+}
+
+func (w *streamWatcher[T]) ResultChan() <-chan Event[T] {
+	return w.result
+}
+
+func (w *streamWatcher[T]) receive(ctx context.Context, body io.ReadCloser) {
+	defer close(w.result)
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+	for {
+		var e map[string]json.RawMessage
+		if err := dec.Decode(&e); err != nil {
+			return
+		}
+		var event Event[T]
+		if err := json.Unmarshal(e["type"], &event.Type); err != nil {
+			return
+		}
+		if event.Type != Error {
+			event.Object = new(T)
+			if err := json.Unmarshal(e["object"], event.Object); err != nil {
+				return
+			}
+		}
+		select {
+		case w.result <- event:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+`
+
+// trackerTemplateText is the tracker package, the in-memory object store
+// behind the fake clients.
+const trackerTemplateText = generatedComment + `
+// Package tracker is the in-memory object store behind the fake clients.
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"{{.Package}}/rest"
+)
+
+// Tracker stores objects by resource, namespace and name, as the JSON they
+// would be sent as, and notifies watchers of their changes. It is safe for
+// concurrent use.
+type Tracker struct {
+	mu              sync.Mutex
+	objects         map[string]map[key]json.RawMessage
+	watchers        map[*watcher]struct{}
+	resourceVersion int64
+}
+
+type key struct {
+	namespace string
+	name      string
+}
+
+type event struct {
+	Type   rest.EventType
+	Object json.RawMessage
+}
+
+// New returns an empty tracker.
+func New() *Tracker {
+	return &Tracker{
+		objects:  map[string]map[key]json.RawMessage{},
+		watchers: map[*watcher]struct{}{},
+	}
+}
+
+// Add adds obj to the resource at apiPath, such as /api/v1 and pods,
+// replacing any object with its namespace and name.
+func (t *Tracker) Add(apiPath, resource string, obj interface{}) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	o, k, err := t.encode(obj)
+	if err != nil {
+		return err
+	}
+	raw, err := t.stamp(o)
+	if err != nil {
+		return err
+	}
+	t.put(apiPath+"/"+resource, k, raw)
+	return nil
+}
+
+// encode returns obj as a JSON object and its key.
+func (t *Tracker) encode(obj interface{}) (map[string]interface{}, key, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, key{}, err
+	}
+	var o map[string]interface{}
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, key{}, err
+	}
+	meta, _ := o["metadata"].(map[string]interface{})
+	if meta == nil {
+		meta = map[string]interface{}{}
+		o["metadata"] = meta
+	}
+	name, _ := meta["name"].(string)
+	if name == "" {
+		return nil, key{}, &rest.StatusError{Code: http.StatusUnprocessableEntity, Message: "object has no name"}
+	}
+	ns, _ := meta["namespace"].(string)
+	return o, key{namespace: ns, name: name}, nil
+}
+
+// stamp returns the JSON of an encoded object with a new resource version.
+func (t *Tracker) stamp(o map[string]interface{}) (json.RawMessage, error) {
+	t.resourceVersion++
+	o["metadata"].(map[string]interface{})["resourceVersion"] = strconv.FormatInt(t.resourceVersion, 10)
+	return json.Marshal(o)
+}
+
+func (t *Tracker) put(resource string, k key, raw json.RawMessage) {
+	objects := t.objects[resource]
+	if objects == nil {
+		objects = map[key]json.RawMessage{}
+		t.objects[resource] = objects
+	}
+	eventType := rest.Added
+	if _, ok := objects[k]; ok {
+		eventType = rest.Modified
+	}
+	objects[k] = raw
+	t.notify(resource, k.namespace, event{Type: eventType, Object: raw})
+}
+
+func (t *Tracker) notify(resource, namespace string, e event) {
+	for w := range t.watchers {
+		if w.resource == resource && (w.namespace == "" || w.namespace == namespace) && w.selector.matches(e.Object) {
+			w.push(e)
+		}
+	}
+}
+
+func (t *Tracker) get(resource string, k key) (json.RawMessage, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	raw, ok := t.objects[resource][k]
+	if !ok {
+		return nil, rest.NewNotFound(resource, k.name)
+	}
+	return raw, nil
+}
+
+func (t *Tracker) list(resource, namespace string, sel selector) []json.RawMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	keys := make([]key, 0, len(t.objects[resource]))
+	for k := range t.objects[resource] {
+		if namespace == "" || k.namespace == namespace {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].namespace != keys[j].namespace {
+			return keys[i].namespace < keys[j].namespace
+		}
+		return keys[i].name < keys[j].name
+	})
+
+	items := make([]json.RawMessage, 0, len(keys))
+	for _, k := range keys {
+		if raw := t.objects[resource][k]; sel.matches(raw) {
+			items = append(items, raw)
+		}
+	}
+	return items
+}
+
+// write stores obj in the namespace given by rest.WriteNamespace. The object
+// must not exist if create is set and must exist otherwise.
+func (t *Tracker) write(resource string, namespaced bool, namespace string, obj interface{}, create bool) (json.RawMessage, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	o, k, err := t.encode(obj)
+	if err != nil {
+		return nil, err
+	}
+	k.namespace, err = rest.WriteNamespace(namespaced, namespace, k.namespace)
+	if err != nil {
+		return nil, err
+	}
+	meta := o["metadata"].(map[string]interface{})
+	if k.namespace == "" {
+		delete(meta, "namespace")
+	} else {
+		meta["namespace"] = k.namespace
+	}
+	_, exists := t.objects[resource][k]
+	switch {
+	case create && exists:
+		return nil, rest.NewAlreadyExists(resource, k.name)
+	case !create && !exists:
+		return nil, rest.NewNotFound(resource, k.name)
+	}
+	raw, err := t.stamp(o)
+	if err != nil {
+		return nil, err
+	}
+	t.put(resource, k, raw)
+	return raw, nil
+}
+
+func (t *Tracker) delete(resource string, k key) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	raw, ok := t.objects[resource][k]
+	if !ok {
+		return rest.NewNotFound(resource, k.name)
+	}
+	delete(t.objects[resource], k)
+	t.notify(resource, k.namespace, event{Type: rest.Deleted, Object: raw})
+	return nil
+}
+
+func (t *Tracker) watch(resource, namespace string, sel selector) *watcher {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w := &watcher{
+		resource:  resource,
+		namespace: namespace,
+		selector:  sel,
+		notified:  make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	t.watchers[w] = struct{}{}
+	return w
+}
+
+func (t *Tracker) stop(w *watcher) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.watchers[w]; ok {
+		delete(t.watchers, w)
+		close(w.done)
+	}
+}
+
+// watcher queues the events of a watch, so the tracker never waits for
+// watchers to receive them.
+type watcher struct {
+	resource  string
+	namespace string
+	selector  selector
+
+	mu       sync.Mutex
+	queue    []event
+	notified chan struct{}
+	done     chan struct{}
+}
+
+func (w *watcher) push(e event) {
+	w.mu.Lock()
+	w.queue = append(w.queue, e)
+	w.mu.Unlock()
+
+	select {
+	case w.notified <- struct{}{}:
+	default:
+	}
+}
+
+func (w *watcher) pop() []event {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	events := w.queue
+	w.queue = nil
+	return events
+}
+
+// selector is a parsed label selector. Only the equality based forms k=v,
+// k==v, k!=v, k and !k are supported.
+type selector []requirement
+
+type requirement struct {
+	key    string
+	value  string
+	op     string
+	exists bool
+}
+
+func parseSelector(s string) (selector, error) {
+	var sel selector
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+		case strings.Contains(part, "!="):
+			kv := strings.SplitN(part, "!=", 2)
+			sel = append(sel, requirement{key: strings.TrimSpace(kv[0]), value: strings.TrimSpace(kv[1]), op: "!="})
+		case strings.Contains(part, "=="):
+			kv := strings.SplitN(part, "==", 2)
+			sel = append(sel, requirement{key: strings.TrimSpace(kv[0]), value: strings.TrimSpace(kv[1]), op: "="})
+		case strings.Contains(part, "="):
+			kv := strings.SplitN(part, "=", 2)
+			sel = append(sel, requirement{key: strings.TrimSpace(kv[0]), value: strings.TrimSpace(kv[1]), op: "="})
+		case strings.ContainsAny(part, " ()"):
+			return nil, &rest.StatusError{Code: http.StatusBadRequest, Message: fmt.Sprintf("unsupported label selector %q", s)}
+		case strings.HasPrefix(part, "!"):
+			sel = append(sel, requirement{key: strings.TrimSpace(part[1:]), op: "exists"})
+		default:
+			sel = append(sel, requirement{key: part, op: "exists", exists: true})
+		}
+	}
+	return sel, nil
+}
+
+func (s selector) matches(raw json.RawMessage) bool {
+	if len(s) == 0 {
+		return true
+	}
+	var o map[string]interface{}
+	if err := json.Unmarshal(raw, &o); err != nil {
+		return false
+	}
+	meta, _ := o["metadata"].(map[string]interface{})
+	labels, _ := meta["labels"].(map[string]interface{})
+	for _, r := range s {
+		value, ok := labels[r.key].(string)
+		switch r.op {
+		case "=":
+			if !ok || value != r.value {
+				return false
+			}
+		case "!=":
+			if ok && value == r.value {
+				return false
+			}
+		case "exists":
+			if ok != r.exists {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Resource is a fake client for the objects of type T, with the list type
+// L, of a resource, which reads and writes them in a tracker. It behaves as
+// rest.Resource does, except that field selectors and paging are ignored.
+type Resource[T any, L any] struct {
+	tracker    *Tracker
+	resource   string
+	namespaced bool
+	namespace  string
+}
+
+// NewResource returns a fake client for the resource at apiPath, such as
+// /api/v1 or /apis/apps/v1, in namespace if the resource is namespaced.
+func NewResource[T any, L any](t *Tracker, apiPath, resource string, namespaced bool, namespace string) *Resource[T, L] {
+	return &Resource[T, L]{tracker: t, resource: apiPath + "/" + resource, namespaced: namespaced, namespace: namespace}
+}
+
+func decode[T any](raw json.RawMessage) (*T, error) {
+	out := new(T)
+	if err := json.Unmarshal(raw, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Get returns the object called name.
+func (r *Resource[T, L]) Get(ctx context.Context, name string) (*T, error) {
+	raw, err := r.tracker.get(r.resource, key{namespace: r.namespace, name: name})
+	if err != nil {
+		return nil, err
+	}
+	return decode[T](raw)
+}
+
+// List returns the objects that match the label selector of opts.
+func (r *Resource[T, L]) List(ctx context.Context, opts rest.ListOptions) (*L, error) {
+	sel, err := parseSelector(opts.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"items": r.tracker.list(r.resource, r.namespace, sel),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decode[L](data)
+}
+
+// Create adds obj, in the namespace of the client or else its own, and
+// returns it as added.
+func (r *Resource[T, L]) Create(ctx context.Context, obj *T) (*T, error) {
+	raw, err := r.tracker.write(r.resource, r.namespaced, r.namespace, obj, true)
+	if err != nil {
+		return nil, err
+	}
+	return decode[T](raw)
+}
+
+// Update replaces the object with the namespace, as for Create, and name of
+// obj and returns it as replaced.
+func (r *Resource[T, L]) Update(ctx context.Context, obj *T) (*T, error) {
+	raw, err := r.tracker.write(r.resource, r.namespaced, r.namespace, obj, false)
+	if err != nil {
+		return nil, err
+	}
+	return decode[T](raw)
+}
+
+// Delete deletes the object called name.
+func (r *Resource[T, L]) Delete(ctx context.Context, name string) error {
+	return r.tracker.delete(r.resource, key{namespace: r.namespace, name: name})
+}
+
+// Watch watches the changes to the objects that match the label selector of
+// opts from now on, until ctx is done or the watcher is stopped.
+func (r *Resource[T, L]) Watch(ctx context.Context, opts rest.ListOptions) (rest.Watcher[T], error) {
+	sel, err := parseSelector(opts.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	w := &typedWatcher[T]{
+		tracker: r.tracker,
+		watcher: r.tracker.watch(r.resource, r.namespace, sel),
+		result:  make(chan rest.Event[T]),
+	}
+	go w.receive(ctx)
+	return w, nil
+}
+
+type typedWatcher[T any] struct {
+	tracker *Tracker
+	watcher *watcher
+	result  chan rest.Event[T]
+}
+
+func (w *typedWatcher[T]) Stop() {
+	w.tracker.stop(w.watcher)
+}
+
+func (w *typedWatcher[T]) ResultChan() <-chan rest.Event[T] {
+	return w.result
+}
+
+func (w *typedWatcher[T]) receive(ctx context.Context) {
+	defer close(w.result)
+	defer w.Stop()
+
+	for {
+		select {
+		case <-w.watcher.notified:
+		case <-w.watcher.done:
+			return
+		case <-ctx.Done():
+			return
+		}
+		for _, e := range w.watcher.pop() {
+			obj, err := decode[T](e.Object)
+			if err != nil {
+				return
+			}
+			select {
+			case w.result <- rest.Event[T]{Type: e.Type, Object: obj}:
+			case <-w.watcher.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+`
+
+var (
+	restTemplate    = template.Must(template.New("rest").Parse(restTemplateText))
+	trackerTemplate = template.Must(template.New("tracker").Parse(trackerTemplateText))
+)
@@ -0,0 +1,66 @@
+package clientset_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/clientset"
+	"k8s.io/kubernetes/pkg/client/clientset/rest"
+)
+
+// TestNamespaces checks that clients write objects to the namespaces that
+// the fake clients write them to.
+func TestNamespaces(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		var obj map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&obj); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(obj)
+	}))
+	defer server.Close()
+
+	cs, err := clientset.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	a := &v1.Pod{ObjectMeta: v1.ObjectMeta{Name: "a", Namespace: "other"}}
+	if _, err := cs.CoreV1().Pods("").Create(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.CoreV1().Pods("").Update(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.CoreV1().Pods("other").Update(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.CoreV1().Pods("").Create(ctx, &v1.Pod{ObjectMeta: v1.ObjectMeta{Name: "b"}}); err == nil {
+		t.Fatal("creating a pod without a namespace succeeded")
+	}
+	if _, err := cs.CoreV1().Pods("default").Update(ctx, a); err == nil {
+		t.Fatal("updating a pod of another namespace succeeded")
+	}
+	if _, err := cs.CoreV1().Nodes().Create(ctx, &v1.Node{ObjectMeta: v1.ObjectMeta{Name: "node", Namespace: "default"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"POST /api/v1/namespaces/other/pods",
+		"PUT /api/v1/namespaces/other/pods/a",
+		"PUT /api/v1/namespaces/other/pods/a",
+		"POST /api/v1/nodes",
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Fatalf("got requests %q, want %q", requests, want)
+	}
+}
@@ -0,0 +1,183 @@
+package fake_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/clientset/fake"
+	"k8s.io/kubernetes/pkg/client/clientset/rest"
+)
+
+func pod(name string, labels map[string]string) *v1.Pod {
+	return &v1.Pod{ObjectMeta: v1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func next(t *testing.T, w rest.Watcher[v1.Pod]) rest.Event[v1.Pod] {
+	t.Helper()
+	select {
+	case e := <-w.ResultChan():
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("no watch event")
+		return rest.Event[v1.Pod]{}
+	}
+}
+
+func TestCreateUpdateDelete(t *testing.T) {
+	ctx := context.Background()
+	pods := fake.NewSimpleClientset(&v1.Pod{ObjectMeta: v1.ObjectMeta{Name: "a", Namespace: "default"}}).CoreV1().Pods("default")
+
+	if _, err := pods.Create(ctx, pod("a", nil)); !rest.IsAlreadyExists(err) {
+		t.Fatalf("creating an existing pod: got %v, want already exists", err)
+	}
+	if _, err := pods.Update(ctx, pod("b", nil)); !rest.IsNotFound(err) {
+		t.Fatalf("updating a missing pod: got %v, want not found", err)
+	}
+
+	b, err := pods.Create(ctx, pod("b", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The failed writes must not have used up resource versions.
+	if b.Namespace != "default" || b.ResourceVersion != "2" {
+		t.Fatalf("created pod has namespace %q and resource version %q, want default and 2", b.Namespace, b.ResourceVersion)
+	}
+
+	b.Spec.NodeName = "node"
+	b, err = pods.Update(ctx, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ResourceVersion != "3" {
+		t.Fatalf("updated pod has resource version %q, want 3", b.ResourceVersion)
+	}
+	if got, err := pods.Get(ctx, "b"); err != nil || got.Spec.NodeName != "node" {
+		t.Fatalf("getting the updated pod: got %+v, %v", got, err)
+	}
+
+	if _, err := pods.Create(ctx, &v1.Pod{ObjectMeta: v1.ObjectMeta{Name: "c", Namespace: "other"}}); err == nil {
+		t.Fatal("creating a pod of another namespace succeeded")
+	}
+
+	if err := pods.Delete(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := pods.Delete(ctx, "a"); !rest.IsNotFound(err) {
+		t.Fatalf("deleting a missing pod: got %v, want not found", err)
+	}
+	if _, err := pods.Get(ctx, "a"); !rest.IsNotFound(err) {
+		t.Fatalf("getting a deleted pod: got %v, want not found", err)
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	ctx := context.Background()
+	cs := fake.NewSimpleClientset()
+
+	// Clients for all namespaces write objects to their own namespace.
+	a, err := cs.CoreV1().Pods("").Create(ctx, &v1.Pod{ObjectMeta: v1.ObjectMeta{Name: "a", Namespace: "other"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Namespace != "other" {
+		t.Fatalf("created pod has namespace %q, want other", a.Namespace)
+	}
+	a.Spec.NodeName = "node"
+	if _, err := cs.CoreV1().Pods("").Update(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := cs.CoreV1().Pods("other").Get(ctx, "a"); err != nil || got.Spec.NodeName != "node" {
+		t.Fatalf("getting the updated pod: got %+v, %v", got, err)
+	}
+
+	if _, err := cs.CoreV1().Pods("").Create(ctx, pod("b", nil)); err == nil {
+		t.Fatal("creating a pod without a namespace succeeded")
+	}
+	if _, err := cs.CoreV1().Pods("default").Update(ctx, a); err == nil {
+		t.Fatal("updating a pod of another namespace succeeded")
+	}
+
+	node, err := cs.CoreV1().Nodes().Create(ctx, &v1.Node{ObjectMeta: v1.ObjectMeta{Name: "node", Namespace: "default"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Namespace != "" {
+		t.Fatalf("created node has namespace %q, want none", node.Namespace)
+	}
+	if _, err := cs.CoreV1().Nodes().Get(ctx, "node"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	cs := fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: v1.ObjectMeta{Name: "b", Namespace: "default", Labels: map[string]string{"app": "web"}}},
+		&v1.Pod{ObjectMeta: v1.ObjectMeta{Name: "a", Namespace: "default", Labels: map[string]string{"app": "web"}}},
+		&v1.Pod{ObjectMeta: v1.ObjectMeta{Name: "c", Namespace: "default", Labels: map[string]string{"app": "db"}}},
+		&v1.Pod{ObjectMeta: v1.ObjectMeta{Name: "d", Namespace: "other", Labels: map[string]string{"app": "web"}}},
+		&v1.Node{ObjectMeta: v1.ObjectMeta{Name: "node"}},
+	)
+
+	list, err := cs.CoreV1().Pods("default").List(ctx, rest.ListOptions{LabelSelector: "app=web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 2 || list.Items[0].Name != "a" || list.Items[1].Name != "b" {
+		t.Fatalf("listed %+v, want pods a and b", list.Items)
+	}
+
+	all, err := cs.CoreV1().Pods("").List(ctx, rest.ListOptions{})
+	if err != nil || len(all.Items) != 4 {
+		t.Fatalf("listing pods of all namespaces: got %+v, %v", all, err)
+	}
+
+	nodes, err := cs.CoreV1().Nodes().List(ctx, rest.ListOptions{})
+	if err != nil || len(nodes.Items) != 1 || nodes.Items[0].Name != "node" {
+		t.Fatalf("listing nodes: got %+v, %v", nodes, err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ctx := context.Background()
+	pods := fake.NewSimpleClientset().CoreV1().Pods("default")
+
+	w, err := pods.Watch(ctx, rest.ListOptions{LabelSelector: "app=web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	if _, err := pods.Create(ctx, pod("db", map[string]string{"app": "db"})); err != nil {
+		t.Fatal(err)
+	}
+	web, err := pods.Create(ctx, pod("web", map[string]string{"app": "web"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := next(t, w); e.Type != rest.Added || e.Object.Name != "web" {
+		t.Fatalf("got %s event for %+v, want web added", e.Type, e.Object)
+	}
+
+	web.Spec.NodeName = "node"
+	if _, err := pods.Update(ctx, web); err != nil {
+		t.Fatal(err)
+	}
+	if e := next(t, w); e.Type != rest.Modified || e.Object.Spec.NodeName != "node" {
+		t.Fatalf("got %s event for %+v, want web modified", e.Type, e.Object)
+	}
+
+	if err := pods.Delete(ctx, "web"); err != nil {
+		t.Fatal(err)
+	}
+	if e := next(t, w); e.Type != rest.Deleted || e.Object.Name != "web" {
+		t.Fatalf("got %s event for %+v, want web deleted", e.Type, e.Object)
+	}
+
+	w.Stop()
+	if _, ok := <-w.ResultChan(); ok {
+		t.Fatal("result channel is open after stopping the watch")
+	}
+}
@@ -0,0 +1,84 @@
+package goclient
+
+import (
+	"strings"
+	"unicode"
+)
+
+// packageName returns s as a Go package name, lower case and without the
+// characters that are not allowed in identifiers.
+func packageName(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "x" + name
+	}
+	return name
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// groupNames returns the package name and clientset method name of each
+// group, keyed by API version, such as appsv1 and AppsV1 for apps/v1. Names
+// are made of the first label of the API group, with the core group being
+// core, and of more labels for groups that would otherwise share names.
+func groupNames(groups []*group) map[string][2]string {
+	labels := map[string][]string{}
+	counts := map[string]int{}
+	for _, grp := range groups {
+		labels[grp.APIVersion] = []string{"core"}
+		if grp.Group != "" {
+			labels[grp.APIVersion] = strings.Split(grp.Group, ".")
+		}
+		counts[grp.APIVersion] = 1
+	}
+
+	names := map[string][2]string{}
+	for {
+		byName := map[string][]string{}
+		for _, grp := range groups {
+			var name, goName string
+			for _, label := range labels[grp.APIVersion][:counts[grp.APIVersion]] {
+				name += packageName(label)
+				goName += upperFirst(packageName(label))
+			}
+			name += packageName(grp.Version)
+			goName += upperFirst(packageName(grp.Version))
+			names[grp.APIVersion] = [2]string{name, goName}
+			byName[name] = append(byName[name], grp.APIVersion)
+		}
+
+		changed := false
+		for _, apiVersions := range byName {
+			if len(apiVersions) < 2 {
+				continue
+			}
+			for _, apiVersion := range apiVersions {
+				if counts[apiVersion] < len(labels[apiVersion]) {
+					counts[apiVersion]++
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			return names
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"strings"
+)
+
+// GeneratedMarker marks generated files in their header.
+const GeneratedMarker = "Generated by kube-client-gen, DO NOT EDIT."
+
+// CommentStyle is the comment syntax of an output format. Headers are written
+// as a block opened by Start and closed by End, if set, with every line
+// prefixed by Line. Occurrences of Forbidden in the header, such as a */ that
+// would close a block comment early, are replaced by Escaped.
+type CommentStyle struct {
+	Start     string
+	Line      string
+	End       string
+	Forbidden string
+	Escaped   string
+}
+
+var (
+	// JavaComment is a Javadoc style block comment, as license header checks
+	// expect in Java files.
+	JavaComment = CommentStyle{Start: "/**", Line: " * ", End: " */", Forbidden: "*/", Escaped: `*\/`}
+	// BlockComment is a C style block comment, as used in TypeScript.
+	BlockComment = CommentStyle{Start: "/*", Line: " * ", End: " */", Forbidden: "*/", Escaped: `*\/`}
+	// SlashComment is a C++ style line comment, as used in protobuf files.
+	SlashComment = CommentStyle{Line: "// "}
+	// HashComment is a shell style line comment, as used in Python and YAML.
+	HashComment = CommentStyle{Line: "# "}
+	// XMLComment is an XML comment, which follows any XML declaration. XML
+	// comments must not contain -- at all.
+	XMLComment = CommentStyle{Start: "<!--", Line: "    ", End: "-->", Forbidden: "--", Escaped: "- -"}
+)
+
+// HeaderComment returns the header comment for a file generated from the Go
+// package pkgPath with the API version apiVersion, either of which may be
+// empty, such as for files generated from several packages. It is empty
+// unless c has a header.
+func (c Config) HeaderComment(style CommentStyle, pkgPath, apiVersion string) string {
+	if c.Header == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimRight(c.Header, "\n"), "\n")
+	lines = append(lines, "", GeneratedMarker)
+	switch {
+	case pkgPath != "" && apiVersion != "":
+		lines = append(lines, "Source: "+pkgPath+", version "+apiVersion)
+	case pkgPath != "":
+		lines = append(lines, "Source: "+pkgPath)
+	}
+
+	var b strings.Builder
+	if style.Start != "" {
+		b.WriteString(style.Start + "\n")
+	}
+	for _, l := range lines {
+		b.WriteString(strings.TrimRight(style.Line+style.escape(l), " ") + "\n")
+	}
+	if style.End != "" {
+		b.WriteString(style.End + "\n")
+	}
+	return b.String()
+}
+
+// escape replaces the occurrences of the forbidden sequence in s, including
+// those that replacing others makes, such as in --- for XML.
+func (style CommentStyle) escape(s string) string {
+	if style.Forbidden == "" {
+		return s
+	}
+	for strings.Contains(s, style.Forbidden) {
+		s = strings.Replace(s, style.Forbidden, style.Escaped, -1)
+	}
+	return s
+}
+
+// WithHeader returns data with the header comment added at its start, or,
+// for XML, after its XML declaration.
+func (c Config) WithHeader(style CommentStyle, pkgPath, apiVersion string, data []byte) []byte {
+	header := c.HeaderComment(style, pkgPath, apiVersion)
+	if header == "" {
+		return data
+	}
+
+	var prolog []byte
+	if style == XMLComment && strings.HasPrefix(string(data), "<?xml") {
+		if i := strings.IndexByte(string(data), '\n'); i >= 0 {
+			prolog, data = data[:i+1], data[i+1:]
+		}
+	}
+
+	res := make([]byte, 0, len(prolog)+len(header)+1+len(data))
+	res = append(res, prolog...)
+	res = append(res, header...)
+	if len(data) > 0 {
+		res = append(res, '\n')
+	}
+	return append(res, data...)
+}
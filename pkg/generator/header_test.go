@@ -0,0 +1,61 @@
+package generator_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/jimmidyson/kube-client-gen/pkg/generator"
+)
+
+var _ = Describe("Header", func() {
+	c := Config{Header: "Copyright 2016\n\nLicensed.\n"}
+
+	It("adds nothing without a header", func() {
+		Expect(Config{}.WithHeader(JavaComment, "example.com/api/v1", "v1", []byte("package a;\n"))).To(Equal([]byte("package a;\n")))
+	})
+
+	It("renders the header in block comments", func() {
+		Expect(string(c.WithHeader(JavaComment, "example.com/api/v1", "apps/v1", []byte("package a;\n")))).To(Equal(`/**
+ * Copyright 2016
+ *
+ * Licensed.
+ *
+ * Generated by kube-client-gen, DO NOT EDIT.
+ * Source: example.com/api/v1, version apps/v1
+ */
+
+package a;
+`))
+	})
+
+	It("renders the header in line comments", func() {
+		Expect(c.HeaderComment(HashComment, "", "")).To(Equal("# Copyright 2016\n#\n# Licensed.\n#\n# Generated by kube-client-gen, DO NOT EDIT.\n"))
+	})
+
+	It("escapes sequences that would end block comments early", func() {
+		c := Config{Header: "Copyright 2016 */ ACME\n"}
+		Expect(c.HeaderComment(JavaComment, "", "")).To(Equal("/**\n * Copyright 2016 *\\/ ACME\n *\n * Generated by kube-client-gen, DO NOT EDIT.\n */\n"))
+		Expect(c.HeaderComment(BlockComment, "", "")).To(Equal("/*\n * Copyright 2016 *\\/ ACME\n *\n * Generated by kube-client-gen, DO NOT EDIT.\n */\n"))
+		Expect(c.HeaderComment(SlashComment, "", "")).To(Equal("// Copyright 2016 */ ACME\n//\n// Generated by kube-client-gen, DO NOT EDIT.\n"))
+	})
+
+	It("escapes double hyphens in XML comments", func() {
+		c := Config{Header: "Copyright 2016 -- ACME --> ---\n"}
+		Expect(c.HeaderComment(XMLComment, "", "")).To(Equal("<!--\n    Copyright 2016 - - ACME - -> - - -\n\n    Generated by kube-client-gen, DO NOT EDIT.\n-->\n"))
+	})
+
+	It("renders the header after an XML declaration", func() {
+		Expect(string(c.WithHeader(XMLComment, "example.com/api/v1", "", []byte("<?xml version=\"1.0\"?>\n<project/>\n")))).To(Equal(`<?xml version="1.0"?>
+<!--
+    Copyright 2016
+
+    Licensed.
+
+    Generated by kube-client-gen, DO NOT EDIT.
+    Source: example.com/api/v1
+-->
+
+<project/>
+`))
+	})
+})
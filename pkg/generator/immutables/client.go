@@ -0,0 +1,696 @@
+package immutables
+
+import (
+	"bytes"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+// clientVerbs are the verbs of generated clients, as named in +genclient
+// markers.
+var clientVerbs = []string{"list", "get", "create", "update", "updateStatus", "patch", "delete", "deleteCollection", "watch"}
+
+const clientInterfaceTemplateText = `package {{.Package}};
+
+import {{.SupportPackage}}.ListOptions;
+import {{.SupportPackage}}.PatchType;
+import {{.SupportPackage}}.Watch;
+import {{.SupportPackage}}.Watcher;
+
+/*
+ * {{.ClassName}}Client reads and writes objects of kind {{.ClassName}} in apiVersion {{.APIVersion}}.{{if .Namespaced}}
+ * Methods taking a namespace are scoped to it.{{end}}
+ */
+public interface {{.ClassName}}Client {
+{{if .Verbs.list}}
+  /*
+   * Lists the objects{{if .Namespaced}} in a namespace{{end}}.
+   */
+  {{.ListClass}} list({{if .Namespaced}}String namespace, {{end}}ListOptions options);
+{{if .Namespaced}}
+  /*
+   * Lists the objects across all namespaces.
+   */
+  {{.ListClass}} listAllNamespaces(ListOptions options);
+{{end}}{{end}}{{if .Verbs.get}}
+  /*
+   * Returns the named object.
+   */
+  {{.TypeClass}} get({{if .Namespaced}}String namespace, {{end}}String name);
+{{end}}{{if .Verbs.create}}
+  /*
+   * Creates an object, returning it as stored.
+   */
+  {{.TypeClass}} create({{if .Namespaced}}String namespace, {{end}}{{.TypeClass}} object);
+{{end}}{{if .Verbs.update}}
+  /*
+   * Replaces the named object, returning it as stored.
+   */
+  {{.TypeClass}} update({{if .Namespaced}}String namespace, {{end}}String name, {{.TypeClass}} object);
+{{end}}{{if .Verbs.updateStatus}}
+  /*
+   * Replaces the status of the named object, returning it as stored.
+   */
+  {{.TypeClass}} updateStatus({{if .Namespaced}}String namespace, {{end}}String name, {{.TypeClass}} object);
+{{end}}{{if .Verbs.patch}}
+  /*
+   * Patches the named object, returning it as stored.
+   */
+  {{.TypeClass}} patch({{if .Namespaced}}String namespace, {{end}}String name, PatchType type, String patch);
+{{if .Status}}
+  /*
+   * Patches the status of the named object, returning it as stored.
+   */
+  {{.TypeClass}} patchStatus({{if .Namespaced}}String namespace, {{end}}String name, PatchType type, String patch);
+{{end}}{{end}}{{if .Verbs.delete}}
+  /*
+   * Deletes the named object.
+   */
+  void delete({{if .Namespaced}}String namespace, {{end}}String name);
+{{end}}{{if .Verbs.deleteCollection}}
+  /*
+   * Deletes the objects{{if .Namespaced}} in a namespace{{end}} that match the options.
+   */
+  void deleteCollection({{if .Namespaced}}String namespace, {{end}}ListOptions options);
+{{end}}{{if .Verbs.watch}}
+  /*
+   * Watches the objects{{if .Namespaced}} in a namespace, or across all
+   * namespaces if it is null,{{end}} until the returned watch is closed.
+   */
+  Watch watch({{if .Namespaced}}String namespace, {{end}}ListOptions options, Watcher<{{.TypeClass}}> watcher);
+{{end}}
+}
+`
+
+const clientImplementationTemplateText = `package {{.Package}};
+
+import {{.SupportPackage}}.ClientConfig;
+import {{.SupportPackage}}.HttpResourceClient;
+import {{.SupportPackage}}.ListOptions;
+import {{.SupportPackage}}.PatchType;
+import {{.SupportPackage}}.Watch;
+import {{.SupportPackage}}.Watcher;
+
+/*
+ * Http{{.ClassName}}Client is the {{.ClassName}}Client of a Kubernetes API server,
+ * using java.net.http.
+ */
+public class Http{{.ClassName}}Client extends HttpResourceClient<{{.TypeClass}}> implements {{.ClassName}}Client {{"{"}}{{$ns := ""}}{{$nsArg := "null"}}{{if .Namespaced}}{{$ns = "String namespace, "}}{{$nsArg = "namespace"}}{{end}}
+
+  public Http{{.ClassName}}Client(ClientConfig config) {
+    super(config, "{{.APIPath}}", "{{.Resource}}", {{.TypeClass}}.class);
+  }
+{{if .Verbs.list}}
+  @Override
+  public {{.ListClass}} list({{$ns}}ListOptions options) {
+    return doList({{$nsArg}}, options, {{.ListClass}}.class);
+  }
+{{if .Namespaced}}
+  @Override
+  public {{.ListClass}} listAllNamespaces(ListOptions options) {
+    return doList(null, options, {{.ListClass}}.class);
+  }
+{{end}}{{end}}{{if .Verbs.get}}
+  @Override
+  public {{.TypeClass}} get({{$ns}}String name) {
+    return doGet({{$nsArg}}, name, null);
+  }
+{{end}}{{if .Verbs.create}}
+  @Override
+  public {{.TypeClass}} create({{$ns}}{{.TypeClass}} object) {
+    return doCreate({{$nsArg}}, object);
+  }
+{{end}}{{if .Verbs.update}}
+  @Override
+  public {{.TypeClass}} update({{$ns}}String name, {{.TypeClass}} object) {
+    return doUpdate({{$nsArg}}, name, null, object);
+  }
+{{end}}{{if .Verbs.updateStatus}}
+  @Override
+  public {{.TypeClass}} updateStatus({{$ns}}String name, {{.TypeClass}} object) {
+    return doUpdate({{$nsArg}}, name, "status", object);
+  }
+{{end}}{{if .Verbs.patch}}
+  @Override
+  public {{.TypeClass}} patch({{$ns}}String name, PatchType type, String patch) {
+    return doPatch({{$nsArg}}, name, null, type, patch);
+  }
+{{if .Status}}
+  @Override
+  public {{.TypeClass}} patchStatus({{$ns}}String name, PatchType type, String patch) {
+    return doPatch({{$nsArg}}, name, "status", type, patch);
+  }
+{{end}}{{end}}{{if .Verbs.delete}}
+  @Override
+  public void delete({{$ns}}String name) {
+    doDelete({{$nsArg}}, name, null);
+  }
+{{end}}{{if .Verbs.deleteCollection}}
+  @Override
+  public void deleteCollection({{$ns}}ListOptions options) {
+    doDelete({{$nsArg}}, null, options);
+  }
+{{end}}{{if .Verbs.watch}}
+  @Override
+  public Watch watch({{$ns}}ListOptions options, Watcher<{{.TypeClass}}> watcher) {
+    return doWatch({{$nsArg}}, options, watcher);
+  }
+{{end}}
+}
+`
+
+// clientSupportTemplateTexts are the classes shared by all clients, keyed by
+// class name.
+var clientSupportTemplateTexts = map[string]string{
+	"ClientConfig": `package {{.Package}};
+
+/*
+ * ClientConfig holds how clients reach a Kubernetes API server. The object
+ * mapper should have the KubernetesModule registered.
+ */
+public final class ClientConfig {
+
+  private final java.net.http.HttpClient httpClient;
+  private final java.net.URI server;
+  private final com.fasterxml.jackson.databind.ObjectMapper mapper;
+  private final String bearerToken;
+
+  public ClientConfig(java.net.http.HttpClient httpClient, java.net.URI server, com.fasterxml.jackson.databind.ObjectMapper mapper) {
+    this(httpClient, server, mapper, null);
+  }
+
+  private ClientConfig(java.net.http.HttpClient httpClient, java.net.URI server, com.fasterxml.jackson.databind.ObjectMapper mapper, String bearerToken) {
+    this.httpClient = java.util.Objects.requireNonNull(httpClient, "httpClient");
+    this.server = java.util.Objects.requireNonNull(server, "server");
+    this.mapper = java.util.Objects.requireNonNull(mapper, "mapper");
+    this.bearerToken = bearerToken;
+  }
+
+  /*
+   * Returns a copy of the config that authenticates with a bearer token.
+   */
+  public ClientConfig withBearerToken(String bearerToken) {
+    return new ClientConfig(httpClient, server, mapper, bearerToken);
+  }
+
+  public java.net.http.HttpClient getHttpClient() {
+    return httpClient;
+  }
+
+  public java.net.URI getServer() {
+    return server;
+  }
+
+  public com.fasterxml.jackson.databind.ObjectMapper getMapper() {
+    return mapper;
+  }
+
+  public java.util.Optional<String> getBearerToken() {
+    return java.util.Optional.ofNullable(bearerToken);
+  }
+
+}
+`,
+	"ListOptions": `package {{.Package}};
+
+/*
+ * ListOptions restricts the objects that are listed, watched or deleted.
+ */
+public final class ListOptions {
+
+  private final java.util.Map<String, String> query;
+
+  private ListOptions(java.util.Map<String, String> query) {
+    this.query = query;
+  }
+
+  /*
+   * Returns options that do not restrict objects.
+   */
+  public static ListOptions all() {
+    return new ListOptions(java.util.Collections.emptyMap());
+  }
+
+  private ListOptions with(String name, String value) {
+    java.util.Map<String, String> query = new java.util.LinkedHashMap<>(this.query);
+    query.put(name, value);
+    return new ListOptions(java.util.Collections.unmodifiableMap(query));
+  }
+
+  public ListOptions withLabelSelector(String labelSelector) {
+    return with("labelSelector", labelSelector);
+  }
+
+  public ListOptions withFieldSelector(String fieldSelector) {
+    return with("fieldSelector", fieldSelector);
+  }
+
+  public ListOptions withLimit(long limit) {
+    return with("limit", Long.toString(limit));
+  }
+
+  public ListOptions withContinue(String continueToken) {
+    return with("continue", continueToken);
+  }
+
+  public ListOptions withResourceVersion(String resourceVersion) {
+    return with("resourceVersion", resourceVersion);
+  }
+
+  /*
+   * Returns the options as query parameters, in the order they were set.
+   */
+  public java.util.Map<String, String> toQuery() {
+    return query;
+  }
+
+}
+`,
+	"PatchType": `package {{.Package}};
+
+/*
+ * PatchType is the format of a patch.
+ */
+public enum PatchType {
+
+  JSON("application/json-patch+json"),
+  MERGE("application/merge-patch+json"),
+  STRATEGIC_MERGE("application/strategic-merge-patch+json");
+
+  private final String contentType;
+
+  PatchType(String contentType) {
+    this.contentType = contentType;
+  }
+
+  public String getContentType() {
+    return contentType;
+  }
+
+}
+`,
+	"KubernetesClientException": `package {{.Package}};
+
+/*
+ * KubernetesClientException is thrown when a request to the API server fails.
+ */
+public class KubernetesClientException extends RuntimeException {
+
+  private final int code;
+
+  public KubernetesClientException(int code, String message) {
+    super(message);
+    this.code = code;
+  }
+
+  public KubernetesClientException(String message, Throwable cause) {
+    super(message, cause);
+    this.code = 0;
+  }
+
+  /*
+   * Returns the HTTP status code of the response, or 0 if there was none.
+   */
+  public int getCode() {
+    return code;
+  }
+
+}
+`,
+	"WatchEvent": `package {{.Package}};
+
+/*
+ * WatchEvent is a change to a watched object.
+ */
+public final class WatchEvent<T> {
+
+  private final String type;
+  private final T object;
+
+  public WatchEvent(String type, T object) {
+    this.type = type;
+    this.object = object;
+  }
+
+  /*
+   * Returns the type of the event: ADDED, MODIFIED, DELETED, BOOKMARK or ERROR.
+   */
+  public String getType() {
+    return type;
+  }
+
+  /*
+   * Returns the object, which is null for ERROR events.
+   */
+  public T getObject() {
+    return object;
+  }
+
+}
+`,
+	"Watcher": `package {{.Package}};
+
+/*
+ * Watcher receives the events of a watch.
+ */
+public interface Watcher<T> {
+
+  void onEvent(WatchEvent<T> event);
+
+  /*
+   * Called once the watch ends, with the cause if it failed.
+   */
+  default void onClose(Throwable cause) {
+  }
+
+}
+`,
+	"Watch": `package {{.Package}};
+
+/*
+ * Watch is a running watch, which ends when closed.
+ */
+public final class Watch implements AutoCloseable {
+
+  private final java.util.concurrent.CompletableFuture<?> response;
+  private final java.util.concurrent.atomic.AtomicReference<java.util.stream.Stream<String>> lines = new java.util.concurrent.atomic.AtomicReference<>();
+  private volatile boolean closed;
+
+  Watch(java.util.concurrent.CompletableFuture<?> response) {
+    this.response = response;
+  }
+
+  void streaming(java.util.stream.Stream<String> lines) {
+    this.lines.set(lines);
+    if (closed) {
+      lines.close();
+    }
+  }
+
+  public boolean isClosed() {
+    return closed;
+  }
+
+  @Override
+  public void close() {
+    closed = true;
+    response.cancel(true);
+    java.util.stream.Stream<String> lines = this.lines.get();
+    if (lines != null) {
+      lines.close();
+    }
+  }
+
+}
+`,
+	"HttpResourceClient": `package {{.Package}};
+
+/*
+ * HttpResourceClient implements the requests of the generated clients for a
+ * resource, using java.net.http. Namespaces are null for cluster scoped
+ * resources and requests across all namespaces.
+ */
+public abstract class HttpResourceClient<T> {
+
+  private final ClientConfig config;
+  private final String apiPath;
+  private final String resource;
+  private final Class<T> type;
+
+  protected HttpResourceClient(ClientConfig config, String apiPath, String resource, Class<T> type) {
+    this.config = config;
+    this.apiPath = apiPath;
+    this.resource = resource;
+    this.type = type;
+  }
+
+  protected <L> L doList(String namespace, ListOptions options, Class<L> listType) {
+    return send(request(uri(namespace, null, null, options, false)).GET(), listType);
+  }
+
+  protected T doGet(String namespace, String name, String subresource) {
+    return send(request(uri(namespace, name, subresource, null, false)).GET(), type);
+  }
+
+  protected T doCreate(String namespace, T object) {
+    return send(request(uri(namespace, null, null, null, false)).POST(body(object)).header("Content-Type", "application/json"), type);
+  }
+
+  protected T doUpdate(String namespace, String name, String subresource, T object) {
+    return send(request(uri(namespace, name, subresource, null, false)).PUT(body(object)).header("Content-Type", "application/json"), type);
+  }
+
+  protected T doPatch(String namespace, String name, String subresource, PatchType patchType, String patch) {
+    java.net.http.HttpRequest.Builder request = request(uri(namespace, name, subresource, null, false))
+        .method("PATCH", java.net.http.HttpRequest.BodyPublishers.ofString(patch))
+        .header("Content-Type", patchType.getContentType());
+    return send(request, type);
+  }
+
+  /*
+   * Deletes the named object, or the objects matching the options if name
+   * is null.
+   */
+  protected void doDelete(String namespace, String name, ListOptions options) {
+    send(request(uri(namespace, name, null, options, false)).DELETE(), null);
+  }
+
+  protected Watch doWatch(String namespace, ListOptions options, Watcher<T> watcher) {
+    java.net.http.HttpRequest request = request(uri(namespace, null, null, options, true)).GET().build();
+    java.util.concurrent.CompletableFuture<java.net.http.HttpResponse<java.util.stream.Stream<String>>> response =
+        config.getHttpClient().sendAsync(request, java.net.http.HttpResponse.BodyHandlers.ofLines());
+    Watch watch = new Watch(response);
+    response.whenCompleteAsync((r, e) -> {
+      if (e != null) {
+        watcher.onClose(watch.isClosed() ? null : new KubernetesClientException("watch of " + resource + " failed", e));
+        return;
+      }
+      try (java.util.stream.Stream<String> lines = r.body()) {
+        if (r.statusCode() / 100 != 2) {
+          watcher.onClose(new KubernetesClientException(r.statusCode(), "watch of " + resource + " failed with status " + r.statusCode()));
+          return;
+        }
+        watch.streaming(lines);
+        lines.forEach(line -> watcher.onEvent(event(line)));
+        watcher.onClose(null);
+      } catch (RuntimeException ex) {
+        watcher.onClose(watch.isClosed() ? null : ex);
+      }
+    });
+    return watch;
+  }
+
+  private WatchEvent<T> event(String line) {
+    try {
+      com.fasterxml.jackson.databind.JsonNode event = config.getMapper().readTree(line);
+      String eventType = event.path("type").asText();
+      com.fasterxml.jackson.databind.JsonNode object = event.get("object");
+      if (object == null || "ERROR".equals(eventType)) {
+        return new WatchEvent<>(eventType, null);
+      }
+      return new WatchEvent<>(eventType, config.getMapper().treeToValue(object, type));
+    } catch (java.io.IOException e) {
+      throw new KubernetesClientException("failed to read watch event of " + resource, e);
+    }
+  }
+
+  private java.net.URI uri(String namespace, String name, String subresource, ListOptions options, boolean watch) {
+    StringBuilder path = new StringBuilder(apiPath);
+    if (namespace != null) {
+      path.append("/namespaces/").append(encode(namespace));
+    }
+    path.append('/').append(resource);
+    if (name != null) {
+      path.append('/').append(encode(name));
+    }
+    if (subresource != null) {
+      path.append('/').append(subresource);
+    }
+
+    java.util.StringJoiner query = new java.util.StringJoiner("&", "?", "").setEmptyValue("");
+    if (options != null) {
+      options.toQuery().forEach((k, v) -> query.add(k + "=" + encode(v)));
+    }
+    if (watch) {
+      query.add("watch=true");
+    }
+    String server = config.getServer().toString().replaceAll("/+$", "");
+    return java.net.URI.create(server + path + query);
+  }
+
+  private static String encode(String s) {
+    return java.net.URLEncoder.encode(s, java.nio.charset.StandardCharsets.UTF_8).replace("+", "%20");
+  }
+
+  private java.net.http.HttpRequest.Builder request(java.net.URI uri) {
+    java.net.http.HttpRequest.Builder request = java.net.http.HttpRequest.newBuilder(uri).header("Accept", "application/json");
+    config.getBearerToken().ifPresent(token -> request.header("Authorization", "Bearer " + token));
+    return request;
+  }
+
+  private java.net.http.HttpRequest.BodyPublisher body(T object) {
+    try {
+      return java.net.http.HttpRequest.BodyPublishers.ofByteArray(config.getMapper().writeValueAsBytes(object));
+    } catch (com.fasterxml.jackson.core.JsonProcessingException e) {
+      throw new KubernetesClientException("failed to write " + resource, e);
+    }
+  }
+
+  private <R> R send(java.net.http.HttpRequest.Builder request, Class<R> responseType) {
+    java.net.http.HttpResponse<String> response;
+    try {
+      response = config.getHttpClient().send(request.build(), java.net.http.HttpResponse.BodyHandlers.ofString());
+    } catch (java.io.IOException e) {
+      throw new KubernetesClientException("request for " + resource + " failed", e);
+    } catch (InterruptedException e) {
+      Thread.currentThread().interrupt();
+      throw new KubernetesClientException("request for " + resource + " was interrupted", e);
+    }
+
+    if (response.statusCode() / 100 != 2) {
+      throw new KubernetesClientException(response.statusCode(), "request for " + resource + " failed with status " + response.statusCode() + ": " + response.body());
+    }
+    if (responseType == null) {
+      return null;
+    }
+    try {
+      return config.getMapper().readValue(response.body(), responseType);
+    } catch (java.io.IOException e) {
+      throw new KubernetesClientException("failed to read " + resource, e);
+    }
+  }
+
+}
+`,
+}
+
+var (
+	clientInterfaceTemplate      = template.Must(template.New("clientInterface").Parse(clientInterfaceTemplateText))
+	clientImplementationTemplate = template.Must(template.New("clientImplementation").Parse(clientImplementationTemplateText))
+	clientSupportTemplates       = map[string]*template.Template{}
+)
+
+func init() {
+	for name, text := range clientSupportTemplateTexts {
+		clientSupportTemplates[name] = template.Must(template.New(name).Parse(text))
+	}
+}
+
+// client is the Java client of a client-enabled kind.
+type client struct {
+	Package string
+	// SupportPackage holds the classes shared by all clients.
+	SupportPackage string
+	ClassName      string
+	TypeClass      string
+	ListClass      string
+	APIVersion     string
+	APIPath        string
+	Resource       string
+	Namespaced     bool
+	// Status is set if the kind has a status subresource.
+	Status bool
+	Verbs  map[string]bool
+}
+
+// newClient returns the client for typ, generated as a class of javaPkg, or
+// nil if it has no client.
+func (g *immutablesGenerator) newClient(pkg loader.Package, javaPkg string, typ loader.Type) *client {
+	markers := typ.Markers.Client()
+	if !typ.GenerateClient || typ.Version == "" {
+		return nil
+	}
+
+	c := &client{
+		Package:        clientPackage(g.config.JavaRootPackage, g.config.JavaRootOpenShiftPackage, javaPkg),
+		SupportPackage: g.config.JavaRootPackage + ".client",
+		ClassName:      typ.Name,
+		TypeClass:      javaPkg + "." + typ.Name,
+		APIVersion:     typ.APIVersion(),
+		APIPath:        "/apis/" + typ.APIVersion(),
+		Resource:       typ.Resource(),
+		Namespaced:     typ.Namespaced,
+		Verbs:          map[string]bool{},
+	}
+	if typ.Group == "" {
+		c.APIPath = "/api/" + typ.Version
+	}
+	for _, fld := range typ.Fields {
+		if fld.JSONProperty == "status" {
+			c.Status = !markers.NoStatus
+		}
+	}
+	for _, verb := range clientVerbs {
+		c.Verbs[verb] = markers.HasVerb(verb)
+	}
+	c.Verbs["updateStatus"] = c.Verbs["updateStatus"] && c.Status
+
+	// Lists are read as the list kind of the type, without which there is
+	// nothing to read them as.
+	if _, ok := g.known[loader.StripVendor(pkg.Path)+"."+typ.Name+"List"]; ok {
+		c.ListClass = javaPkg + "." + typ.Name + "List"
+	} else {
+		c.Verbs["list"] = false
+	}
+	return c
+}
+
+// clientPackage returns the package of the clients for the kinds of javaPkg,
+// which mirrors it below the client package of its root package.
+func clientPackage(rootPackage, openshiftRootPackage, javaPkg string) string {
+	for _, root := range []string{rootPackage, openshiftRootPackage} {
+		if strings.HasPrefix(javaPkg, root+".") {
+			return root + ".client." + strings.TrimPrefix(javaPkg, root+".")
+		}
+	}
+	return javaPkg + ".client"
+}
+
+// writeClients writes the clients of the client-enabled kinds, along with the
+// classes they share, to the client package of the all module. Clients are
+// in a package per API version, as kinds of different versions share names.
+func (g *immutablesGenerator) writeClients(clients []*client) error {
+	if len(clients) == 0 {
+		return nil
+	}
+
+	supportPackage := g.config.JavaRootPackage + ".client"
+	supportDir := javaPackageToDir("all", supportPackage)
+	names := make([]string, 0, len(clientSupportTemplates))
+	for name := range clientSupportTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := g.writeClientClass(supportDir, name, clientSupportTemplates[name], struct{ Package string }{supportPackage}); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range clients {
+		dir := javaPackageToDir("all", c.Package)
+		if err := g.writeClientClass(dir, c.ClassName+"Client", clientInterfaceTemplate, c); err != nil {
+			return err
+		}
+		if err := g.writeClientClass(dir, "Http"+c.ClassName+"Client", clientImplementationTemplate, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *immutablesGenerator) writeClientClass(dir, className string, tmpl *template.Template, data interface{}) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return errors.Wrapf(err, "failed to render %s", className)
+	}
+	contents := g.config.WithHeader(generator.JavaComment, "", "", buf.Bytes())
+	return g.config.Output.WriteFile(path.Join(dir, className+".java"), contents)
+}
@@ -1,13 +1,15 @@
 package immutables
 
 import (
+	"bytes"
 	"fmt"
+	"go/token"
+	"go/types"
 	"io"
-	"io/ioutil"
 	"os"
 	"path"
-	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"text/template"
 	"unicode"
@@ -19,6 +21,7 @@ import (
 
 	"github.com/jimmidyson/kube-client-gen/pkg/generator"
 	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
 )
 
 const immutableTemplateText = `package {{.JavaPackage}};
@@ -32,17 +35,17 @@ const immutableTemplateText = `package {{.JavaPackage}};
 @com.fasterxml.jackson.databind.annotation.JsonSerialize(as = Immutable{{.ClassName}}.class)
 @com.fasterxml.jackson.databind.annotation.JsonDeserialize(as = Immutable{{.ClassName}}.class){{if .GenerateClient}}
 @io.fabric8.kubernetes.types.common.GenerateClient(namespaced = {{.Namespaced}}){{end}}
-public abstract class {{.ClassName}}{{if .HasMetadata}} implements io.fabric8.kubernetes.types.api.v1.HasMetadata{{end}} {{"{"}}{{$className := .ClassName}}{{$goPackage := .GoPackage}}{{range .Fields}}
+public abstract class {{.ClassName}}{{if .HasMetadata}} implements {{.MetadataInterface}}{{end}} {{"{"}}{{$className := .ClassName}}{{$kind := .Kind}}{{$apiVersion := .APIVersion}}{{range .Fields}}
 {{if .Doc}}
-{{comment .Doc "  "}}{{end}}{{if eq .Name ""}}
+{{comment .Doc "  "}}{{end}}{{if typeName .Type | ne "TypeMeta"}}{{if eq .Name ""}}
   @com.fasterxml.jackson.annotation.JsonUnwrapped{{else}}
-  @com.fasterxml.jackson.annotation.JsonProperty("{{.Name}}"){{end}}{{if typeName .Type | ne "TypeMeta"}}{{if eq .Type "java.util.Date"}}
-  @com.fasterxml.jackson.databind.annotation.JsonDeserialize(using = io.fabric8.kubernetes.types.common.RFC3339DateDeserializer.class)
-  @com.fasterxml.jackson.annotation.JsonFormat(shape = com.fasterxml.jackson.annotation.JsonFormat.Shape.STRING, pattern = io.fabric8.kubernetes.types.common.RFC3339DateDeserializer.RFC3339_FORMAT, timezone="UTC"){{end}}
-  {{$optional := isOptional $className (typeName .Type) .Optional $fieldsLen}}{{validationConstraints $className .Name $optional}}public abstract {{if $optional}}java.util.Optional<{{end}}{{.Type}}{{if $optional}}>{{end}} {{if eq .Type "Boolean"}}is{{else}}get{{end}}{{if .Name}}{{upperFirst .Name | sanitize}}{{else}}{{typeName .Type | upperFirst | sanitize}}{{end}}();{{else}}
+  @com.fasterxml.jackson.annotation.JsonProperty("{{.Name}}"){{end}}{{range .Annotations}}
+  {{.}}{{end}}
+  {{$optional := isOptional $className (typeName .Type) .Optional $fieldsLen}}{{validationConstraints .Type .Validation}}public abstract {{if $optional}}java.util.Optional<{{end}}{{.Type}}{{if $optional}}>{{end}} {{if eq .Type "Boolean"}}is{{else}}get{{end}}{{if .Name}}{{upperFirst .Name | sanitize}}{{else}}{{typeName .Type | upperFirst | sanitize}}{{end}}();{{else if $kind}}
+  @com.fasterxml.jackson.annotation.JsonUnwrapped
   @org.immutables.value.Value.Derived
   public {{.Type}} get{{typeName .Type}}() {
-    return new {{.Type}}.Builder().kind("{{$className}}").apiVersion("{{apiVersion $goPackage}}").build();
+    return new {{.Type}}.Builder().kind("{{$kind}}").apiVersion("{{$apiVersion}}").build();
   }
 
   @com.fasterxml.jackson.annotation.JsonIgnore
@@ -55,13 +58,57 @@ public abstract class {{.ClassName}}{{if .HasMetadata}} implements io.fabric8.ku
   @org.immutables.value.Value.Derived
   public String getKind() {
     return getTypeMeta().getKind();
-  }{{end}}{{end}}
+  }{{else}}
+  @com.fasterxml.jackson.annotation.JsonProperty("apiVersion")
+  public abstract java.util.Optional<String> getApiVersion();
+
+  @com.fasterxml.jackson.annotation.JsonProperty("kind")
+  public abstract java.util.Optional<String> getKind();{{end}}{{end}}
 
   public static class Builder extends Immutable{{.ClassName}}.Builder {}
 
 }
 `
 
+const enumTemplateText = `package {{.JavaPackage}};
+{{if .Doc}}
+{{comment .Doc ""}}{{end}}
+public enum {{.ClassName}} {{"{"}}{{$valueType := .ValueType}}{{range $v := .Values}}
+{{if $v.Doc}}
+{{comment $v.Doc "  "}}{{end}}
+  {{constantName $v.Name}}({{javaLiteral $valueType $v.Value}}),{{end}}
+
+  /*
+   * {{.Unknown}} is read for values this version does not know, such as
+   * those added by newer API servers, which are lost on writing.
+   */
+  @com.fasterxml.jackson.annotation.JsonEnumDefaultValue
+  {{.Unknown}}(null);
+
+  private final {{.ValueType}} value;
+
+  {{.ClassName}}({{.ValueType}} value) {
+    this.value = value;
+  }
+
+  @com.fasterxml.jackson.annotation.JsonValue
+  public {{.ValueType}} getValue() {
+    return value;
+  }
+
+  @com.fasterxml.jackson.annotation.JsonCreator
+  public static {{.ClassName}} fromValue({{.ValueType}} value) {
+    for ({{.ClassName}} v : values()) {
+      if (v.value != null && v.value.equals(value)) {
+        return v;
+      }
+    }
+    return {{.Unknown}};
+  }
+
+}
+`
+
 const modulePomTemplateText = `<?xml version="1.0" encoding="UTF-8"?>
 <project xmlns="http://maven.apache.org/POM/4.0.0"
          xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
@@ -89,15 +136,19 @@ const modulePomTemplateText = `<?xml version="1.0" encoding="UTF-8"?>
 
 var startOfLineRegexp = regexp.MustCompile(`(?m:^)`)
 
+func isNotLastField(currentIndex, numFields int) bool {
+	return currentIndex < (numFields - 1)
+}
+
+func comment(doc string, indent string) string {
+	return indent + "/*\n" + startOfLineRegexp.ReplaceAllString(doc, indent+" * ") + "\n" + indent + " */"
+}
+
 var immutableTemplate = template.Must(template.New("immutable").
 	Funcs(
 		template.FuncMap{
-			"isNotLastField": func(currentIndex, numFields int) bool {
-				return currentIndex < (numFields - 1)
-			},
-			"comment": func(doc string, indent string) string {
-				return indent + "/*\n" + startOfLineRegexp.ReplaceAllString(doc, indent+" * ") + "\n" + indent + " */"
-			},
+			"isNotLastField": isNotLastField,
+			"comment":        comment,
 			"typeName": func(s string) string {
 				lastDotIndex := strings.LastIndex(s, ".")
 				if lastDotIndex >= 0 {
@@ -119,14 +170,6 @@ var immutableTemplate = template.Must(template.New("immutable").
 				r, n := utf8.DecodeRuneInString(s)
 				return string(unicode.ToUpper(r)) + s[n:]
 			},
-			"apiVersion": func(s string) string {
-				apiVersion := path.Base(s)
-				apiGroup := path.Base(strings.TrimSuffix(s, apiVersion))
-				if apiGroup != "api" {
-					apiVersion = apiGroup + "/" + apiVersion
-				}
-				return apiVersion
-			},
 			"sanitize": func(s string) string {
 				res := ""
 				splitRes := strings.Split(s, ".")
@@ -144,39 +187,21 @@ var immutableTemplate = template.Must(template.New("immutable").
 			"isOptional": func(className, fieldType string, optional bool, numFields int) bool {
 				return className != "TypeMeta" && fieldType != "ObjectMeta" && optional && numFields > 1
 			},
-			"validationConstraints": func(className, fieldName string, optional bool) string {
-				switch className {
-				case "ObjectMeta":
-					switch fieldName {
-					case "namespace":
-						return `@javax.validation.Valid
-	@javax.validation.constraints.Size(max = 253)
-	@javax.validation.constraints.Pattern(regexp = "^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$")
-  `
-					}
-				case "EnvVar":
-					switch fieldName {
-					case "name":
-						return `@javax.validation.Valid
-	@javax.validation.constraints.Pattern(regexp = "^[A-Za-z_][A-Za-z0-9_]*$")
-  `
-					}
-				case "Container", "Volume", "ContainerPort", "ContainerStatus", "ServicePort", "EndpointPort":
-					switch fieldName {
-					case "name":
-						return `@javax.validation.Valid
-	@javax.validation.constraints.Size(max = 63)
-	@javax.validation.constraints.Pattern(regexp = "^[a-z0-9]([-a-z0-9]*[a-z0-9])?$")
-  `
-					}
-				}
-				return `@javax.validation.Valid
-	`
-			},
+			"validationConstraints": validationConstraints,
 		},
 	).
 	Parse(immutableTemplateText))
 
+var enumTemplate = template.Must(template.New("enum").
+	Funcs(
+		template.FuncMap{
+			"comment":      comment,
+			"constantName": constantName,
+			"javaLiteral":  javaLiteral,
+		},
+	).
+	Parse(enumTemplateText))
+
 var modulePomTemplate = template.Must(template.New("modulePOM").Parse(modulePomTemplateText))
 
 func New(c Config) generator.Generator {
@@ -192,10 +217,16 @@ type Config struct {
 	JavaRootPackage          string
 	JavaRootOpenShiftPackage string
 	StyleClass               string
+	// Parent is the parent POM of the generated module POMs.
+	Parent POM
 }
 
 type immutablesGenerator struct {
-	config Config
+	config   Config
+	enums    map[string]struct{}
+	known    map[string]struct{}
+	mappings typemap.Table
+	diags    generator.Diagnostics
 }
 
 var _ generator.Generator = &immutablesGenerator{}
@@ -203,53 +234,81 @@ var _ generator.Generator = &immutablesGenerator{}
 func (g *immutablesGenerator) Generate(pkgs []loader.Package) error {
 	g.config.Logger.Debug("generating")
 
-	p, err := parsePOM(filepath.Join(g.config.OutputDirectory, "pom.xml"))
-	if err != nil {
-		return errors.Wrap(err, "failed to parse parent POM")
+	g.mappings = typemap.Merge(defaultTypeMappings(g.config.JavaRootPackage), g.config.TypeMappings["immutables"])
+	g.enums = map[string]struct{}{}
+	g.known = map[string]struct{}{}
+	g.diags = generator.Diagnostics{}
+	for _, pkg := range pkgs {
+		for _, enum := range pkg.Enums {
+			g.enums[enum.Package+"."+enum.Name] = struct{}{}
+		}
+		for _, typ := range pkg.Types {
+			g.known[loader.StripVendor(typ.Package)+"."+typ.Name] = struct{}{}
+		}
+	}
+
+	p := g.config.Parent
+	if p.GroupID == "" || p.ArtifactID == "" || p.Version == "" {
+		return errors.New("no parent POM coordinates given")
 	}
 
-	var allDependencies []string
+	// classes holds the Go type each class file was generated from, to
+	// report types that map to the same class.
+	classes := map[string]string{}
+
+	var allDependencies, registries []string
+	var clients []*client
 	for _, pkg := range pkgs {
 		dependencies := []string{"common"}
 
 		depMap := map[string]struct{}{}
 		javaPkg, moduleName, platform := javaPackage(g.config.JavaRootPackage, g.config.JavaRootOpenShiftPackage, pkg.Path)
+		if javaPkg == "" {
+			pos := token.Position{}
+			if len(pkg.Types) > 0 {
+				pos = pkg.Types[0].Position
+			}
+			g.diags.Addf(pos, "package %s does not map to a Java package", pkg.Path)
+			continue
+		}
 		moduleName = platform + "-" + moduleName
-		pkgDir := javaPackageToDir(g.config.OutputDirectory, moduleName, javaPkg)
+		pkgDir := javaPackageToDir(moduleName, javaPkg)
 		g.config.Logger.Debug("generating for package", "package", pkg.Path, "javaPackage", javaPkg, "dir", pkgDir)
 
-		if err := os.MkdirAll(pkgDir, 0700); err != nil && os.IsExist(err) {
-			return errors.Wrapf(err, "failed to create directory %s", pkgDir)
-		}
-
-		if err := g.writePackageJava(pkgDir, javaPkg, g.config.StyleClass, pkg.Doc); err != nil {
+		if err := g.writePackageJava(pkg, pkgDir, javaPkg, g.config.StyleClass); err != nil {
 			return errors.Wrap(err, "failed to write package-info.java file")
 		}
 
+		var kinds []kind
 		for _, typ := range pkg.Types {
-			if typ.Name == "Time" {
+			if g.mappings.Maps(pkg.Path, typ.Name) {
+				g.config.Logger.Debug("ignoring mapped type", "type", typ.Name)
 				continue
 			}
 
-			fp := filepath.Join(pkgDir, typ.Name+".java")
-
-			if !g.config.Force {
-				_, err := os.Stat(fp)
-				if err == nil {
-					return errors.Errorf("target file %s already exists", fp)
-				}
-				if !os.IsNotExist(err) {
-					return errors.Errorf("failed to check if target file %s exists: %v", fp, err)
-				}
+			name := path.Join(pkgDir, typ.Name+".java")
+			if other, ok := classes[name]; ok {
+				g.diags.Addf(typ.Position, "type %s.%s maps to class %s.%s, as does %s", pkg.Path, typ.Name, javaPkg, typ.Name, other)
+				continue
 			}
+			classes[name] = pkg.Path + "." + typ.Name
 
-			f, err := os.OpenFile(fp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+			var buf bytes.Buffer
+			d, err := g.write(javaPkg, typ, &buf)
 			if err != nil {
-				return errors.Wrapf(err, "failed to open file %s to write", fp)
+				return errors.Wrapf(err, "failed to render class %s.%s", javaPkg, typ.Name)
 			}
-
-			if err := g.write(javaPkg, typ, f); err != nil {
-				return errors.Wrapf(err, "failed to write file %s", fp)
+			if d != nil {
+				contents := g.config.WithHeader(generator.JavaComment, pkg.Path, pkg.APIVersion(), buf.Bytes())
+				if err := g.config.Output.WriteFile(name, contents); err != nil {
+					return err
+				}
+				if d.HasMetadata {
+					kinds = append(kinds, kind{Kind: d.Kind, ClassName: d.ClassName})
+				}
+				if c := g.newClient(pkg, javaPkg, typ); c != nil {
+					clients = append(clients, c)
+				}
 			}
 
 			for _, fld := range typ.Fields {
@@ -262,55 +321,123 @@ func (g *immutablesGenerator) Generate(pkgs []loader.Package) error {
 			}
 		}
 
+		for _, enum := range pkg.Enums {
+			name := path.Join(pkgDir, enum.Name+".java")
+			if other, ok := classes[name]; ok {
+				g.diags.Addf(token.Position{}, "enum %s.%s maps to class %s.%s, as does %s", pkg.Path, enum.Name, javaPkg, enum.Name, other)
+				continue
+			}
+			classes[name] = pkg.Path + "." + enum.Name
+
+			basic, ok := enum.Type.(*types.Basic)
+			if !ok {
+				g.diags.Addf(token.Position{}, "enum %s.%s: unsupported type %s", pkg.Path, enum.Name, enum.Type)
+				continue
+			}
+
+			var buf bytes.Buffer
+			if err := g.writeEnum(javaPkg, enum, basic, &buf); err != nil {
+				return errors.Wrapf(err, "failed to render enum %s.%s", javaPkg, enum.Name)
+			}
+			contents := g.config.WithHeader(generator.JavaComment, pkg.Path, pkg.APIVersion(), buf.Bytes())
+			if err := g.config.Output.WriteFile(name, contents); err != nil {
+				return err
+			}
+		}
+
+		if len(kinds) > 0 {
+			name := path.Join(pkgDir, kindsClass+".java")
+			if other, ok := classes[name]; ok {
+				g.diags.Addf(token.Position{}, "kind registry %s.%s has the name of the class of %s", javaPkg, kindsClass, other)
+			} else {
+				if err := g.writeKinds(pkg, pkgDir, javaPkg, kinds); err != nil {
+					return err
+				}
+				registries = append(registries, javaPkg+"."+kindsClass)
+			}
+		}
+
+		moduleDependencies := make([]string, 0, len(depMap))
 		for k := range depMap {
-			dependencies = append(dependencies, k)
+			moduleDependencies = append(moduleDependencies, k)
 		}
+		sort.Strings(moduleDependencies)
+		dependencies = append(dependencies, moduleDependencies...)
 
-		if err := g.writeModulePOM(filepath.Join(g.config.OutputDirectory, moduleName), p.GroupID, moduleName, p.ArtifactID, p.Version, dependencies); err != nil {
+		if err := g.writeModulePOM(pkg.Path, pkg.APIVersion(), moduleName, p.GroupID, moduleName, p.ArtifactID, p.Version, dependencies); err != nil {
 			return errors.Wrap(err, "failed to write module POM file")
 		}
 
 		allDependencies = append(allDependencies, moduleName)
 	}
 
-	if err := g.writeModulePOM(filepath.Join(g.config.OutputDirectory, "all"), p.GroupID, "all", p.ArtifactID, p.Version, allDependencies); err != nil {
+	if err := g.writeModulePOM("", "", "all", p.GroupID, "all", p.ArtifactID, p.Version, allDependencies); err != nil {
 		return errors.Wrap(err, "failed to write module POM file")
 	}
+	if len(registries) > 0 {
+		if err := g.writeJacksonModule(registries); err != nil {
+			return errors.Wrap(err, "failed to write Jackson module")
+		}
+	}
+	if err := g.writeClients(clients); err != nil {
+		return errors.Wrap(err, "failed to write clients")
+	}
 
-	return nil
+	return g.diags.Err()
 }
 
 type field struct {
-	Type     string
-	Name     string
-	Doc      string
-	Optional bool
+	Type       string
+	Name       string
+	Doc        string
+	Optional   bool
+	Validation loader.Validation
+	// Annotations are the serialization annotations from the field type's
+	// mapping.
+	Annotations []string
 }
 
 type data struct {
-	JavaPackage    string
-	GoPackage      string
-	ClassName      string
-	HasMetadata    bool
-	Doc            string
-	GenerateClient bool
-	Namespaced     bool
-	Fields         []field
+	JavaPackage string
+	ClassName   string
+	Kind        string
+	APIVersion  string
+	HasMetadata bool
+	// MetadataInterface is the interface implemented by classes with
+	// metadata.
+	MetadataInterface string
+	Doc               string
+	GenerateClient    bool
+	Namespaced        bool
+	Fields            []field
 }
 
-func (g *immutablesGenerator) write(pkg string, typ loader.Type, f io.WriteCloser) error {
-	defer func() {
-		_ = f.Close()
-	}()
-
+// write renders the class for typ, returning what it rendered, or recording
+// any problems with its fields and returning nil instead of rendering if there
+// are any.
+func (g *immutablesGenerator) write(pkg string, typ loader.Type, w io.Writer) (*data, error) {
 	fields := make([]field, 0, len(typ.Fields))
+	problems := g.diags.Len()
+	properties := map[string]string{}
 
 	hasMetadata := false
 	hasTypemeta := false
 	for _, fld := range typ.Fields {
-		javaType, err := javaType(g.config.JavaRootPackage, g.config.JavaRootOpenShiftPackage, fld.Type, fld.TypeName)
+		javaType, err := javaType(g.config.JavaRootPackage, g.config.JavaRootOpenShiftPackage, g.enums, g.mappings, fld.Type, fld.TypeName)
 		if err != nil {
-			return errors.Wrapf(err, "unhandled field type %s for field %s.%s.%s", pkg, typ.Name, fld.Type.String())
+			g.diags.Addf(fld.Position, "field %s.%s: %v", typ.Name, fld.Name, err)
+			continue
+		}
+		if missing, ok := missingReference(g.known, g.mappings, fld.Type); ok {
+			g.diags.Addf(fld.Position, "field %s.%s refers to %s, which is neither generated nor mapped", typ.Name, fld.Name, missing)
+			continue
+		}
+		if fld.JSONProperty != "" {
+			if other, ok := properties[fld.JSONProperty]; ok {
+				g.diags.Addf(fld.Position, "field %s.%s has JSON property %q, as does field %s", typ.Name, fld.Name, fld.JSONProperty, other)
+				continue
+			}
+			properties[fld.JSONProperty] = fld.Name
 		}
 
 		if fld.JSONProperty == "metadata" && fld.Type.String() == "k8s.io/kubernetes/pkg/api/v1.ObjectMeta" {
@@ -321,31 +448,79 @@ func (g *immutablesGenerator) write(pkg string, typ loader.Type, f io.WriteClose
 			hasTypemeta = true
 		}
 
-		fields = append(fields, field{javaType, fld.JSONProperty, fld.Doc, !fld.JSONRequired})
+		fldType := fld.Type
+		if ptr, ok := fldType.(*types.Pointer); ok {
+			fldType = ptr.Elem()
+		}
+		mapping, _ := g.mappings.Lookup(fldType)
+
+		validation := fld.Markers.Validation()
+		fields = append(fields, field{javaType, fld.JSONProperty, fld.Doc, !fld.JSONRequired && !validation.Required, validation, mapping.Annotations})
 	}
 
-	return immutableTemplate.Execute(f, data{
-		JavaPackage:    pkg,
-		GoPackage:      typ.Package,
-		ClassName:      typ.Name,
-		HasMetadata:    hasMetadata && hasTypemeta,
-		Doc:            typ.Doc,
-		GenerateClient: typ.GenerateClient,
-		Namespaced:     typ.Namespaced,
-		Fields:         fields,
+	if g.diags.Len() > problems {
+		return nil, nil
+	}
+
+	d := &data{
+		JavaPackage:       pkg,
+		ClassName:         typ.Name,
+		Kind:              typ.Kind,
+		APIVersion:        typ.APIVersion(),
+		HasMetadata:       hasMetadata && hasTypemeta && typ.Kind != "",
+		MetadataInterface: hasMetadataInterface(g.config.JavaRootPackage),
+		Doc:               typ.Doc,
+		GenerateClient:    typ.GenerateClient,
+		Namespaced:        typ.Namespaced,
+		Fields:            fields,
+	}
+	return d, immutableTemplate.Execute(w, d)
+}
+
+func (g *immutablesGenerator) writeEnum(pkg string, enum loader.Enum, basic *types.Basic, w io.Writer) error {
+	type params struct {
+		JavaPackage string
+		ClassName   string
+		Doc         string
+		ValueType   string
+		Values      []loader.EnumValue
+		// Unknown is the constant for unknown values, UNKNOWN unless a value
+		// has that name.
+		Unknown string
+	}
+
+	unknown := "UNKNOWN"
+	for taken := true; taken; {
+		taken = false
+		for _, v := range enum.Values {
+			if constantName(v.Name) == unknown {
+				unknown += "_VALUE"
+				taken = true
+			}
+		}
+	}
+
+	return enumTemplate.Execute(w, params{
+		JavaPackage: pkg,
+		ClassName:   enum.Name,
+		Doc:         enum.Doc,
+		ValueType:   javaTypeBasic(basic.Kind()),
+		Values:      enum.Values,
+		Unknown:     unknown,
 	})
 }
 
-func (g *immutablesGenerator) writePackageJava(pkgDir, javaPackage, styleClass, doc string) error {
-	pkgDoc := doc
+func (g *immutablesGenerator) writePackageJava(pkg loader.Package, pkgDir, javaPackage, styleClass string) error {
+	pkgDoc := pkg.Doc
 	if len(pkgDoc) > 0 {
 		pkgDoc = startOfLineRegexp.ReplaceAllString(pkgDoc, "// ") + "\n"
 	}
 	contents := []byte(fmt.Sprintf("%s@%s\npackage %s;\n", pkgDoc, styleClass, javaPackage))
-	return ioutil.WriteFile(filepath.Join(pkgDir, "package-info.java"), contents, 0644)
+	contents = g.config.WithHeader(generator.JavaComment, pkg.Path, pkg.APIVersion(), contents)
+	return g.config.Output.WriteFile(path.Join(pkgDir, "package-info.java"), contents)
 }
 
-func (g *immutablesGenerator) writeModulePOM(moduleDir, groupID, artifactID, parentArtifactID, version string, dependencies []string) error {
+func (g *immutablesGenerator) writeModulePOM(pkgPath, apiVersion, moduleDir, groupID, artifactID, parentArtifactID, version string, dependencies []string) error {
 	type params struct {
 		GroupID          string
 		ArtifactID       string
@@ -354,40 +529,50 @@ func (g *immutablesGenerator) writeModulePOM(moduleDir, groupID, artifactID, par
 		Dependencies     []string
 	}
 
-	err := os.Mkdir(moduleDir, 0755)
-	if err != nil && !os.IsExist(err) {
-		return errors.Wrap(err, "failed to create module dir")
-	}
-
-	f, err := os.Create(filepath.Join(moduleDir, "pom.xml"))
-	if err != nil {
-		return errors.Wrap(err, "failed to create module POM file")
-	}
-	defer func() { _ = f.Close() }() // #nosec
-
-	return modulePomTemplate.Execute(f, params{
+	var buf bytes.Buffer
+	err := modulePomTemplate.Execute(&buf, params{
 		GroupID:          groupID,
 		ArtifactID:       artifactID,
 		ParentArtifactID: parentArtifactID,
 		Version:          version,
 		Dependencies:     dependencies,
 	})
+	if err != nil {
+		return errors.Wrap(err, "failed to render module POM file")
+	}
+
+	contents := g.config.WithHeader(generator.XMLComment, pkgPath, apiVersion, buf.Bytes())
+	return g.config.Output.WriteFile(path.Join(moduleDir, "pom.xml"), contents)
 }
 
-type pom struct {
+// POM holds the Maven coordinates of a POM.
+type POM struct {
 	GroupID    string `xml:"groupId"`
 	ArtifactID string `xml:"artifactId"`
 	Version    string `xml:"version"`
 }
 
-func parsePOM(pomPath string) (*pom, error) {
+// ReadPOM reads the coordinates of the POM at pomPath.
+func ReadPOM(pomPath string) (POM, error) {
 	f, err := os.Open(pomPath)
 	if err != nil {
-		return nil, errors.Wrapf(err, "unable to open POM at %s", pomPath)
+		return POM{}, errors.Wrapf(err, "unable to open POM at %s", pomPath)
 	}
-	var p pom
+	defer f.Close()
+
+	var p POM
 	if err := xml.NewDecoder(f).Decode(&p); err != nil {
-		return nil, errors.Wrapf(err, "unable to parse POM at %s", pomPath)
+		return POM{}, errors.Wrapf(err, "unable to parse POM at %s", pomPath)
+	}
+	return p, nil
+}
+
+// ParsePOMCoordinates parses coordinates of the form
+// groupId:artifactId:version.
+func ParsePOMCoordinates(s string) (POM, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return POM{}, errors.Errorf("invalid POM coordinates %q, expected groupId:artifactId:version", s)
 	}
-	return &p, nil
+	return POM{GroupID: parts[0], ArtifactID: parts[1], Version: parts[2]}, nil
 }
@@ -0,0 +1,13 @@
+package immutables_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestImmutables(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Immutables Suite")
+}
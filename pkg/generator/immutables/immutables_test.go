@@ -0,0 +1,211 @@
+package immutables_test
+
+import (
+	"go/types"
+
+	"github.com/inconshreveable/log15"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	. "github.com/jimmidyson/kube-client-gen/pkg/generator/immutables"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+var _ = Describe("Immutables", func() {
+	var logger log15.Logger
+
+	BeforeEach(func() {
+		logger = log15.New()
+		logger.SetHandler(log15.DiscardHandler())
+	})
+
+	allPackages := []string{
+		"k8s.io/kubernetes/pkg/api/unversioned",
+		"k8s.io/kubernetes/pkg/api/v1",
+		"k8s.io/kubernetes/pkg/apis/apps/v1",
+	}
+
+	loadPackages := func(packages []string, opts ...loader.Option) []loader.Package {
+		pkgs, err := loader.New(packages, logger, append([]loader.Option{loader.WithDir("../testdata/module")}, opts...)...).Load()
+		Expect(err).NotTo(HaveOccurred())
+		return pkgs
+	}
+
+	load := func(opts ...loader.Option) []loader.Package {
+		return loadPackages(allPackages, opts...)
+	}
+
+	generate := func(pkgs []loader.Package) *generator.MemoryOutput {
+		out := generator.NewMemoryOutput()
+		err := New(Config{
+			Config:                   generator.Config{Logger: logger, Output: out},
+			JavaRootPackage:          "io.fabric8.kubernetes.types",
+			JavaRootOpenShiftPackage: "io.fabric8.openshift.types",
+			StyleClass:               "io.fabric8.kubernetes.types.common.ImmutablesStyle",
+			Parent:                   POM{GroupID: "io.fabric8", ArtifactID: "kubernetes-types", Version: "1.0.0"},
+		}).Generate(pkgs)
+		Expect(err).NotTo(HaveOccurred())
+		return out
+	}
+
+	javaFile := func(out *generator.MemoryOutput, name string) string {
+		fp := "kubernetes-api-v1/src/main/java/io/fabric8/kubernetes/types/api/v1/" + name
+		Expect(out.Files).To(HaveKey(fp))
+		return string(out.Files[fp])
+	}
+
+	It("writes module POMs with the configured parent", func() {
+		out := generate(load())
+
+		pom := string(out.Files["kubernetes-api-v1/pom.xml"])
+		Expect(pom).To(ContainSubstring("  <parent>\n    <groupId>io.fabric8</groupId>\n    <artifactId>kubernetes-types</artifactId>\n    <version>1.0.0</version>\n  </parent>\n"))
+		Expect(pom).To(ContainSubstring("<artifactId>kubernetes-api-v1</artifactId>"))
+		Expect(out.Files).To(HaveKey("all/pom.xml"))
+	})
+
+	It("does not generate mapped types, such as Time, whatever the excludes", func() {
+		out := generate(load(loader.WithExcludes("k8s.io/kubernetes/pkg/api/v1.NodeList")))
+		Expect(out.Files).To(HaveKey("kubernetes-api-unversioned/src/main/java/io/fabric8/kubernetes/types/api/unversioned/TypeMeta.java"))
+		Expect(out.Files).NotTo(HaveKey("kubernetes-api-unversioned/src/main/java/io/fabric8/kubernetes/types/api/unversioned/Time.java"))
+		Expect(out.Files).NotTo(HaveKey(HaveSuffix("/v1/NodeList.java")))
+		Expect(javaFile(out, "ObjectMeta.java")).To(ContainSubstring("java.util.Optional<java.util.Date> getCreationTimestamp()"))
+	})
+
+	It("requires the parent POM", func() {
+		err := New(Config{
+			Config:          generator.Config{Logger: logger, Output: generator.NewMemoryOutput()},
+			JavaRootPackage: "io.fabric8.kubernetes.types",
+		}).Generate(load())
+		Expect(err).To(MatchError("no parent POM coordinates given"))
+	})
+
+	It("generates a Java enum with a constant per value", func() {
+		phase := javaFile(generate(load()), "PodPhase.java")
+		Expect(phase).To(ContainSubstring("public enum PodPhase {"))
+		Expect(phase).To(ContainSubstring("  /*\n   * PodPending means the pod has been accepted but is not running yet.\n   */\n  POD_PENDING(\"Pending\"),"))
+		Expect(phase).To(ContainSubstring("public static PodPhase fromValue(String value) {"))
+	})
+
+	It("reads unknown enum values as an UNKNOWN constant", func() {
+		out := generate(load())
+
+		phase := javaFile(out, "PodPhase.java")
+		Expect(phase).To(ContainSubstring("  POD_RUNNING(\"Running\"),\n"))
+		Expect(phase).To(ContainSubstring("@com.fasterxml.jackson.annotation.JsonEnumDefaultValue\n  UNKNOWN(null);"))
+		Expect(phase).To(ContainSubstring("return UNKNOWN;"))
+		Expect(phase).NotTo(ContainSubstring("throw"))
+
+		status := javaFile(out, "ConditionStatus.java")
+		Expect(status).To(ContainSubstring("  UNKNOWN(\"Unknown\"),\n"))
+		Expect(status).To(ContainSubstring("  UNKNOWN_VALUE(null);"))
+		Expect(status).To(ContainSubstring("return UNKNOWN_VALUE;"))
+	})
+
+	It("types fields of enum types with the enum", func() {
+		Expect(javaFile(generate(load()), "PodStatus.java")).To(ContainSubstring("public abstract io.fabric8.kubernetes.types.api.v1.PodPhase getPhase();"))
+	})
+
+	DescribeTable("annotates fields with the constraints of their validation markers",
+		func(typ types.Type, markers string, constraints ...string) {
+			out := generate([]loader.Package{{
+				Path:    "k8s.io/kubernetes/pkg/api/v1",
+				Version: "v1",
+				Types: []loader.Type{
+					{Name: "Limits", Fields: []loader.Field{
+						{Name: "Value", JSONProperty: "value", Type: typ, Markers: loader.ParseMarkers(markers)},
+					}},
+				},
+			}})
+			limits := javaFile(out, "Limits.java")
+			for _, constraint := range constraints {
+				Expect(limits).To(ContainSubstring("  " + constraint + "\n"))
+			}
+		},
+		Entry("required", types.Typ[types.String], "+required",
+			"@javax.validation.constraints.NotNull"),
+		Entry("string length and pattern", types.Typ[types.String],
+			"+kubebuilder:validation:MinLength=1\n+kubebuilder:validation:MaxLength=63\n+kubebuilder:validation:Pattern=`^[a-z]+$`",
+			"@javax.validation.constraints.Size(min = 1, max = 63)",
+			`@javax.validation.constraints.Pattern(regexp = "^[a-z]+$")`),
+		Entry("list items", types.NewSlice(types.Typ[types.String]), "+kubebuilder:validation:MinItems=1",
+			"@javax.validation.constraints.Size(min = 1)"),
+		Entry("integral bounds", types.Typ[types.Int32],
+			"+kubebuilder:validation:Minimum=0\n+kubebuilder:validation:Maximum=10",
+			"@javax.validation.constraints.Min(0)",
+			"@javax.validation.constraints.Max(10)"),
+		Entry("exclusive bounds", types.Typ[types.Int64],
+			"+kubebuilder:validation:Minimum=0\n+kubebuilder:validation:ExclusiveMinimum=true\n+kubebuilder:validation:Maximum=10\n+kubebuilder:validation:ExclusiveMaximum=true",
+			`@javax.validation.constraints.DecimalMin(value = "0", inclusive = false)`,
+			`@javax.validation.constraints.DecimalMax(value = "10", inclusive = false)`),
+		Entry("non-integral bounds", types.Typ[types.Int32], "+kubebuilder:validation:Minimum=0.5",
+			`@javax.validation.constraints.DecimalMin(value = "0.5", inclusive = true)`),
+		Entry("floating point bounds", types.Typ[types.Float64],
+			"+kubebuilder:validation:Minimum=0\n+kubebuilder:validation:Maximum=1.5",
+			`@javax.validation.constraints.DecimalMin(value = "0", inclusive = true)`,
+			`@javax.validation.constraints.DecimalMax(value = "1.5", inclusive = true)`),
+	)
+
+	It("registers the kinds of each module and resolves them as HasMetadata", func() {
+		out := generate(load())
+
+		Expect(out.Files).NotTo(HaveKey(HaveSuffix("/HasMetadata.java")))
+		Expect(javaFile(out, "Pod.java")).To(ContainSubstring("public abstract class Pod implements io.fabric8.kubernetes.types.api.v1.HasMetadata {"))
+		Expect(javaFile(out, "PodSpec.java")).NotTo(ContainSubstring("HasMetadata"))
+
+		kinds := javaFile(out, "Kinds.java")
+		Expect(kinds).To(ContainSubstring(`public static final String API_VERSION = "v1";`))
+		Expect(kinds).To(ContainSubstring("    classes.put(\"Pod\", ImmutablePod.class);\n    classes.put(\"Node\", ImmutableNode.class);\n"))
+		Expect(kinds).NotTo(ContainSubstring("PodList"))
+		Expect(out.Files).NotTo(HaveKey(HaveSuffix("/unversioned/Kinds.java")))
+
+		jackson := "all/src/main/java/io/fabric8/kubernetes/types/jackson/"
+		Expect(string(out.Files[jackson+"KubernetesTypeIdResolver.java"])).To(ContainSubstring("    register(io.fabric8.kubernetes.types.api.v1.Kinds.API_VERSION, io.fabric8.kubernetes.types.api.v1.Kinds.classes());\n"))
+		Expect(string(out.Files[jackson+"KubernetesModule.java"])).To(ContainSubstring("addDeserializer(io.fabric8.kubernetes.types.api.v1.HasMetadata.class, new HasMetadataDeserializer());"))
+	})
+
+	It("keeps apiVersion and kind as properties of types without a registered kind", func() {
+		out := generate(load())
+
+		Expect(javaFile(out, "Pod.java")).To(ContainSubstring(`.kind("Pod").apiVersion("v1")`))
+
+		options := javaFile(out, "PodLogOptions.java")
+		Expect(options).To(ContainSubstring("public abstract class PodLogOptions {"))
+		Expect(options).To(ContainSubstring("  @com.fasterxml.jackson.annotation.JsonProperty(\"apiVersion\")\n  public abstract java.util.Optional<String> getApiVersion();\n"))
+		Expect(options).To(ContainSubstring("  @com.fasterxml.jackson.annotation.JsonProperty(\"kind\")\n  public abstract java.util.Optional<String> getKind();\n"))
+		Expect(options).NotTo(ContainSubstring("PodLogOptions\")"))
+		Expect(javaFile(out, "Kinds.java")).NotTo(ContainSubstring("PodLogOptions"))
+	})
+
+	It("does not write the Jackson module without kinds", func() {
+		out := generate(loadPackages([]string{"k8s.io/kubernetes/pkg/api/unversioned"}))
+		Expect(out.Files).To(HaveKey("all/pom.xml"))
+		Expect(out.Files).NotTo(HaveKey(HavePrefix("all/src/")))
+	})
+
+	It("generates REST clients for client kinds", func() {
+		out := generate(load())
+
+		clients := "all/src/main/java/io/fabric8/kubernetes/types/client/"
+		Expect(out.Files).To(HaveKey(clients + "ClientConfig.java"))
+		Expect(out.Files).To(HaveKey(clients + "HttpResourceClient.java"))
+		Expect(out.Files).NotTo(HaveKey(clients + "api/v1/PodSpecClient.java"))
+
+		pod := string(out.Files[clients+"api/v1/PodClient.java"])
+		Expect(pod).To(ContainSubstring("public interface PodClient {"))
+		Expect(pod).To(ContainSubstring("  io.fabric8.kubernetes.types.api.v1.PodList list(String namespace, ListOptions options);\n"))
+		Expect(pod).To(ContainSubstring("  io.fabric8.kubernetes.types.api.v1.Pod patchStatus(String namespace, String name, PatchType type, String patch);\n"))
+		Expect(string(out.Files[clients+"api/v1/HttpPodClient.java"])).To(ContainSubstring(`super(config, "/api/v1", "pods", io.fabric8.kubernetes.types.api.v1.Pod.class);`))
+
+		Expect(string(out.Files[clients+"api/v1/HttpEndpointsClient.java"])).To(ContainSubstring(`super(config, "/api/v1", "endpoints", io.fabric8.kubernetes.types.api.v1.Endpoints.class);`))
+
+		node := string(out.Files[clients+"api/v1/NodeClient.java"])
+		Expect(node).To(ContainSubstring("  io.fabric8.kubernetes.types.api.v1.Node get(String name);\n"))
+		Expect(node).NotTo(ContainSubstring("listAllNamespaces"))
+		Expect(node).NotTo(ContainSubstring("patchStatus"))
+
+		Expect(string(out.Files[clients+"apis/apps/v1/HttpDeploymentClient.java"])).To(ContainSubstring(`super(config, "/apis/apps/v1", "deployments", io.fabric8.kubernetes.types.apis.apps.v1.Deployment.class);`))
+	})
+})
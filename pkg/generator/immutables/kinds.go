@@ -0,0 +1,235 @@
+package immutables
+
+import (
+	"bytes"
+	"path"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+// kindsClass is the name of the kind registry class written to each module
+// with kinds.
+const kindsClass = "Kinds"
+
+const kindsTemplateText = `package {{.JavaPackage}};
+
+/*
+ * {{.ClassName}} maps the kinds of apiVersion {{.APIVersion}} to their classes.
+ */
+public final class {{.ClassName}} {
+
+  public static final String API_VERSION = "{{.APIVersion}}";
+
+  private static final java.util.Map<String, Class<?>> CLASSES;
+
+  static {
+    java.util.Map<String, Class<?>> classes = new java.util.LinkedHashMap<>();{{range .Kinds}}
+    classes.put("{{.Kind}}", Immutable{{.ClassName}}.class);{{end}}
+    CLASSES = java.util.Collections.unmodifiableMap(classes);
+  }
+
+  private {{.ClassName}}() {
+  }
+
+  /*
+   * Returns the classes of the kinds, keyed by kind.
+   */
+  public static java.util.Map<String, Class<?>> classes() {
+    return CLASSES;
+  }
+
+}
+`
+
+const typeIDResolverTemplateText = `package {{.JavaPackage}};
+
+/*
+ * KubernetesTypeIdResolver resolves the classes of Kubernetes objects from
+ * type ids of the form apiVersion/kind, such as extensions/v1beta1/Deployment.
+ */
+public class KubernetesTypeIdResolver extends com.fasterxml.jackson.databind.jsontype.impl.TypeIdResolverBase {
+
+  private static final java.util.Map<String, Class<?>> CLASSES = new java.util.HashMap<>();
+  private static final java.util.Map<Class<?>, String> IDS = new java.util.HashMap<>();
+
+  static {{"{"}}{{range .Registries}}
+    register({{.}}.API_VERSION, {{.}}.classes());{{end}}
+  }
+
+  private static void register(String apiVersion, java.util.Map<String, Class<?>> classes) {
+    for (java.util.Map.Entry<String, Class<?>> e : classes.entrySet()) {
+      String id = typeId(apiVersion, e.getKey());
+      CLASSES.put(id, e.getValue());
+      IDS.put(e.getValue(), id);
+    }
+  }
+
+  /*
+   * Returns the type id of the kind in apiVersion.
+   */
+  public static String typeId(String apiVersion, String kind) {
+    return apiVersion + "/" + kind;
+  }
+
+  /*
+   * Returns the class of the kind in apiVersion, or null if it is unknown.
+   */
+  public static Class<?> classOf(String apiVersion, String kind) {
+    return CLASSES.get(typeId(apiVersion, kind));
+  }
+
+  @Override
+  public String idFromValue(Object value) {
+    return idFromValueAndType(value, value.getClass());
+  }
+
+  @Override
+  public String idFromValueAndType(Object value, Class<?> suggestedType) {
+    return IDS.get(suggestedType);
+  }
+
+  @Override
+  public com.fasterxml.jackson.databind.JavaType typeFromId(com.fasterxml.jackson.databind.DatabindContext context, String id) {
+    Class<?> cls = CLASSES.get(id);
+    if (cls == null) {
+      return null;
+    }
+    return context.constructType(cls);
+  }
+
+  @Override
+  public String getDescForKnownTypeIds() {
+    return "apiVersion/kind of a generated kind";
+  }
+
+  @Override
+  public com.fasterxml.jackson.annotation.JsonTypeInfo.Id getMechanism() {
+    return com.fasterxml.jackson.annotation.JsonTypeInfo.Id.CUSTOM;
+  }
+
+}
+`
+
+const jacksonModuleTemplateText = `package {{.JavaPackage}};
+
+/*
+ * KubernetesModule lets Jackson read {{.HasMetadata}} values, such as the
+ * items of a List, as the classes of their apiVersion and kind.
+ */
+public class KubernetesModule extends com.fasterxml.jackson.databind.module.SimpleModule {
+
+  public KubernetesModule() {
+    super("KubernetesModule");
+    addDeserializer({{.HasMetadata}}.class, new HasMetadataDeserializer());
+  }
+
+  static class HasMetadataDeserializer extends com.fasterxml.jackson.databind.deser.std.StdDeserializer<{{.HasMetadata}}> {
+
+    private final KubernetesTypeIdResolver resolver = new KubernetesTypeIdResolver();
+
+    HasMetadataDeserializer() {
+      super({{.HasMetadata}}.class);
+    }
+
+    @Override
+    public {{.HasMetadata}} deserialize(com.fasterxml.jackson.core.JsonParser p, com.fasterxml.jackson.databind.DeserializationContext ctxt) throws java.io.IOException {
+      com.fasterxml.jackson.databind.JsonNode node = p.readValueAsTree();
+      com.fasterxml.jackson.databind.JsonNode apiVersion = node.get("apiVersion");
+      com.fasterxml.jackson.databind.JsonNode kind = node.get("kind");
+      if (apiVersion == null || kind == null) {
+        return ctxt.reportInputMismatch(this, "object without apiVersion and kind");
+      }
+
+      String id = KubernetesTypeIdResolver.typeId(apiVersion.asText(), kind.asText());
+      com.fasterxml.jackson.databind.JavaType type = resolver.typeFromId(ctxt, id);
+      if (type == null) {
+        return ctxt.reportInputMismatch(this, "unknown apiVersion and kind %s", id);
+      }
+
+      com.fasterxml.jackson.core.JsonParser objectParser = node.traverse(p.getCodec());
+      objectParser.nextToken();
+      return ctxt.readValue(objectParser, type);
+    }
+
+  }
+
+}
+`
+
+var (
+	kindsTemplate          = template.Must(template.New("kinds").Parse(kindsTemplateText))
+	typeIDResolverTemplate = template.Must(template.New("typeIdResolver").Parse(typeIDResolverTemplateText))
+	jacksonModuleTemplate  = template.Must(template.New("jacksonModule").Parse(jacksonModuleTemplateText))
+)
+
+// kind is a generated class that implements HasMetadata, which Jackson can
+// resolve from its apiVersion and kind.
+type kind struct {
+	Kind      string
+	ClassName string
+}
+
+// writeKinds writes the kind registry class of a module.
+func (g *immutablesGenerator) writeKinds(pkg loader.Package, pkgDir, javaPackage string, kinds []kind) error {
+	type params struct {
+		JavaPackage string
+		ClassName   string
+		APIVersion  string
+		Kinds       []kind
+	}
+
+	var buf bytes.Buffer
+	err := kindsTemplate.Execute(&buf, params{
+		JavaPackage: javaPackage,
+		ClassName:   kindsClass,
+		APIVersion:  pkg.APIVersion(),
+		Kinds:       kinds,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to render kind registry")
+	}
+
+	contents := g.config.WithHeader(generator.JavaComment, pkg.Path, pkg.APIVersion(), buf.Bytes())
+	return g.config.Output.WriteFile(path.Join(pkgDir, kindsClass+".java"), contents)
+}
+
+// writeJacksonModule writes the Jackson module that resolves HasMetadata
+// values by their apiVersion and kind, using the kind registries of all
+// modules, to the all module. There must be at least one registry.
+func (g *immutablesGenerator) writeJacksonModule(registries []string) error {
+	type params struct {
+		JavaPackage string
+		HasMetadata string
+		Registries  []string
+	}
+
+	javaPackage := g.config.JavaRootPackage + ".jackson"
+	p := params{
+		JavaPackage: javaPackage,
+		HasMetadata: hasMetadataInterface(g.config.JavaRootPackage),
+		Registries:  registries,
+	}
+	pkgDir := javaPackageToDir("all", javaPackage)
+
+	for _, f := range []struct {
+		className string
+		tmpl      *template.Template
+	}{
+		{"KubernetesTypeIdResolver", typeIDResolverTemplate},
+		{"KubernetesModule", jacksonModuleTemplate},
+	} {
+		var buf bytes.Buffer
+		if err := f.tmpl.Execute(&buf, p); err != nil {
+			return errors.Wrapf(err, "failed to render %s", f.className)
+		}
+		contents := g.config.WithHeader(generator.JavaComment, "", "", buf.Bytes())
+		if err := g.config.Output.WriteFile(path.Join(pkgDir, f.className+".java"), contents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,11 +1,18 @@
 package immutables
 
 import (
+	"fmt"
 	"go/types"
-	"path/filepath"
+	"math"
+	"path"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
 )
 
 func javaPackage(rootPackage, openshiftRootPackage, pkgPath string) (string, string, string) {
@@ -27,47 +34,73 @@ func javaPackage(rootPackage, openshiftRootPackage, pkgPath string) (string, str
 	return "", "", ""
 }
 
-func javaPackageToDir(rootDir, moduleName, javaPackage string) string {
-	return filepath.Join(
-		rootDir,
+func javaPackageToDir(moduleName, javaPackage string) string {
+	return path.Join(
 		moduleName, "src", "main", "java",
-		strings.Replace(javaPackage, ".", string(filepath.Separator), -1),
+		strings.Replace(javaPackage, ".", "/", -1),
 	)
 }
 
-func javaType(rootPackage, openshiftRootPackage string, typ types.Type, typeName string) (string, error) {
+// hasMetadataInterface returns the interface implemented by the classes of
+// kinds with object metadata, which belongs to the core v1 API package but
+// is not generated.
+func hasMetadataInterface(rootPackage string) string {
+	return rootPackage + ".api.v1.HasMetadata"
+}
+
+// defaultTypeMappings returns the Java types for Go types that are not
+// generated as classes.
+func defaultTypeMappings(rootPackage string) typemap.Table {
+	return typemap.Table{
+		"k8s.io/kubernetes/pkg/runtime.RawExtension": {Type: hasMetadataInterface(rootPackage)},
+		"k8s.io/kubernetes/pkg/api/unversioned.Time": {
+			Type: "java.util.Date",
+			Annotations: []string{
+				"@com.fasterxml.jackson.databind.annotation.JsonDeserialize(using = io.fabric8.kubernetes.types.common.RFC3339DateDeserializer.class)",
+				`@com.fasterxml.jackson.annotation.JsonFormat(shape = com.fasterxml.jackson.annotation.JsonFormat.Shape.STRING, pattern = io.fabric8.kubernetes.types.common.RFC3339DateDeserializer.RFC3339_FORMAT, timezone="UTC")`,
+			},
+		},
+		"k8s.io/kubernetes/pkg/util/intstr.IntOrString": {Type: "io.fabric8.kubernetes.types.common.IntOrString"},
+	}
+}
+
+func javaType(rootPackage, openshiftRootPackage string, enums map[string]struct{}, mappings typemap.Table, typ types.Type, typeName string) (string, error) {
+	if m, ok := mappings.Lookup(typ); ok {
+		return m.Type, nil
+	}
 	typeName = strings.TrimPrefix(typeName, "github.com/openshift/origin/vendor/")
+	if _, ok := enums[typeName]; ok {
+		javaPkg, _, _ := javaPackage(rootPackage, openshiftRootPackage, typeName)
+		return javaPkg, nil
+	}
+	switch loader.Dynamic(typ) {
+	case loader.ArbitraryJSON:
+		return "com.fasterxml.jackson.databind.JsonNode", nil
+	case loader.EmbeddedObject:
+		return hasMetadataInterface(rootPackage), nil
+	}
 	switch fldT := typ.Underlying().(type) {
 	case *types.Slice:
-		elemType, err := javaType(rootPackage, openshiftRootPackage, fldT.Elem(), fldT.Elem().String())
+		elemType, err := javaType(rootPackage, openshiftRootPackage, enums, mappings, fldT.Elem(), fldT.Elem().String())
 		if err != nil {
 			return "", err
 		}
 		return "java.util.List<" + elemType + ">", nil
 	case *types.Map:
-		keyType, err := javaType(rootPackage, openshiftRootPackage, fldT.Key(), fldT.Key().String())
+		keyType, err := javaType(rootPackage, openshiftRootPackage, enums, mappings, fldT.Key(), fldT.Key().String())
 		if err != nil {
 			return "", err
 		}
-		elemType, err := javaType(rootPackage, openshiftRootPackage, fldT.Elem(), fldT.Elem().String())
+		elemType, err := javaType(rootPackage, openshiftRootPackage, enums, mappings, fldT.Elem(), fldT.Elem().String())
 		if err != nil {
 			return "", err
 		}
 		return "java.util.Map<" + keyType + ", " + elemType + ">", nil
 	case *types.Struct:
-		switch typeName {
-		case "k8s.io/kubernetes/pkg/runtime.RawExtension":
-			return "io.fabric8.kubernetes.types.api.v1.HasMetadata", nil
-		case "k8s.io/kubernetes/pkg/api/unversioned.Time":
-			return "java.util.Date", nil
-		case "k8s.io/kubernetes/pkg/util/intstr.IntOrString":
-			return "io.fabric8.kubernetes.types.common.IntOrString", nil
-		default:
-			javaPkg, _, _ := javaPackage(rootPackage, openshiftRootPackage, typeName)
-			return javaPkg, nil
-		}
+		javaPkg, _, _ := javaPackage(rootPackage, openshiftRootPackage, typeName)
+		return javaPkg, nil
 	case *types.Pointer:
-		return javaType(rootPackage, openshiftRootPackage, fldT.Elem(), fldT.Elem().String())
+		return javaType(rootPackage, openshiftRootPackage, enums, mappings, fldT.Elem(), fldT.Elem().String())
 	case *types.Basic:
 		return javaTypeBasic(fldT.Kind()), nil
 	default:
@@ -75,6 +108,38 @@ func javaType(rootPackage, openshiftRootPackage string, typ types.Type, typeName
 	}
 }
 
+// missingReference returns the struct type typ refers to, if it is neither
+// generated nor mapped, and so would refer to a class that does not exist.
+func missingReference(known map[string]struct{}, mappings typemap.Table, typ types.Type) (string, bool) {
+	if _, ok := mappings.Lookup(typ); ok || loader.Dynamic(typ) != loader.NotDynamic {
+		return "", false
+	}
+	switch t := typ.(type) {
+	case *types.Named:
+		if _, ok := t.Underlying().(*types.Struct); !ok || t.Obj().Pkg() == nil {
+			return missingReference(known, mappings, t.Underlying())
+		}
+		name := loader.StripVendor(t.Obj().Pkg().Path()) + "." + t.Obj().Name()
+		if _, ok := known[name]; ok {
+			return "", false
+		}
+		return name, true
+	case *types.Pointer:
+		return missingReference(known, mappings, t.Elem())
+	case *types.Slice:
+		return missingReference(known, mappings, t.Elem())
+	case *types.Array:
+		return missingReference(known, mappings, t.Elem())
+	case *types.Map:
+		if name, ok := missingReference(known, mappings, t.Key()); ok {
+			return name, true
+		}
+		return missingReference(known, mappings, t.Elem())
+	default:
+		return "", false
+	}
+}
+
 func javaTypeBasic(kind types.BasicKind) string {
 	switch kind {
 	case types.Bool:
@@ -92,3 +157,133 @@ func javaTypeBasic(kind types.BasicKind) string {
 		return ""
 	}
 }
+
+// constantName converts a Go constant name such as RestartPolicyOnFailure to
+// a Java enum constant name such as RESTART_POLICY_ON_FAILURE.
+func constantName(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+func javaLiteral(javaType, value string) string {
+	switch javaType {
+	case "String":
+		return javaString(value)
+	case "Long":
+		return value + "L"
+	case "Float":
+		return value + "f"
+	default:
+		return value
+	}
+}
+
+func javaString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < ' ' {
+				fmt.Fprintf(&b, `\u%04x`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// validationConstraints returns the Bean Validation annotations for a field
+// from its validation markers, each followed by the indent of the field
+// declaration.
+func validationConstraints(javaType string, v loader.Validation) string {
+	const indent = "\n  "
+
+	annotations := []string{"@javax.validation.Valid"}
+
+	if v.Required {
+		annotations = append(annotations, "@javax.validation.constraints.NotNull")
+	}
+
+	switch {
+	case javaType == "String":
+		if size := sizeConstraint(v.MinLength, v.MaxLength); size != "" {
+			annotations = append(annotations, size)
+		}
+		if v.Pattern != "" {
+			annotations = append(annotations, "@javax.validation.constraints.Pattern(regexp = "+javaString(v.Pattern)+")")
+		}
+	case strings.HasPrefix(javaType, "java.util.List<"), strings.HasPrefix(javaType, "java.util.Map<"):
+		if size := sizeConstraint(v.MinItems, v.MaxItems); size != "" {
+			annotations = append(annotations, size)
+		}
+	case javaType == "Integer", javaType == "Long":
+		if v.Minimum != nil {
+			annotations = append(annotations, boundConstraint("Min", *v.Minimum, v.ExclusiveMinimum))
+		}
+		if v.Maximum != nil {
+			annotations = append(annotations, boundConstraint("Max", *v.Maximum, v.ExclusiveMaximum))
+		}
+	case javaType == "Float":
+		// @Min and @Max do not support floating point types.
+		if v.Minimum != nil {
+			annotations = append(annotations, decimalConstraint("Min", *v.Minimum, v.ExclusiveMinimum))
+		}
+		if v.Maximum != nil {
+			annotations = append(annotations, decimalConstraint("Max", *v.Maximum, v.ExclusiveMaximum))
+		}
+	}
+
+	return strings.Join(annotations, indent) + indent
+}
+
+func sizeConstraint(min, max *int64) string {
+	var args []string
+	if min != nil {
+		args = append(args, fmt.Sprintf("min = %d", *min))
+	}
+	if max != nil {
+		args = append(args, fmt.Sprintf("max = %d", *max))
+	}
+	if len(args) == 0 {
+		return ""
+	}
+	return "@javax.validation.constraints.Size(" + strings.Join(args, ", ") + ")"
+}
+
+// boundConstraint returns a @Min or @Max constraint, falling back to
+// @DecimalMin or @DecimalMax for exclusive or non-integral bounds.
+func boundConstraint(name string, bound float64, exclusive bool) string {
+	if !exclusive && bound == math.Trunc(bound) && math.Abs(bound) < math.MaxInt64 {
+		return fmt.Sprintf("@javax.validation.constraints.%s(%d)", name, int64(bound))
+	}
+	return decimalConstraint(name, bound, exclusive)
+}
+
+// decimalConstraint returns a @DecimalMin or @DecimalMax constraint.
+func decimalConstraint(name string, bound float64, exclusive bool) string {
+	return fmt.Sprintf("@javax.validation.constraints.Decimal%s(value = %q, inclusive = %t)", name, strconv.FormatFloat(bound, 'g', -1, 64), !exclusive)
+}
@@ -0,0 +1,89 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/generator/schema"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
+)
+
+const draft07 = "http://json-schema.org/draft-07/schema#"
+
+func New(c Config) generator.Generator {
+	c.Logger.Debug("creating generator", "type", "jsonschema")
+	return &jsonSchemaGenerator{
+		config: c,
+	}
+}
+
+type Config struct {
+	generator.Config
+
+	SchemaFile string
+	Title      string
+}
+
+type jsonSchemaGenerator struct {
+	config Config
+}
+
+var _ generator.Generator = &jsonSchemaGenerator{}
+
+func (g *jsonSchemaGenerator) Generate(pkgs []loader.Package) error {
+	g.config.Logger.Debug("generating")
+
+	var diags generator.Diagnostics
+	doc := g.document(pkgs, &diags)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return errors.Wrapf(err, "failed to encode %s", g.config.SchemaFile)
+	}
+
+	if err := g.config.Output.WriteFile(g.config.SchemaFile, buf.Bytes()); err != nil {
+		return err
+	}
+	return diags.Err()
+}
+
+func (g *jsonSchemaGenerator) document(pkgs []loader.Package, diags *generator.Diagnostics) *schema.Schema {
+	converter := schema.NewConverter("#/definitions/", pkgs, typemap.Merge(schema.DefaultTypeMappings, g.config.TypeMappings["jsonschema"]))
+
+	definitions := map[string]*schema.Schema{}
+	for _, pkg := range pkgs {
+		g.config.Logger.Debug("generating for package", "package", pkg.Path)
+
+		for _, typ := range pkg.Types {
+			if converter.Maps(pkg.Path, typ.Name) {
+				continue
+			}
+			def := converter.TypeSchema(typ, diags)
+			if def == nil {
+				continue
+			}
+			definitions[schema.DefinitionName(pkg.Path, typ.Name)] = def
+		}
+
+		for _, enum := range pkg.Enums {
+			def := converter.EnumSchema(pkg.Path, enum, diags)
+			if def == nil {
+				continue
+			}
+			definitions[schema.DefinitionName(pkg.Path, enum.Name)] = def
+		}
+	}
+
+	return &schema.Schema{
+		Schema:      draft07,
+		Title:       g.config.Title,
+		Type:        "object",
+		Definitions: definitions,
+	}
+}
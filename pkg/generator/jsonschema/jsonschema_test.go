@@ -0,0 +1,146 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"go/token"
+	"go/types"
+
+	"github.com/inconshreveable/log15"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	. "github.com/jimmidyson/kube-client-gen/pkg/generator/jsonschema"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+func namedType(pkg *types.Package, name string) types.Type {
+	return types.NewNamed(types.NewTypeName(token.NoPos, pkg, name, nil), types.NewStruct(nil, nil), nil)
+}
+
+var _ = Describe("JSONSchema", func() {
+	var logger log15.Logger
+
+	BeforeEach(func() {
+		logger = log15.New()
+		logger.SetHandler(log15.DiscardHandler())
+	})
+
+	unversioned := types.NewPackage("k8s.io/kubernetes/pkg/api/unversioned", "unversioned")
+	v1 := types.NewPackage("k8s.io/kubernetes/pkg/api/v1", "v1")
+
+	pkgs := []loader.Package{
+		{
+			Path: unversioned.Path(),
+			Types: []loader.Type{
+				{Name: "TypeMeta", Fields: []loader.Field{
+					{Name: "Kind", JSONProperty: "kind", Type: types.Typ[types.String]},
+				}},
+			},
+		},
+		{
+			Path: v1.Path(),
+			Types: []loader.Type{
+				{Name: "Pod", Doc: "Pod is a collection of containers.", Fields: []loader.Field{
+					{Name: "TypeMeta", Anonymous: true, Type: namedType(unversioned, "TypeMeta")},
+					{Name: "Spec", JSONProperty: "spec", JSONRequired: true, Type: namedType(v1, "PodSpec")},
+					{Name: "CreationTimestamp", JSONProperty: "creationTimestamp", Type: namedType(unversioned, "Time")},
+					{Name: "Labels", JSONProperty: "labels", Type: types.NewMap(types.Typ[types.String], types.Typ[types.String])},
+					{Name: "Template", Doc: "Template is the spec of replacement pods.", JSONProperty: "template", Type: namedType(v1, "PodSpec")},
+					{Name: "Defaults", JSONProperty: "defaults", Type: namedType(v1, "PodSpec"), Markers: loader.ParseMarkers(`+kubebuilder:default={"replicas":1}`)},
+				}},
+				{Name: "PodSpec", Fields: []loader.Field{
+					{Name: "Replicas", Doc: "Replicas is the number of pods.", JSONProperty: "replicas", Type: types.NewPointer(types.Typ[types.Int32])},
+					{Name: "Data", JSONProperty: "data", Type: types.NewSlice(types.Typ[types.Byte])},
+					{Name: "Hosts", Type: types.NewSlice(types.Typ[types.String])},
+				}},
+			},
+		},
+	}
+
+	generate := func() map[string]interface{} {
+		out := generator.NewMemoryOutput()
+		err := New(Config{Config: generator.Config{Logger: logger, Output: out}, SchemaFile: "kube-schema.json", Title: "Kubernetes"}).Generate(pkgs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Files).To(HaveLen(1))
+
+		var schema map[string]interface{}
+		Expect(json.Unmarshal(out.Files["kube-schema.json"], &schema)).To(Succeed())
+		return schema
+	}
+
+	definition := func(schema map[string]interface{}, name string) map[string]interface{} {
+		return schema["definitions"].(map[string]interface{})[name].(map[string]interface{})
+	}
+
+	It("writes a draft-07 schema with a definition per type", func() {
+		schema := generate()
+		Expect(schema).To(HaveKeyWithValue("$schema", "http://json-schema.org/draft-07/schema#"))
+		Expect(schema).To(HaveKeyWithValue("title", "Kubernetes"))
+		Expect(schema["definitions"]).To(HaveLen(3))
+		Expect(schema["definitions"]).To(HaveKey("k8s.io.kubernetes.pkg.api.unversioned.TypeMeta"))
+		Expect(schema["definitions"]).To(HaveKey("k8s.io.kubernetes.pkg.api.v1.Pod"))
+		Expect(schema["definitions"]).To(HaveKey("k8s.io.kubernetes.pkg.api.v1.PodSpec"))
+	})
+
+	It("refers to known types and inlines embedded ones with allOf", func() {
+		pod := definition(generate(), "k8s.io.kubernetes.pkg.api.v1.Pod")
+		Expect(pod).To(HaveKeyWithValue("description", "Pod is a collection of containers."))
+		Expect(pod).To(HaveKeyWithValue("required", []interface{}{"spec"}))
+		Expect(pod).To(HaveKeyWithValue("allOf", []interface{}{
+			map[string]interface{}{"$ref": "#/definitions/k8s.io.kubernetes.pkg.api.unversioned.TypeMeta"},
+		}))
+
+		properties := pod["properties"].(map[string]interface{})
+		Expect(properties).NotTo(HaveKey("TypeMeta"))
+		Expect(properties["spec"]).To(Equal(map[string]interface{}{"$ref": "#/definitions/k8s.io.kubernetes.pkg.api.v1.PodSpec"}))
+		Expect(properties["creationTimestamp"]).To(Equal(map[string]interface{}{"type": "string", "format": "date-time"}))
+		Expect(properties["labels"]).To(Equal(map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+		}))
+	})
+
+	It("does not define mapped types, such as Time", func() {
+		withTime := append([]loader.Package{}, pkgs...)
+		withTime[0].Types = append([]loader.Type{{Name: "Time"}}, withTime[0].Types...)
+
+		out := generator.NewMemoryOutput()
+		Expect(New(Config{Config: generator.Config{Logger: logger, Output: out}, SchemaFile: "kube-schema.json"}).Generate(withTime)).To(Succeed())
+		var schema map[string]interface{}
+		Expect(json.Unmarshal(out.Files["kube-schema.json"], &schema)).To(Succeed())
+		Expect(schema["definitions"]).NotTo(HaveKey("k8s.io.kubernetes.pkg.api.unversioned.Time"))
+		Expect(schema["definitions"]).To(HaveKey("k8s.io.kubernetes.pkg.api.unversioned.TypeMeta"))
+	})
+
+	It("wraps described references in allOf to keep their descriptions", func() {
+		properties := definition(generate(), "k8s.io.kubernetes.pkg.api.v1.Pod")["properties"].(map[string]interface{})
+		Expect(properties["template"]).To(Equal(map[string]interface{}{
+			"description": "Template is the spec of replacement pods.",
+			"allOf":       []interface{}{map[string]interface{}{"$ref": "#/definitions/k8s.io.kubernetes.pkg.api.v1.PodSpec"}},
+		}))
+	})
+
+	It("wraps references with validation markers in allOf to keep their validation", func() {
+		properties := definition(generate(), "k8s.io.kubernetes.pkg.api.v1.Pod")["properties"].(map[string]interface{})
+		Expect(properties["defaults"]).To(Equal(map[string]interface{}{
+			"default": map[string]interface{}{"replicas": float64(1)},
+			"allOf":   []interface{}{map[string]interface{}{"$ref": "#/definitions/k8s.io.kubernetes.pkg.api.v1.PodSpec"}},
+		}))
+	})
+
+	It("maps basic, pointer and slice fields", func() {
+		properties := definition(generate(), "k8s.io.kubernetes.pkg.api.v1.PodSpec")["properties"].(map[string]interface{})
+		Expect(properties["replicas"]).To(Equal(map[string]interface{}{
+			"type":        "integer",
+			"format":      "int32",
+			"description": "Replicas is the number of pods.",
+		}))
+		Expect(properties["data"]).To(Equal(map[string]interface{}{"type": "string", "format": "byte"}))
+		Expect(properties["Hosts"]).To(Equal(map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		}))
+	})
+})
@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// ManifestVersion is the schema version of generation manifests.
+const ManifestVersion = 1
+
+// Manifest lists the files a generator produced in an output directory, with
+// their content hashes, so the files it no longer produces can be pruned on
+// the next run without touching files it never produced.
+type Manifest struct {
+	Version   int    `json:"version"`
+	Generator string `json:"generator"`
+	// Files maps slash separated file names, relative to the output
+	// directory, to the hashes of their contents.
+	Files map[string]string `json:"files"`
+}
+
+// ManifestFile returns the name of the manifest of a generator, relative to
+// its output directory.
+func ManifestFile(generator string) string {
+	return ".kube-client-gen-" + generator + ".json"
+}
+
+// Hash returns the content hash recorded in manifests for data.
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ReadManifest reads the manifest of a generator from dir, returning nil if
+// there is none.
+func ReadManifest(dir, generator string) (*Manifest, error) {
+	fp := filepath.Join(dir, ManifestFile(generator))
+	data, err := os.ReadFile(fp)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read manifest %s", fp)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse manifest %s", fp)
+	}
+	if m.Version != ManifestVersion {
+		return nil, errors.Errorf("unsupported manifest version %d in %s, expected %d", m.Version, fp, ManifestVersion)
+	}
+	if m.Generator != generator {
+		return nil, errors.Errorf("manifest %s is for generator %s, expected %s", fp, m.Generator, generator)
+	}
+	return &m, nil
+}
+
+// Write writes the manifest to dir, replacing any previous one.
+func (m *Manifest) Write(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode manifest")
+	}
+	return NewFileOutput(dir, true).WriteFile(ManifestFile(m.Generator), append(data, '\n'))
+}
+
+// Stale returns the sorted names of the files in the manifest that are not
+// in current.
+func (m *Manifest) Stale(current map[string]string) []string {
+	var stale []string
+	for name := range m.Files {
+		if _, ok := current[name]; !ok {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// Prune deletes the files of the previous manifest from dir that are not in
+// m, along with directories left empty. Files changed since they were
+// generated are kept and returned as modified.
+func (m *Manifest) Prune(dir string, previous *Manifest) (deleted, modified []string, err error) {
+	if previous == nil {
+		return nil, nil, nil
+	}
+
+	for _, name := range previous.Stale(m.Files) {
+		if _, err := CleanName(name); err != nil {
+			return deleted, modified, errors.Wrapf(err, "invalid file in manifest")
+		}
+
+		fp := filepath.Join(dir, filepath.FromSlash(name))
+		data, err := os.ReadFile(fp)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return deleted, modified, errors.Wrapf(err, "failed to read file %s", fp)
+		}
+		if Hash(data) != previous.Files[name] {
+			modified = append(modified, name)
+			continue
+		}
+
+		if err := os.Remove(fp); err != nil {
+			return deleted, modified, errors.Wrapf(err, "failed to delete file %s", fp)
+		}
+		deleted = append(deleted, name)
+
+		// Remove parent directories left empty, stopping at the first that
+		// is not, which os.Remove refuses to delete.
+		for d := path.Dir(name); d != "."; d = path.Dir(d) {
+			if os.Remove(filepath.Join(dir, filepath.FromSlash(d))) != nil {
+				break
+			}
+		}
+	}
+
+	return deleted, modified, nil
+}
+
+// ManifestOutput is an Output that records the hashes of the files written
+// through it before passing them on.
+type ManifestOutput struct {
+	out      Output
+	manifest *Manifest
+}
+
+func NewManifestOutput(generator string, out Output) *ManifestOutput {
+	return &ManifestOutput{
+		out: out,
+		manifest: &Manifest{
+			Version:   ManifestVersion,
+			Generator: generator,
+			Files:     map[string]string{},
+		},
+	}
+}
+
+func (o *ManifestOutput) WriteFile(name string, data []byte) error {
+	cleaned, err := CleanName(name)
+	if err != nil {
+		return err
+	}
+	if err := o.out.WriteFile(cleaned, data); err != nil {
+		return err
+	}
+	o.manifest.Files[cleaned] = Hash(data)
+	return nil
+}
+
+// Manifest returns the manifest of the files written so far.
+func (o *ManifestOutput) Manifest() *Manifest {
+	return o.manifest
+}
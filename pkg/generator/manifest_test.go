@@ -0,0 +1,82 @@
+package generator_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/jimmidyson/kube-client-gen/pkg/generator"
+)
+
+var _ = Describe("Manifest", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "manifest")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	generate := func(files map[string]string) *Manifest {
+		out := NewManifestOutput("test", NewFileOutput(dir, true))
+		for name, content := range files {
+			Expect(out.WriteFile(name, []byte(content))).To(Succeed())
+		}
+		return out.Manifest()
+	}
+
+	It("records the hashes of written files", func() {
+		m := generate(map[string]string{"a/./b.txt": "b"})
+		Expect(m.Files).To(Equal(map[string]string{"a/b.txt": Hash([]byte("b"))}))
+
+		Expect(m.Write(dir)).To(Succeed())
+		read, err := ReadManifest(dir, "test")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(read).To(Equal(m))
+	})
+
+	It("reads no manifest from a new output directory", func() {
+		Expect(ReadManifest(dir, "test")).To(BeNil())
+	})
+
+	It("prunes files that are no longer generated", func() {
+		previous := generate(map[string]string{
+			"a/Kept.java":     "kept",
+			"a/Removed.java":  "removed",
+			"a/Modified.java": "modified",
+			"b/c/Gone.java":   "gone",
+		})
+		Expect(os.WriteFile(filepath.Join(dir, "a", "Modified.java"), []byte("changed"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "a", "HandWritten.java"), []byte("mine"), 0644)).To(Succeed())
+
+		m := generate(map[string]string{"a/Kept.java": "kept"})
+		deleted, modified, err := m.Prune(dir, previous)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(Equal([]string{"a/Removed.java", "b/c/Gone.java"}))
+		Expect(modified).To(Equal([]string{"a/Modified.java"}))
+
+		Expect(filepath.Join(dir, "a", "Removed.java")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(dir, "b")).NotTo(BeADirectory())
+		Expect(filepath.Join(dir, "a", "Kept.java")).To(BeAnExistingFile())
+		Expect(filepath.Join(dir, "a", "Modified.java")).To(BeAnExistingFile())
+		Expect(filepath.Join(dir, "a", "HandWritten.java")).To(BeAnExistingFile())
+	})
+
+	It("reports files of the previous manifest as removed when verifying", func() {
+		previous := generate(map[string]string{"a/Kept.java": "kept\n", "a/Removed.java": "removed\n"})
+		Expect(os.WriteFile(filepath.Join(dir, "a", "HandWritten.java"), []byte("mine\n"), 0644)).To(Succeed())
+
+		var buf bytes.Buffer
+		upToDate, err := Verify(map[string][]byte{"a/Kept.java": []byte("kept\n")}, previous, dir, &buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(upToDate).To(BeFalse())
+		Expect(buf.String()).To(Equal("--- a/a/Removed.java\n+++ /dev/null\n@@ -1 +0,0 @@\n-removed\n"))
+	})
+})
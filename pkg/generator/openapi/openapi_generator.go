@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/generator/schema"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
+)
+
+const openAPIVersion = "3.0.3"
+
+func New(c Config) generator.Generator {
+	c.Logger.Debug("creating generator", "type", "openapi")
+	return &openAPIGenerator{
+		config: c,
+	}
+}
+
+type Config struct {
+	generator.Config
+
+	OpenAPIFile string
+	Title       string
+	Version     string
+	Paths       bool
+}
+
+type openAPIGenerator struct {
+	config Config
+}
+
+var _ generator.Generator = &openAPIGenerator{}
+
+type document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       info                 `json:"info"`
+	Paths      map[string]*pathItem `json:"paths"`
+	Components components           `json:"components"`
+}
+
+type info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type components struct {
+	Schemas map[string]*schema.Schema `json:"schemas"`
+}
+
+func (g *openAPIGenerator) Generate(pkgs []loader.Package) error {
+	g.config.Logger.Debug("generating")
+
+	var diags generator.Diagnostics
+	doc := g.document(pkgs, &diags)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return errors.Wrapf(err, "failed to encode %s", g.config.OpenAPIFile)
+	}
+
+	if err := g.config.Output.WriteFile(g.config.OpenAPIFile, buf.Bytes()); err != nil {
+		return err
+	}
+	return diags.Err()
+}
+
+func (g *openAPIGenerator) document(pkgs []loader.Package, diags *generator.Diagnostics) *document {
+	converter := schema.NewConverter("#/components/schemas/", pkgs, typemap.Merge(schema.DefaultTypeMappings, g.config.TypeMappings["openapi"]))
+
+	doc := &document{
+		OpenAPI: openAPIVersion,
+		Info: info{
+			Title:   g.config.Title,
+			Version: g.config.Version,
+		},
+		Paths: map[string]*pathItem{},
+		Components: components{
+			Schemas: map[string]*schema.Schema{},
+		},
+	}
+
+	for _, pkg := range pkgs {
+		g.config.Logger.Debug("generating for package", "package", pkg.Path)
+
+		for _, typ := range pkg.Types {
+			if converter.Maps(pkg.Path, typ.Name) {
+				continue
+			}
+			s := converter.TypeSchema(typ, diags)
+			if s == nil {
+				continue
+			}
+			if typ.Kind != "" {
+				s.GroupVersionKind = []schema.GroupVersionKind{{Group: typ.Group, Version: typ.Version, Kind: typ.Kind}}
+			}
+			doc.Components.Schemas[schema.DefinitionName(pkg.Path, typ.Name)] = s
+
+			if g.config.Paths && typ.GenerateClient {
+				if typ.Version == "" {
+					g.config.Logger.Warn("skipping paths for type without a registered version", "package", pkg.Path, "type", typ.Name)
+				} else {
+					g.config.Logger.Debug("generating paths", "package", pkg.Path, "type", typ.Name)
+					addPaths(doc.Paths, converter, pkg, typ)
+				}
+			}
+		}
+
+		for _, enum := range pkg.Enums {
+			s := converter.EnumSchema(pkg.Path, enum, diags)
+			if s == nil {
+				continue
+			}
+			doc.Components.Schemas[schema.DefinitionName(pkg.Path, enum.Name)] = s
+		}
+	}
+
+	return doc
+}
@@ -1,4 +1,4 @@
-package table_test
+package openapi_test
 
 import (
 	. "github.com/onsi/ginkgo"
@@ -7,7 +7,7 @@ import (
 	"testing"
 )
 
-func TestTable(t *testing.T) {
+func TestOpenAPI(t *testing.T) {
 	RegisterFailHandler(Fail)
-	RunSpecs(t, "Table Suite")
+	RunSpecs(t, "OpenAPI Suite")
 }
@@ -0,0 +1,107 @@
+package openapi_test
+
+import (
+	"encoding/json"
+
+	"github.com/inconshreveable/log15"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	. "github.com/jimmidyson/kube-client-gen/pkg/generator/openapi"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+type operation struct {
+	OperationID string
+	Tags        []string
+}
+
+type pathItem struct {
+	Get, Put, Post, Delete, Patch *operation
+}
+
+type document struct {
+	OpenAPI string
+	Info    struct {
+		Title, Version string
+	}
+	Paths      map[string]pathItem
+	Components struct {
+		Schemas map[string]map[string]interface{}
+	}
+}
+
+var _ = Describe("OpenAPI", func() {
+	var logger log15.Logger
+
+	BeforeEach(func() {
+		logger = log15.New()
+		logger.SetHandler(log15.DiscardHandler())
+	})
+
+	generate := func(paths bool) document {
+		pkgs, err := loader.New([]string{
+			"k8s.io/kubernetes/pkg/api/unversioned",
+			"k8s.io/kubernetes/pkg/api/v1",
+			"k8s.io/kubernetes/pkg/apis/apps/v1",
+		}, logger, loader.WithDir("../testdata/module")).Load()
+		Expect(err).NotTo(HaveOccurred())
+
+		out := generator.NewMemoryOutput()
+		err = New(Config{Config: generator.Config{Logger: logger, Output: out}, OpenAPIFile: "openapi.json", Title: "Kubernetes", Version: "v1.0.0", Paths: paths}).Generate(pkgs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Files).To(HaveLen(1))
+
+		var doc document
+		Expect(json.Unmarshal(out.Files["openapi.json"], &doc)).To(Succeed())
+		return doc
+	}
+
+	It("writes component schemas with their group, version and kind", func() {
+		doc := generate(false)
+		Expect(doc.OpenAPI).To(Equal("3.0.3"))
+		Expect(doc.Info.Title).To(Equal("Kubernetes"))
+		Expect(doc.Info.Version).To(Equal("v1.0.0"))
+		Expect(doc.Paths).To(BeEmpty())
+
+		Expect(doc.Components.Schemas).To(HaveKey("k8s.io.kubernetes.pkg.api.v1.PodSpec"))
+		Expect(doc.Components.Schemas["k8s.io.kubernetes.pkg.api.v1.PodSpec"]).NotTo(HaveKey("x-kubernetes-group-version-kind"))
+		Expect(doc.Components.Schemas["k8s.io.kubernetes.pkg.api.v1.Pod"]).To(HaveKeyWithValue("x-kubernetes-group-version-kind", []interface{}{
+			map[string]interface{}{"group": "", "version": "v1", "kind": "Pod"},
+		}))
+		Expect(doc.Components.Schemas["k8s.io.kubernetes.pkg.apis.apps.v1.Deployment"]).To(HaveKeyWithValue("x-kubernetes-group-version-kind", []interface{}{
+			map[string]interface{}{"group": "apps", "version": "v1", "kind": "Deployment"},
+		}))
+		Expect(doc.Components.Schemas["k8s.io.kubernetes.pkg.api.v1.PodPhase"]).To(HaveKeyWithValue("enum", []interface{}{"Pending", "Running"}))
+	})
+
+	It("gives unsigned integers a minimum and uint32 the int64 format", func() {
+		spec := generate(false).Components.Schemas["k8s.io.kubernetes.pkg.apis.apps.v1.DeploymentSpec"]["properties"].(map[string]interface{})
+		Expect(spec["minReadySeconds"]).To(HaveKeyWithValue("format", "int64"))
+		Expect(spec["minReadySeconds"]).To(HaveKeyWithValue("minimum", 0.0))
+		Expect(spec["replicas"]).To(HaveKeyWithValue("format", "int32"))
+		Expect(spec["replicas"]).NotTo(HaveKey("minimum"))
+	})
+
+	It("writes namespaced paths for namespaced client types", func() {
+		paths := generate(true).Paths
+		Expect(paths).To(HaveKey("/api/v1/pods"))
+		Expect(paths["/api/v1/pods"].Get.OperationID).To(Equal("listCoreV1PodForAllNamespaces"))
+		Expect(paths["/api/v1/namespaces/{namespace}/pods"].Post.OperationID).To(Equal("createCoreV1NamespacedPod"))
+		Expect(paths["/api/v1/namespaces/{namespace}/pods/{name}"].Get.OperationID).To(Equal("readCoreV1NamespacedPod"))
+		Expect(paths).To(HaveKey("/api/v1/namespaces/{namespace}/endpoints/{name}"))
+		Expect(paths).NotTo(HaveKey(ContainSubstring("endpointses")))
+		Expect(paths["/apis/apps/v1/namespaces/{namespace}/deployments/{name}"].Put.OperationID).To(Equal("replaceAppsV1NamespacedDeployment"))
+		Expect(paths["/apis/apps/v1/namespaces/{namespace}/deployments/{name}"].Put.Tags).To(Equal([]string{"apps_v1"}))
+	})
+
+	It("writes cluster-scoped paths for non-namespaced client types", func() {
+		paths := generate(true).Paths
+		Expect(paths["/api/v1/nodes"].Get.OperationID).To(Equal("listCoreV1Node"))
+		Expect(paths["/api/v1/nodes/{name}"].Delete.OperationID).To(Equal("deleteCoreV1Node"))
+		Expect(paths).NotTo(HaveKey("/api/v1/namespaces/{namespace}/nodes"))
+		Expect(paths).NotTo(HaveKey(ContainSubstring("podspecs")))
+	})
+})
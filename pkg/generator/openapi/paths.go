@@ -0,0 +1,253 @@
+package openapi
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator/schema"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+type pathItem struct {
+	Parameters []*parameter `json:"parameters,omitempty"`
+	Get        *operation   `json:"get,omitempty"`
+	Put        *operation   `json:"put,omitempty"`
+	Post       *operation   `json:"post,omitempty"`
+	Delete     *operation   `json:"delete,omitempty"`
+	Patch      *operation   `json:"patch,omitempty"`
+}
+
+type operation struct {
+	OperationID string               `json:"operationId"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*parameter         `json:"parameters,omitempty"`
+	RequestBody *requestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *schema.Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema *schema.Schema `json:"schema"`
+}
+
+type response struct {
+	Description string                `json:"description"`
+	Content     map[string]*mediaType `json:"content,omitempty"`
+}
+
+var (
+	namespaceParameter = &parameter{
+		Name:        "namespace",
+		In:          "path",
+		Description: "object name and auth scope, such as for teams and projects",
+		Required:    true,
+		Schema:      &schema.Schema{Type: "string"},
+	}
+	nameParameter = &parameter{
+		Name:        "name",
+		In:          "path",
+		Description: "name of the object",
+		Required:    true,
+		Schema:      &schema.Schema{Type: "string"},
+	}
+	listParameters = []*parameter{
+		{Name: "labelSelector", In: "query", Description: "a selector to restrict the list of returned objects by their labels", Schema: &schema.Schema{Type: "string"}},
+		{Name: "fieldSelector", In: "query", Description: "a selector to restrict the list of returned objects by their fields", Schema: &schema.Schema{Type: "string"}},
+		{Name: "limit", In: "query", Description: "maximum number of objects to return", Schema: &schema.Schema{Type: "integer"}},
+		{Name: "continue", In: "query", Description: "continue token from a previous list call", Schema: &schema.Schema{Type: "string"}},
+		{Name: "resourceVersion", In: "query", Description: "resource version to list or watch from", Schema: &schema.Schema{Type: "string"}},
+		{Name: "watch", In: "query", Description: "watch for changes instead of listing", Schema: &schema.Schema{Type: "boolean"}},
+	}
+	patchContentTypes = []string{
+		"application/json-patch+json",
+		"application/merge-patch+json",
+		"application/strategic-merge-patch+json",
+	}
+)
+
+// addPaths adds the REST paths for a client-enabled type, namespaced or
+// cluster-scoped according to the type.
+func addPaths(paths map[string]*pathItem, converter *schema.Converter, pkg loader.Package, typ loader.Type) {
+	group, version := typ.Group, typ.Version
+	client := typ.Markers.Client()
+	resource := typ.Resource()
+	objectSchema := &schema.Schema{Ref: converter.Ref(pkg.Path, typ.Name)}
+	listSchema := &schema.Schema{Type: "object"}
+	if hasType(pkg, typ.Name+"List") {
+		listSchema = &schema.Schema{Ref: converter.Ref(pkg.Path, typ.Name+"List")}
+	}
+
+	root := "/apis/" + group + "/" + version
+	if group == "" {
+		root = "/api/" + version
+	}
+	prefix := operationPrefix(group, version)
+	tags := []string{tag(group, version)}
+
+	collectionPath := root + "/" + resource
+	scope := ""
+	var scopeParameters []*parameter
+	if typ.Namespaced {
+		collectionPath = root + "/namespaces/{namespace}/" + resource
+		scope = "Namespaced"
+		scopeParameters = []*parameter{namespaceParameter}
+
+		if client.HasVerb("list") {
+			paths[root+"/"+resource] = &pathItem{
+				Get: &operation{
+					OperationID: "list" + prefix + typ.Name + "ForAllNamespaces",
+					Description: "list or watch objects of kind " + typ.Name + " across all namespaces",
+					Tags:        tags,
+					Parameters:  listParameters,
+					Responses:   okResponse(listSchema),
+				},
+			}
+		}
+	}
+
+	collection := &pathItem{Parameters: scopeParameters}
+	if client.HasVerb("list") {
+		collection.Get = &operation{
+			OperationID: "list" + prefix + scope + typ.Name,
+			Description: "list or watch objects of kind " + typ.Name,
+			Tags:        tags,
+			Parameters:  listParameters,
+			Responses:   okResponse(listSchema),
+		}
+	}
+	if client.HasVerb("create") {
+		collection.Post = &operation{
+			OperationID: "create" + prefix + scope + typ.Name,
+			Description: "create a " + typ.Name,
+			Tags:        tags,
+			RequestBody: jsonBody(objectSchema),
+			Responses:   createdResponse(objectSchema),
+		}
+	}
+	if client.HasVerb("deleteCollection") {
+		collection.Delete = &operation{
+			OperationID: "deleteCollection" + prefix + scope + typ.Name,
+			Description: "delete collection of " + typ.Name,
+			Tags:        tags,
+			Parameters:  listParameters[:2],
+			Responses:   okResponse(&schema.Schema{Type: "object"}),
+		}
+	}
+	if collection.Get != nil || collection.Post != nil || collection.Delete != nil {
+		paths[collectionPath] = collection
+	}
+
+	object := &pathItem{Parameters: append(append([]*parameter{}, scopeParameters...), nameParameter)}
+	if client.HasVerb("get") {
+		object.Get = &operation{
+			OperationID: "read" + prefix + scope + typ.Name,
+			Description: "read the specified " + typ.Name,
+			Tags:        tags,
+			Responses:   okResponse(objectSchema),
+		}
+	}
+	if client.HasVerb("update") {
+		object.Put = &operation{
+			OperationID: "replace" + prefix + scope + typ.Name,
+			Description: "replace the specified " + typ.Name,
+			Tags:        tags,
+			RequestBody: jsonBody(objectSchema),
+			Responses:   okResponse(objectSchema),
+		}
+	}
+	if client.HasVerb("patch") {
+		content := map[string]*mediaType{}
+		for _, contentType := range patchContentTypes {
+			content[contentType] = &mediaType{Schema: &schema.Schema{Type: "object"}}
+		}
+		object.Patch = &operation{
+			OperationID: "patch" + prefix + scope + typ.Name,
+			Description: "partially update the specified " + typ.Name,
+			Tags:        tags,
+			RequestBody: &requestBody{Required: true, Content: content},
+			Responses:   okResponse(objectSchema),
+		}
+	}
+	if client.HasVerb("delete") {
+		object.Delete = &operation{
+			OperationID: "delete" + prefix + scope + typ.Name,
+			Description: "delete a " + typ.Name,
+			Tags:        tags,
+			Responses:   okResponse(&schema.Schema{Type: "object"}),
+		}
+	}
+	if object.Get != nil || object.Put != nil || object.Patch != nil || object.Delete != nil {
+		paths[collectionPath+"/{name}"] = object
+	}
+}
+
+func jsonBody(s *schema.Schema) *requestBody {
+	return &requestBody{
+		Required: true,
+		Content:  map[string]*mediaType{"application/json": {Schema: s}},
+	}
+}
+
+func okResponse(s *schema.Schema) map[string]*response {
+	return map[string]*response{
+		"200": {Description: "OK", Content: map[string]*mediaType{"application/json": {Schema: s}}},
+	}
+}
+
+func createdResponse(s *schema.Schema) map[string]*response {
+	responses := okResponse(s)
+	responses["201"] = &response{Description: "Created", Content: responses["200"].Content}
+	return responses
+}
+
+func hasType(pkg loader.Package, name string) bool {
+	for _, typ := range pkg.Types {
+		if typ.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// operationPrefix returns the group and version part of operation IDs, such as
+// CoreV1 or AppsV1beta1.
+func operationPrefix(group, version string) string {
+	if group == "" {
+		group = "core"
+	}
+	group = strings.TrimSuffix(strings.TrimSuffix(group, ".k8s.io"), ".openshift.io")
+	prefix := ""
+	for _, part := range strings.FieldsFunc(group, func(r rune) bool { return r == '.' || r == '-' }) {
+		prefix += upperFirst(part)
+	}
+	return prefix + upperFirst(version)
+}
+
+func tag(group, version string) string {
+	if group == "" {
+		group = "core"
+	}
+	return strings.Replace(group, ".", "_", -1) + "_" + version
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return ""
+	}
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Output receives the files a generator produces. Names are slash separated
+// paths relative to the output directory.
+type Output interface {
+	WriteFile(name string, data []byte) error
+}
+
+// NewFileOutput returns an Output that writes files below dir. Unless force
+// is set, writing a file that already exists fails.
+func NewFileOutput(dir string, force bool) Output {
+	return &fileOutput{
+		dir:   dir,
+		force: force,
+	}
+}
+
+type fileOutput struct {
+	dir   string
+	force bool
+}
+
+func (o *fileOutput) WriteFile(name string, data []byte) error {
+	name, err := CleanName(name)
+	if err != nil {
+		return err
+	}
+
+	fp := filepath.Join(o.dir, filepath.FromSlash(name))
+	if !o.force {
+		_, err := os.Stat(fp)
+		if err == nil {
+			return errors.Errorf("target file %s already exists", fp)
+		}
+		if !os.IsNotExist(err) {
+			return errors.Errorf("failed to check if target file %s exists: %v", fp, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create directory %s", filepath.Dir(fp))
+	}
+	if err := os.WriteFile(fp, data, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write file %s", fp)
+	}
+	return nil
+}
+
+// MemoryOutput is an Output that keeps files in memory, keyed by their
+// cleaned name.
+type MemoryOutput struct {
+	Files map[string][]byte
+}
+
+func NewMemoryOutput() *MemoryOutput {
+	return &MemoryOutput{
+		Files: map[string][]byte{},
+	}
+}
+
+func (o *MemoryOutput) WriteFile(name string, data []byte) error {
+	name, err := CleanName(name)
+	if err != nil {
+		return err
+	}
+	o.Files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+// CopyTo writes the files to out, in name order.
+func (o *MemoryOutput) CopyTo(out Output) error {
+	names := make([]string, 0, len(o.Files))
+	for name := range o.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := out.WriteFile(name, o.Files[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewWriterOutput returns an Output that writes the contents of a file to w,
+// for streaming the output of generators that produce a single file, such as
+// a schema. Writing a second file fails, as the contents of files could not
+// be told apart.
+func NewWriterOutput(w io.Writer) Output {
+	return &writerOutput{w: w}
+}
+
+type writerOutput struct {
+	w       io.Writer
+	written string
+}
+
+func (o *writerOutput) WriteFile(name string, data []byte) error {
+	name, err := CleanName(name)
+	if err != nil {
+		return err
+	}
+	if o.written != "" {
+		return errors.Errorf("cannot write file %s, the output only takes a single file and already has %s", name, o.written)
+	}
+	o.written = name
+	_, err = o.w.Write(data)
+	return errors.Wrapf(err, "failed to write file %s", name)
+}
+
+// NewPrefixOutput returns an Output that writes files to out below the slash
+// separated directory prefix.
+func NewPrefixOutput(prefix string, out Output) Output {
+	return &prefixOutput{
+		prefix: prefix,
+		out:    out,
+	}
+}
+
+type prefixOutput struct {
+	prefix string
+	out    Output
+}
+
+func (o *prefixOutput) WriteFile(name string, data []byte) error {
+	name, err := CleanName(name)
+	if err != nil {
+		return err
+	}
+	return o.out.WriteFile(path.Join(o.prefix, name), data)
+}
+
+// CleanName cleans a slash separated file name, rejecting names that are
+// empty or would be outside of the output directory.
+func CleanName(name string) (string, error) {
+	cleaned := path.Clean(name)
+	if name == "" || cleaned == "." || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", errors.Errorf("file %q is outside of the output directory", name)
+	}
+	return cleaned, nil
+}
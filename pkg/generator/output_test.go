@@ -0,0 +1,113 @@
+package generator_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/jimmidyson/kube-client-gen/pkg/generator"
+)
+
+var _ = Describe("Output", func() {
+	It("keeps files in memory by cleaned name", func() {
+		out := NewMemoryOutput()
+		Expect(out.WriteFile("a/./b/../c.txt", []byte("c"))).To(Succeed())
+		Expect(out.Files).To(Equal(map[string][]byte{"a/c.txt": []byte("c")}))
+	})
+
+	It("rejects files outside of the output directory", func() {
+		out := NewMemoryOutput()
+		Expect(out.WriteFile("../a.txt", nil)).NotTo(Succeed())
+		Expect(out.WriteFile("/a.txt", nil)).NotTo(Succeed())
+		Expect(out.WriteFile("", nil)).NotTo(Succeed())
+	})
+
+	It("copies files in memory to another output in name order", func() {
+		out := NewMemoryOutput()
+		Expect(out.WriteFile("b.txt", []byte("b"))).To(Succeed())
+		Expect(out.WriteFile("a/c.txt", []byte("c"))).To(Succeed())
+
+		var names namesOutput
+		Expect(out.CopyTo(&names)).To(Succeed())
+		Expect(names).To(Equal(namesOutput{"a/c.txt", "b.txt"}))
+	})
+
+	It("writes files below a prefix", func() {
+		out := NewMemoryOutput()
+		Expect(NewPrefixOutput("java", out).WriteFile("a/B.java", []byte("b"))).To(Succeed())
+		Expect(out.Files).To(HaveKey("java/a/B.java"))
+	})
+
+	It("only overwrites files when forced", func() {
+		dir, err := os.MkdirTemp("", "output")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		Expect(NewFileOutput(dir, false).WriteFile("a/b.txt", []byte("1"))).To(Succeed())
+		Expect(NewFileOutput(dir, false).WriteFile("a/b.txt", []byte("2"))).To(MatchError(ContainSubstring("already exists")))
+		Expect(NewFileOutput(dir, true).WriteFile("a/b.txt", []byte("3"))).To(Succeed())
+		Expect(os.ReadFile(filepath.Join(dir, "a", "b.txt"))).To(Equal([]byte("3")))
+	})
+
+	It("writes zip archives", func() {
+		var buf bytes.Buffer
+		out := NewZipOutput(&buf)
+		Expect(out.WriteFile("a/b.txt", []byte("b"))).To(Succeed())
+		Expect(out.WriteFile("a/b.txt", []byte("b"))).To(MatchError(ContainSubstring("already in the archive")))
+		Expect(out.Close()).To(Succeed())
+
+		r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.File).To(HaveLen(1))
+		Expect(r.File[0].Name).To(Equal("a/b.txt"))
+		f, err := r.File[0].Open()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(io.ReadAll(f)).To(Equal([]byte("b")))
+	})
+
+	It("writes compressed tar archives", func() {
+		var buf bytes.Buffer
+		out := NewTarOutput(&buf, true)
+		Expect(out.WriteFile("a/b.txt", []byte("b"))).To(Succeed())
+		Expect(out.Close()).To(Succeed())
+
+		gz, err := gzip.NewReader(&buf)
+		Expect(err).NotTo(HaveOccurred())
+		r := tar.NewReader(gz)
+		hdr, err := r.Next()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hdr.Name).To(Equal("a/b.txt"))
+		Expect(io.ReadAll(r)).To(Equal([]byte("b")))
+		_, err = r.Next()
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("streams file contents", func() {
+		var buf bytes.Buffer
+		Expect(NewWriterOutput(&buf).WriteFile("schema.json", []byte("{}\n"))).To(Succeed())
+		Expect(buf.String()).To(Equal("{}\n"))
+	})
+
+	It("rejects more than one file when streaming file contents", func() {
+		var buf bytes.Buffer
+		out := NewWriterOutput(&buf)
+		Expect(out.WriteFile("a.proto", []byte("a"))).To(Succeed())
+		Expect(out.WriteFile("b.proto", []byte("b"))).To(MatchError(ContainSubstring("only takes a single file")))
+		Expect(buf.String()).To(Equal("a"))
+	})
+})
+
+// namesOutput records the names of the files written to it, in order.
+type namesOutput []string
+
+func (o *namesOutput) WriteFile(name string, data []byte) error {
+	*o = append(*o, name)
+	return nil
+}
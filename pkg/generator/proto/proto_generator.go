@@ -0,0 +1,185 @@
+package proto
+
+import (
+	"bytes"
+	"path"
+	"sort"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
+)
+
+const fileTemplateText = `// This file was generated from the Go package {{.GoPackage}}.
+{{if .Doc}}
+{{comment .Doc ""}}
+{{end}}
+syntax = "proto2";
+
+package {{.Package}};
+{{if .Imports}}
+{{range .Imports}}import "{{.}}";
+{{end}}{{end}}
+option go_package = "{{.GoPackage}}";
+{{range .Messages}}
+{{if .Doc}}{{comment .Doc ""}}
+{{end}}message {{.Name}} {{"{"}}{{range $i, $f := .Fields}}{{if $i}}
+{{end}}
+{{if .Doc}}{{comment .Doc "  "}}
+{{end}}  {{if .Label}}{{.Label}} {{end}}{{.Type}} {{.Name}} = {{.Number}};{{end}}
+}
+{{end}}`
+
+var fileTemplate = template.Must(template.New("file").
+	Funcs(
+		template.FuncMap{
+			"comment": comment,
+		},
+	).
+	Parse(fileTemplateText))
+
+func New(c Config) generator.Generator {
+	c.Logger.Debug("creating generator", "type", "proto")
+	return &protoGenerator{
+		config: c,
+	}
+}
+
+type Config struct {
+	generator.Config
+
+	// ProtoFile is the name of the file written for each package, relative to
+	// the package's directory under the output directory.
+	ProtoFile string
+}
+
+type protoGenerator struct {
+	config   Config
+	mappings typemap.Table
+	diags    generator.Diagnostics
+}
+
+var _ generator.Generator = &protoGenerator{}
+
+type file struct {
+	Doc       string
+	GoPackage string
+	Package   string
+	Imports   []string
+	Messages  []message
+}
+
+type message struct {
+	Name   string
+	Doc    string
+	Fields []field
+}
+
+type field struct {
+	Name   string
+	Doc    string
+	Label  string
+	Type   string
+	Number int
+}
+
+func (g *protoGenerator) Generate(pkgs []loader.Package) error {
+	g.config.Logger.Debug("generating")
+
+	known := knownTypes(pkgs)
+	g.mappings = typemap.Merge(defaultTypeMappings, g.config.TypeMappings["proto"])
+	g.diags = generator.Diagnostics{}
+
+	for _, pkg := range pkgs {
+		name := path.Join(pkg.Path, g.config.ProtoFile)
+		g.config.Logger.Debug("generating for package", "package", pkg.Path, "file", name)
+
+		f := g.file(pkg, known)
+
+		var buf bytes.Buffer
+		if err := fileTemplate.Execute(&buf, f); err != nil {
+			return errors.Wrapf(err, "failed to render proto file for package %s", pkg.Path)
+		}
+
+		contents := g.config.WithHeader(generator.SlashComment, pkg.Path, pkg.APIVersion(), buf.Bytes())
+		if err := g.config.Output.WriteFile(name, contents); err != nil {
+			return err
+		}
+	}
+
+	return g.diags.Err()
+}
+
+// file builds the proto file for a package, recording problems with fields
+// and leaving out the messages that have any.
+func (g *protoGenerator) file(pkg loader.Package, known map[string]struct{}) *file {
+	imports := newImportSet(pkg.Path, g.config.ProtoFile)
+
+	f := &file{
+		Doc:       pkg.Doc,
+		GoPackage: pkg.Path,
+		Package:   protoPackage(pkg.Path),
+	}
+
+	for _, typ := range pkg.Types {
+		if g.mappings.Maps(pkg.Path, typ.Name) {
+			continue
+		}
+		m := message{Name: typ.Name, Doc: typ.Doc}
+		numbers := map[int]string{}
+		problems := g.diags.Len()
+
+		for _, fld := range typ.Fields {
+			value, ok := fld.Tags.Get("protobuf")
+			if !ok || value == "-" {
+				g.config.Logger.Debug("ignoring field without protobuf tag", "type", typ.Name, "field", fld.Name)
+				continue
+			}
+			tag, err := parseProtobufTag(value)
+			if err != nil {
+				g.diags.Addf(fld.Position, "field %s.%s: invalid protobuf tag: %v", typ.Name, fld.Name, err)
+				continue
+			}
+			if other, ok := numbers[tag.Number]; ok {
+				g.diags.Addf(fld.Position, "fields %s.%s and %s.%s both use protobuf field number %d", typ.Name, other, typ.Name, fld.Name, tag.Number)
+				continue
+			}
+			numbers[tag.Number] = fld.Name
+
+			label, protoType, err := fieldType(fld.Type, known, g.mappings, imports)
+			if err != nil {
+				g.diags.Addf(fld.Position, "field %s.%s: %v", typ.Name, fld.Name, err)
+				continue
+			}
+
+			name := tag.Name
+			if name == "" {
+				name = fld.JSONProperty
+			}
+			if name == "" {
+				name = fld.Name
+			}
+			m.Fields = append(m.Fields, field{
+				Name:   name,
+				Doc:    fld.Doc,
+				Label:  label,
+				Type:   protoType,
+				Number: tag.Number,
+			})
+		}
+
+		if g.diags.Len() > problems {
+			continue
+		}
+		sort.SliceStable(m.Fields, func(i, j int) bool { return m.Fields[i].Number < m.Fields[j].Number })
+		f.Messages = append(f.Messages, m)
+	}
+
+	f.Imports = imports.list()
+	sort.Strings(f.Imports)
+
+	return f
+}
@@ -1,4 +1,4 @@
-package flags_test
+package proto_test
 
 import (
 	. "github.com/onsi/ginkgo"
@@ -7,7 +7,7 @@ import (
 	"testing"
 )
 
-func TestFlags(t *testing.T) {
+func TestProto(t *testing.T) {
 	RegisterFailHandler(Fail)
-	RunSpecs(t, "Flags Suite")
+	RunSpecs(t, "Proto Suite")
 }
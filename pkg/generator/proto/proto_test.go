@@ -0,0 +1,103 @@
+package proto_test
+
+import (
+	"go/types"
+
+	"github.com/inconshreveable/log15"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	. "github.com/jimmidyson/kube-client-gen/pkg/generator/proto"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+var _ = Describe("Proto", func() {
+	var out *generator.MemoryOutput
+
+	BeforeEach(func() {
+		logger := log15.New()
+		logger.SetHandler(log15.DiscardHandler())
+
+		pkgs, err := loader.New([]string{
+			"k8s.io/kubernetes/pkg/api/unversioned",
+			"k8s.io/kubernetes/pkg/api/v1",
+			"k8s.io/kubernetes/pkg/apis/apps/v1",
+		}, logger, loader.WithDir("../testdata/module")).Load()
+		Expect(err).NotTo(HaveOccurred())
+
+		out = generator.NewMemoryOutput()
+		err = New(Config{Config: generator.Config{Logger: logger, Output: out}, ProtoFile: "generated.proto"}).Generate(pkgs)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	readFile := func(pkgPath string) string {
+		Expect(out.Files).To(HaveKey(pkgPath + "/generated.proto"))
+		return string(out.Files[pkgPath+"/generated.proto"])
+	}
+
+	It("writes a proto file per package importing the packages it uses", func() {
+		Expect(out.Files).To(HaveLen(3))
+		apps := readFile("k8s.io/kubernetes/pkg/apis/apps/v1")
+		Expect(apps).To(ContainSubstring("syntax = \"proto2\";\n\npackage k8s.io.kubernetes.pkg.apis.apps.v1;\n"))
+		Expect(apps).To(ContainSubstring("import \"k8s.io/kubernetes/pkg/api/unversioned/generated.proto\";\nimport \"k8s.io/kubernetes/pkg/api/v1/generated.proto\";\n"))
+		Expect(apps).To(ContainSubstring(`option go_package = "k8s.io/kubernetes/pkg/apis/apps/v1";`))
+		Expect(apps).To(ContainSubstring("  optional .k8s.io.kubernetes.pkg.api.v1.PodSpec template = 3;\n"))
+	})
+
+	It("numbers fields from their protobuf tags", func() {
+		v1 := readFile("k8s.io/kubernetes/pkg/api/v1")
+		Expect(v1).To(ContainSubstring("message PodSpec {\n  repeated Container containers = 2;\n\n  optional string nodeName = 10;\n}"))
+		Expect(v1).To(ContainSubstring("  map<string, string> labels = 11;\n"))
+		Expect(v1).To(ContainSubstring("  repeated int32 ports = 3;\n"))
+		Expect(v1).To(ContainSubstring("  // Spec is the desired behavior of the pod.\n  optional PodSpec spec = 2;\n"))
+	})
+
+	It("writes Time as a well-known timestamp", func() {
+		v1 := readFile("k8s.io/kubernetes/pkg/api/v1")
+		Expect(v1).To(ContainSubstring(`import "google/protobuf/timestamp.proto";`))
+		Expect(v1).To(ContainSubstring("  optional .google.protobuf.Timestamp creationTimestamp = 8;\n"))
+	})
+
+	It("reports the types it cannot generate and generates the others", func() {
+		tags := func(tag string) loader.StructTags {
+			t, err := loader.ParseStructTags(tag)
+			Expect(err).NotTo(HaveOccurred())
+			return t
+		}
+		pkgs := []loader.Package{{
+			Path: "example.com/broken",
+			Types: []loader.Type{
+				{Name: "Broken", Fields: []loader.Field{
+					{Name: "Name", Type: types.Typ[types.String], Tags: tags(`protobuf:"bytes,1,opt,name=name"`)},
+					{Name: "Count", Type: types.Typ[types.Int32], Tags: tags(`protobuf:"varint,1,opt,name=count"`)},
+					{Name: "Events", Type: types.NewChan(types.SendRecv, types.Typ[types.String]), Tags: tags(`protobuf:"bytes,2,opt,name=events"`)},
+				}},
+				{Name: "Fine", Fields: []loader.Field{
+					{Name: "Name", Type: types.Typ[types.String], Tags: tags(`protobuf:"bytes,1,opt,name=name"`)},
+				}},
+			},
+		}}
+
+		logger := log15.New()
+		logger.SetHandler(log15.DiscardHandler())
+		broken := generator.NewMemoryOutput()
+		err := New(Config{Config: generator.Config{Logger: logger, Output: broken}, ProtoFile: "generated.proto"}).Generate(pkgs)
+
+		Expect(err).To(BeAssignableToTypeOf(generator.DiagnosticsError{}))
+		Expect(err.(generator.DiagnosticsError)).To(HaveLen(2))
+		Expect(err.Error()).To(ContainSubstring("fields Broken.Name and Broken.Count both use protobuf field number 1"))
+		Expect(err.Error()).To(ContainSubstring("field Broken.Events: unsupported type chan string"))
+
+		proto := string(broken.Files["example.com/broken/generated.proto"])
+		Expect(proto).To(ContainSubstring("message Fine {"))
+		Expect(proto).NotTo(ContainSubstring("message Broken {"))
+	})
+
+	It("leaves out fields without a protobuf tag", func() {
+		v1 := readFile("k8s.io/kubernetes/pkg/api/v1")
+		Expect(v1).To(ContainSubstring("message Pod {\n  optional ObjectMeta metadata = 1;\n"))
+		Expect(v1).NotTo(ContainSubstring("TypeMeta"))
+	})
+})
@@ -0,0 +1,207 @@
+package proto
+
+import (
+	"go/types"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
+)
+
+func knownTypes(pkgs []loader.Package) map[string]struct{} {
+	known := map[string]struct{}{}
+	for _, pkg := range pkgs {
+		for _, typ := range pkg.Types {
+			known[pkg.Path+"."+typ.Name] = struct{}{}
+		}
+	}
+	return known
+}
+
+var nonIdentifierRegexp = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// protoPackage returns the protobuf package for a Go package path, such as
+// k8s.io.api.core.v1 for k8s.io/api/core/v1.
+func protoPackage(pkgPath string) string {
+	parts := strings.FieldsFunc(pkgPath, func(r rune) bool { return r == '/' || r == '.' })
+	for i, part := range parts {
+		part = nonIdentifierRegexp.ReplaceAllString(part, "_")
+		if part[0] >= '0' && part[0] <= '9' {
+			part = "_" + part
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, ".")
+}
+
+// importSet tracks the files a proto file imports.
+type importSet struct {
+	pkgPath   string
+	protoFile string
+	imports   map[string]struct{}
+}
+
+func newImportSet(pkgPath, protoFile string) *importSet {
+	return &importSet{pkgPath: pkgPath, protoFile: protoFile, imports: map[string]struct{}{}}
+}
+
+// message returns the reference to a message in pkgPath, importing its file
+// when it is in another package.
+func (s *importSet) message(pkgPath, name string) string {
+	if pkgPath == s.pkgPath {
+		return name
+	}
+	s.imports[pkgPath+"/"+s.protoFile] = struct{}{}
+	return "." + protoPackage(pkgPath) + "." + name
+}
+
+func (s *importSet) list() []string {
+	imports := make([]string, 0, len(s.imports))
+	for imp := range s.imports {
+		imports = append(imports, imp)
+	}
+	return imports
+}
+
+type protobufTag struct {
+	WireType string
+	Number   int
+	Name     string
+}
+
+// parseProtobufTag parses a tag such as bytes,1,opt,name=metadata.
+func parseProtobufTag(tag string) (protobufTag, error) {
+	parts := strings.Split(tag, ",")
+	if len(parts) < 2 {
+		return protobufTag{}, errors.Errorf("malformed protobuf tag %q", tag)
+	}
+	number, err := strconv.Atoi(parts[1])
+	if err != nil || number < 1 {
+		return protobufTag{}, errors.Errorf("invalid field number in protobuf tag %q", tag)
+	}
+	t := protobufTag{WireType: parts[0], Number: number}
+	for _, part := range parts[2:] {
+		if strings.HasPrefix(part, "name=") {
+			t.Name = strings.TrimPrefix(part, "name=")
+		}
+	}
+	return t, nil
+}
+
+// defaultTypeMappings are the protobuf types for Go types that are not
+// generated as messages.
+var defaultTypeMappings = typemap.Table{
+	// Timestamp has the same wire format as Kubernetes' own Time message.
+	"k8s.io/kubernetes/pkg/api/unversioned.Time": {
+		Type:    ".google.protobuf.Timestamp",
+		Imports: []string{"google/protobuf/timestamp.proto"},
+	},
+}
+
+func isBytes(typ types.Type) bool {
+	slice, ok := typ.Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+	basic, ok := slice.Elem().Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.Byte
+}
+
+// fieldType returns the label and type of the protobuf field for a Go type.
+func fieldType(typ types.Type, known map[string]struct{}, mappings typemap.Table, imports *importSet) (string, string, error) {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+
+	if _, mapped := mappings.Lookup(typ); !mapped && !isBytes(typ) {
+		switch t := typ.Underlying().(type) {
+		case *types.Slice:
+			elemType, err := protoType(t.Elem(), known, mappings, imports)
+			return "repeated", elemType, err
+		case *types.Array:
+			elemType, err := protoType(t.Elem(), known, mappings, imports)
+			return "repeated", elemType, err
+		case *types.Map:
+			key, ok := t.Key().Underlying().(*types.Basic)
+			if !ok || key.Info()&(types.IsInteger|types.IsString|types.IsBoolean) == 0 {
+				return "", "", errors.Errorf("unsupported map key type %s", t.Key().String())
+			}
+			keyType, err := scalarType(key.Kind())
+			if err != nil {
+				return "", "", err
+			}
+			valueType, err := protoType(t.Elem(), known, mappings, imports)
+			if err != nil {
+				return "", "", err
+			}
+			return "", "map<" + keyType + ", " + valueType + ">", nil
+		}
+	}
+
+	protoType, err := protoType(typ, known, mappings, imports)
+	return "optional", protoType, err
+}
+
+func protoType(typ types.Type, known map[string]struct{}, mappings typemap.Table, imports *importSet) (string, error) {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+
+	if m, ok := mappings.Lookup(typ); ok {
+		for _, imp := range m.Imports {
+			imports.imports[imp] = struct{}{}
+		}
+		return m.Type, nil
+	}
+
+	if isBytes(typ) {
+		return "bytes", nil
+	}
+
+	if named, ok := typ.(*types.Named); ok && named.Obj().Pkg() != nil {
+		pkgPath := loader.StripVendor(named.Obj().Pkg().Path())
+		if _, ok := known[pkgPath+"."+named.Obj().Name()]; ok {
+			return imports.message(pkgPath, named.Obj().Name()), nil
+		}
+	}
+
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		return scalarType(t.Kind())
+	default:
+		return "", errors.Errorf("unsupported type %s", typ.String())
+	}
+}
+
+func scalarType(kind types.BasicKind) (string, error) {
+	switch kind {
+	case types.Bool:
+		return "bool", nil
+	case types.Int8, types.Int16, types.Int32:
+		return "int32", nil
+	case types.Int, types.Int64:
+		return "int64", nil
+	case types.Uint8, types.Uint16, types.Uint32:
+		return "uint32", nil
+	case types.Uint, types.Uint64:
+		return "uint64", nil
+	case types.Float32:
+		return "float", nil
+	case types.Float64:
+		return "double", nil
+	case types.String:
+		return "string", nil
+	default:
+		return "", errors.Errorf("unknown basic type %d", kind)
+	}
+}
+
+var startOfLineRegexp = regexp.MustCompile(`(?m:^)`)
+
+func comment(doc string, indent string) string {
+	return startOfLineRegexp.ReplaceAllString(doc, indent+"// ")
+}
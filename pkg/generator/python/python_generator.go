@@ -0,0 +1,343 @@
+package python
+
+import (
+	"go/token"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/generator/typeref"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
+)
+
+const (
+	StylePydantic  = "pydantic"
+	StyleDataclass = "dataclass"
+)
+
+const moduleTemplateText = `{{if .Doc}}{{docstring .Doc ""}}
+
+{{end}}from __future__ import annotations
+
+import datetime
+import enum
+from typing import Any, Dict, List, Literal, Optional, Union
+{{if eq .Style "pydantic"}}
+from pydantic import BaseModel, ConfigDict, Field
+{{else}}
+from dataclasses import dataclass, field
+{{end}}{{range .ImportStatements}}
+{{.}}{{end}}{{range .Imports}}
+import {{.Module}} as {{.Alias}}{{end}}
+{{range .Enums}}
+
+class {{.Name}}({{.Base}}, enum.Enum):{{if .Doc}}
+{{docstring .Doc "    "}}
+{{end}}{{range .Values}}
+    {{.Name}} = {{.Value}}{{if .Doc}}
+{{docstring .Doc "    "}}{{end}}{{end}}
+{{end}}{{$style := .Style}}{{range .Classes}}
+{{if eq $style "dataclass"}}
+@dataclass(kw_only=True){{else}}
+{{end}}
+class {{.Name}}({{join .Bases ", "}}):{{if .Doc}}
+{{docstring .Doc "    "}}
+{{end}}{{if eq $style "pydantic"}}
+    model_config = ConfigDict(populate_by_name=True, use_attribute_docstrings=True)
+{{end}}{{range .Attributes}}
+    {{.Name}}: {{if .Optional}}Optional[{{.Type}}]{{else}}{{.Type}}{{end}} = {{if eq $style "pydantic"}}Field({{if .Optional}}default={{.Default}}, {{end}}alias="{{.Alias}}"){{else}}field({{if .Optional}}default={{.Default}}, {{end}}metadata={"alias": "{{.Alias}}"}){{end}}{{if .Doc}}
+{{docstring .Doc "    "}}{{end}}{{end}}{{if not .Attributes}}{{if not .Doc}}
+    pass{{end}}{{end}}
+{{end}}`
+
+const initTemplateText = `{{if .Doc}}{{docstring .Doc ""}}
+
+{{end}}from .models import *  # noqa: F401,F403
+`
+
+var funcs = template.FuncMap{
+	"docstring": docstring,
+	"join":      strings.Join,
+}
+
+// blankLinesRegexp matches runs of more than two blank lines left behind by the
+// templates, which are collapsed to the two PEP 8 asks for.
+var blankLinesRegexp = regexp.MustCompile(`\n{4,}`)
+
+var (
+	moduleTemplate = template.Must(template.New("module").Funcs(funcs).Parse(moduleTemplateText))
+	initTemplate   = template.Must(template.New("init").Funcs(funcs).Parse(initTemplateText))
+)
+
+func New(c Config) generator.Generator {
+	c.Logger.Debug("creating generator", "type", "python")
+	return &pythonGenerator{
+		config: c,
+	}
+}
+
+type Config struct {
+	generator.Config
+
+	RootPackage string
+	Style       string
+}
+
+type pythonGenerator struct {
+	config   Config
+	written  map[string]struct{}
+	mappings typemap.Table
+	diags    generator.Diagnostics
+}
+
+var _ generator.Generator = &pythonGenerator{}
+
+type module struct {
+	Doc     string
+	Style   string
+	Imports []typeref.Import
+	// ImportStatements are the imports of mapped types.
+	ImportStatements []string
+	Enums            []enum
+	Classes          []class
+}
+
+type enum struct {
+	Name   string
+	Doc    string
+	Base   string
+	Values []enumValue
+}
+
+type enumValue struct {
+	Name  string
+	Value string
+	Doc   string
+}
+
+type class struct {
+	Name       string
+	Doc        string
+	Bases      []string
+	Attributes []attribute
+}
+
+type attribute struct {
+	Name     string
+	Alias    string
+	Doc      string
+	Type     string
+	Optional bool
+	Default  string
+}
+
+func (g *pythonGenerator) Generate(pkgs []loader.Package) error {
+	g.config.Logger.Debug("generating")
+
+	if g.config.Style != StylePydantic && g.config.Style != StyleDataclass {
+		return errors.Errorf("unknown python style %s", g.config.Style)
+	}
+
+	g.written = map[string]struct{}{}
+	g.diags = generator.Diagnostics{}
+	modules := moduleNames(g.config.RootPackage, pkgs, &g.diags)
+	known := typeref.KnownTypes(pkgs)
+	g.mappings = typemap.Merge(defaultTypeMappings, g.config.TypeMappings["python"])
+
+	if err := g.writeInits(strings.Replace(g.config.RootPackage, ".", "/", -1)); err != nil {
+		return err
+	}
+
+	for _, pkg := range pkgs {
+		moduleName, ok := modules[pkg.Path]
+		if !ok {
+			continue
+		}
+		pkgDir := strings.Replace(moduleName, ".", "/", -1)
+		g.config.Logger.Debug("generating for package", "package", pkg.Path, "module", moduleName, "dir", pkgDir)
+
+		m := g.module(pkg, modules, known)
+
+		if err := g.writeInits(path.Dir(pkgDir)); err != nil {
+			return err
+		}
+		if err := g.writeTemplate(pkg, path.Join(pkgDir, "__init__.py"), initTemplate, m); err != nil {
+			return err
+		}
+		if err := g.writeTemplate(pkg, path.Join(pkgDir, "models.py"), moduleTemplate, m); err != nil {
+			return err
+		}
+	}
+
+	return g.diags.Err()
+}
+
+func (g *pythonGenerator) writeTemplate(pkg loader.Package, name string, tmpl *template.Template, data interface{}) error {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return errors.Wrapf(err, "failed to render file %s", name)
+	}
+	contents := []byte(blankLinesRegexp.ReplaceAllString(buf.String(), "\n\n\n"))
+	return g.writeFile(name, g.config.WithHeader(generator.HashComment, pkg.Path, pkg.APIVersion(), contents))
+}
+
+// writeInits writes an empty __init__.py to dir and to every directory above
+// it, so that each level of a dotted package such as io.k8s is a package.
+func (g *pythonGenerator) writeInits(dir string) error {
+	for ; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if err := g.writeFile(path.Join(dir, "__init__.py"), g.config.WithHeader(generator.HashComment, "", "", nil)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile writes a file once per run, so shared package files such as a
+// group's __init__.py are not rejected as existing when not forcing.
+func (g *pythonGenerator) writeFile(name string, contents []byte) error {
+	if _, ok := g.written[name]; ok {
+		return nil
+	}
+	g.written[name] = struct{}{}
+
+	return g.config.Output.WriteFile(name, contents)
+}
+
+// module builds the module for a package, recording problems with types and
+// enums and leaving out those that have any.
+func (g *pythonGenerator) module(pkg loader.Package, modules map[string]string, known map[string]struct{}) *module {
+	imports := typeref.NewImportSet(pkg.Path, moduleImport(modules))
+
+	m := &module{Doc: pkg.Doc, Style: g.config.Style}
+
+	for _, e := range pkg.Enums {
+		base, err := pythonType(e.Type, known, g.mappings, imports)
+		if err != nil {
+			g.diags.Addf(token.Position{}, "enum %s.%s: %v", pkg.Path, e.Name, err)
+			continue
+		}
+		pe := enum{Name: e.Name, Doc: e.Doc, Base: base}
+		for _, v := range e.Values {
+			pe.Values = append(pe.Values, enumValue{Name: v.Name, Value: literal(e.Type, v.Value), Doc: v.Doc})
+		}
+		m.Enums = append(m.Enums, pe)
+	}
+
+	for _, typ := range pkg.Types {
+		if g.mappings.Maps(pkg.Path, typ.Name) {
+			continue
+		}
+		c := class{Name: typ.Name, Doc: typ.Doc}
+		problems := g.diags.Len()
+		attributes := map[string]string{}
+
+		if typ.Kind != "" {
+			c.Attributes = append(c.Attributes,
+				attribute{Name: "api_version", Alias: "apiVersion", Type: "Literal[" + quote(typ.APIVersion()) + "]", Optional: true, Default: quote(typ.APIVersion())},
+				attribute{Name: "kind", Alias: "kind", Type: "Literal[" + quote(typ.Kind) + "]", Optional: true, Default: quote(typ.Kind)},
+			)
+		}
+
+		for _, fld := range typ.Fields {
+			embedded := fld.Anonymous && fld.JSONProperty == ""
+			if embedded && typeref.TypeName(fld.Type) == "TypeMeta" {
+				if typ.Kind == "" {
+					// Types without a registered kind still have an apiVersion
+					// and kind, only not known ones.
+					c.Attributes = append(c.Attributes,
+						attribute{Name: "api_version", Alias: "apiVersion", Type: "str", Optional: true, Default: "None"},
+						attribute{Name: "kind", Alias: "kind", Type: "str", Optional: true, Default: "None"},
+					)
+				}
+				continue
+			}
+
+			pyType, err := pythonType(fld.Type, known, g.mappings, imports)
+			if err != nil {
+				g.diags.Addf(fld.Position, "field %s.%s: %v", typ.Name, fld.Name, err)
+				continue
+			}
+
+			if embedded {
+				if _, ok := known[typeref.QualifiedName(fld.Type)]; ok {
+					c.Bases = append(c.Bases, pyType)
+				} else {
+					g.config.Logger.Debug("ignoring embedded field of unknown type", "type", typ.Name, "field", fld.Name)
+				}
+				continue
+			}
+
+			alias := fld.JSONProperty
+			if alias == "" {
+				alias = fld.Name
+			}
+			name := attributeName(alias)
+			if other, ok := attributes[name]; ok {
+				g.diags.Addf(fld.Position, "field %s.%s maps to attribute %s, as does field %s", typ.Name, fld.Name, name, other)
+				continue
+			}
+			attributes[name] = fld.Name
+			c.Attributes = append(c.Attributes, attribute{
+				Name:     name,
+				Alias:    alias,
+				Doc:      fld.Doc,
+				Type:     pyType,
+				Optional: !fld.JSONRequired && !fld.Markers.Required(),
+				Default:  "None",
+			})
+		}
+
+		if len(c.Bases) == 0 {
+			if g.config.Style == StylePydantic {
+				c.Bases = []string{"BaseModel"}
+			} else {
+				c.Bases = []string{"object"}
+			}
+		}
+
+		if g.diags.Len() > problems {
+			continue
+		}
+		m.Classes = append(m.Classes, c)
+	}
+
+	m.Classes = sortByBases(m.Classes)
+	m.Imports = imports.List()
+	m.ImportStatements = imports.Statements()
+
+	return m
+}
+
+// sortByBases orders classes so that base classes defined in the same module
+// come before the classes extending them, keeping declaration order otherwise.
+func sortByBases(classes []class) []class {
+	byName := make(map[string]class, len(classes))
+	for _, c := range classes {
+		byName[c.Name] = c
+	}
+
+	sorted := make([]class, 0, len(classes))
+	visited := map[string]bool{}
+	var visit func(c class)
+	visit = func(c class) {
+		if visited[c.Name] {
+			return
+		}
+		visited[c.Name] = true
+		for _, base := range c.Bases {
+			if b, ok := byName[base]; ok {
+				visit(b)
+			}
+		}
+		sorted = append(sorted, c)
+	}
+	for _, c := range classes {
+		visit(c)
+	}
+	return sorted
+}
@@ -1,4 +1,4 @@
-package nested_test
+package python_test
 
 import (
 	. "github.com/onsi/ginkgo"
@@ -7,7 +7,7 @@ import (
 	"testing"
 )
 
-func TestNested(t *testing.T) {
+func TestPython(t *testing.T) {
 	RegisterFailHandler(Fail)
-	RunSpecs(t, "Nested Suite")
+	RunSpecs(t, "Python Suite")
 }
@@ -0,0 +1,139 @@
+package python_test
+
+import (
+	"go/types"
+
+	"github.com/inconshreveable/log15"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	. "github.com/jimmidyson/kube-client-gen/pkg/generator/python"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+var _ = Describe("Python", func() {
+	var (
+		logger log15.Logger
+		out    *generator.MemoryOutput
+	)
+
+	BeforeEach(func() {
+		logger = log15.New()
+		logger.SetHandler(log15.DiscardHandler())
+
+		out = generator.NewMemoryOutput()
+	})
+
+	load := func() []loader.Package {
+		pkgs, err := loader.New([]string{
+			"k8s.io/kubernetes/pkg/api/unversioned",
+			"k8s.io/kubernetes/pkg/api/v1",
+			"k8s.io/kubernetes/pkg/apis/apps/v1",
+		}, logger, loader.WithDir("../testdata/module")).Load()
+		Expect(err).NotTo(HaveOccurred())
+		return pkgs
+	}
+
+	generate := func(style string) error {
+		return New(Config{Config: generator.Config{Logger: logger, Output: out}, RootPackage: "kubernetes_models", Style: style}).Generate(load())
+	}
+
+	readFile := func(name string) string {
+		Expect(out.Files).To(HaveKey("kubernetes_models/" + name))
+		return string(out.Files["kubernetes_models/"+name])
+	}
+
+	It("writes a models module per package with its packages", func() {
+		Expect(generate(StylePydantic)).To(Succeed())
+		for _, name := range []string{"__init__.py", "core/__init__.py", "core/v1/__init__.py", "apps/__init__.py", "apps/v1/__init__.py"} {
+			Expect(out.Files).To(HaveKey("kubernetes_models/" + name))
+		}
+		Expect(readFile("core/v1/__init__.py")).To(ContainSubstring("from .models import *"))
+
+		apps := readFile("apps/v1/models.py")
+		Expect(apps).To(ContainSubstring("import kubernetes_models.core.v1.models as kubernetes_models_core_v1"))
+		Expect(apps).To(ContainSubstring(`template: kubernetes_models_core_v1.PodSpec = Field(alias="template")`))
+	})
+
+	It("writes a package for each level of a dotted root package", func() {
+		Expect(New(Config{Config: generator.Config{Logger: logger, Output: out}, RootPackage: "io.k8s", Style: StylePydantic}).Generate(load())).To(Succeed())
+		Expect(out.Files).To(HaveKey("io/__init__.py"))
+		Expect(out.Files).To(HaveKey("io/k8s/__init__.py"))
+		Expect(out.Files).To(HaveKey("io/k8s/apps/__init__.py"))
+		Expect(out.Files).To(HaveKey("io/k8s/apps/v1/models.py"))
+		Expect(out.Files).NotTo(HaveKey(HavePrefix("io.k8s/")))
+		Expect(string(out.Files["io/k8s/apps/v1/models.py"])).To(ContainSubstring("import io.k8s.core.v1.models as io_k8s_core_v1"))
+	})
+
+	It("writes pydantic models with aliases for the JSON names", func() {
+		Expect(generate(StylePydantic)).To(Succeed())
+		v1 := readFile("core/v1/models.py")
+		Expect(v1).To(ContainSubstring("from pydantic import BaseModel, ConfigDict, Field"))
+		Expect(v1).To(ContainSubstring("class ObjectMeta(BaseModel):"))
+		Expect(v1).To(ContainSubstring("    model_config = ConfigDict(populate_by_name=True, use_attribute_docstrings=True)"))
+		Expect(v1).To(ContainSubstring(`    resource_version: Optional[str] = Field(default=None, alias="resourceVersion")`))
+		Expect(v1).To(ContainSubstring(`    containers: List[Container] = Field(alias="containers")`))
+	})
+
+	It("writes enums and literal apiVersion and kind attributes", func() {
+		Expect(generate(StylePydantic)).To(Succeed())
+		v1 := readFile("core/v1/models.py")
+		Expect(v1).To(ContainSubstring("class PodPhase(str, enum.Enum):"))
+		Expect(v1).To(ContainSubstring(`    PodPending = "Pending"`))
+		Expect(v1).To(ContainSubstring(`    phase: Optional[PodPhase] = Field(default=None, alias="phase")`))
+		Expect(v1).To(ContainSubstring(`    api_version: Optional[Literal["v1"]] = Field(default="v1", alias="apiVersion")`))
+		Expect(v1).To(ContainSubstring(`    kind: Optional[Literal["Pod"]] = Field(default="Pod", alias="kind")`))
+	})
+
+	It("keeps the apiVersion and kind of classes without a registered kind as strings", func() {
+		Expect(generate(StylePydantic)).To(Succeed())
+		v1 := readFile("core/v1/models.py")
+		Expect(v1).To(ContainSubstring("class PodLogOptions(BaseModel):"))
+		Expect(v1).To(ContainSubstring(`    api_version: Optional[str] = Field(default=None, alias="apiVersion")
+    kind: Optional[str] = Field(default=None, alias="kind")
+    container: Optional[str] = Field(default=None, alias="container")`))
+	})
+
+	It("writes dataclasses", func() {
+		Expect(generate(StyleDataclass)).To(Succeed())
+		v1 := readFile("core/v1/models.py")
+		Expect(v1).To(ContainSubstring("from dataclasses import dataclass, field"))
+		Expect(v1).To(ContainSubstring("@dataclass(kw_only=True)\nclass ObjectMeta(object):"))
+		Expect(v1).To(ContainSubstring(`    resource_version: Optional[str] = field(default=None, metadata={"alias": "resourceVersion"})`))
+	})
+
+	DescribeTable("names attributes of properties that are not identifiers with a trailing underscore",
+		func(style, ref, thirdParty string) {
+			pkgs := []loader.Package{{
+				Path:    "example.com/schema/v1",
+				Group:   "schema.example.com",
+				Version: "v1",
+				Types: []loader.Type{
+					{Name: "JSONSchemaProps", Fields: []loader.Field{
+						{Name: "Ref", JSONProperty: "$ref", Type: types.NewPointer(types.Typ[types.String])},
+						{Name: "ThirdParty", JSONProperty: "3rdParty", Type: types.Typ[types.Bool]},
+					}},
+				},
+			}}
+			Expect(New(Config{Config: generator.Config{Logger: logger, Output: out}, RootPackage: "kubernetes_models", Style: style}).Generate(pkgs)).To(Succeed())
+
+			models := readFile("schema_example_com/v1/models.py")
+			Expect(models).To(ContainSubstring(ref))
+			Expect(models).To(ContainSubstring(thirdParty))
+			Expect(models).NotTo(MatchRegexp(`(?m)^    _`))
+		},
+		Entry("pydantic", StylePydantic,
+			`    ref_: Optional[str] = Field(default=None, alias="$ref")`,
+			`    field_3rd_party: Optional[bool] = Field(default=None, alias="3rdParty")`),
+		Entry("dataclass", StyleDataclass,
+			`    ref_: Optional[str] = field(default=None, metadata={"alias": "$ref"})`,
+			`    field_3rd_party: Optional[bool] = field(default=None, metadata={"alias": "3rdParty"})`),
+	)
+
+	It("rejects unknown styles", func() {
+		Expect(generate("attrs")).To(MatchError("unknown python style attrs"))
+	})
+})
@@ -0,0 +1,209 @@
+package python
+
+import (
+	"go/token"
+	"go/types"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/generator/typeref"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
+)
+
+// moduleNames maps each package path to the dotted name of its Python package,
+// <root>.<group>.<version>, recording a problem and leaving out packages that
+// share a group/version with an earlier one.
+func moduleNames(rootPackage string, pkgs []loader.Package, diags *generator.Diagnostics) map[string]string {
+	modules := map[string]string{}
+	owners := map[string]string{}
+	for _, pkg := range pkgs {
+		group, version := pkg.Group, pkg.Version
+		if version == "" {
+			// Packages that do not register a version are named after the
+			// package instead.
+			version = path.Base(pkg.Path)
+		}
+		if group == "" {
+			group = "core"
+		}
+		name := rootPackage + "." + identifier(group) + "." + identifier(version)
+		if owner, ok := owners[name]; ok {
+			pos := token.Position{}
+			if len(pkg.Types) > 0 {
+				pos = pkg.Types[0].Position
+			}
+			diags.Addf(pos, "packages %s and %s both map to python package %s", owner, pkg.Path, name)
+			continue
+		}
+		owners[name] = pkg.Path
+		modules[pkg.Path] = name
+	}
+	return modules
+}
+
+var nonIdentifierRegexp = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func identifier(s string) string {
+	s = nonIdentifierRegexp.ReplaceAllString(s, "_")
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "_" + s
+	}
+	return s
+}
+
+// moduleImport returns how modules import the models module of a package,
+// given the Python package of each Go package: under an alias made of the
+// dotted name of its package.
+func moduleImport(modules map[string]string) func(pkgPath string) typeref.Import {
+	return func(pkgPath string) typeref.Import {
+		module := modules[pkgPath]
+		return typeref.Import{
+			Module: module + ".models",
+			Alias:  strings.Replace(module, ".", "_", -1),
+		}
+	}
+}
+
+// defaultTypeMappings are the Python types for Go types that are not
+// generated as classes.
+var defaultTypeMappings = typemap.Table{
+	"k8s.io/kubernetes/pkg/api/unversioned.Time":    {Type: "datetime.datetime"},
+	"k8s.io/kubernetes/pkg/util/intstr.IntOrString": {Type: "Union[int, str]"},
+	"k8s.io/kubernetes/pkg/runtime.RawExtension":    {Type: "Dict[str, Any]"},
+}
+
+func pythonType(typ types.Type, known map[string]struct{}, mappings typemap.Table, imports *typeref.ImportSet) (string, error) {
+	if m, ok := mappings.Lookup(typ); ok {
+		imports.AddStatements(m.Imports)
+		return m.Type, nil
+	}
+	if ref, ok := imports.Reference(typ, known); ok {
+		return ref, nil
+	}
+	if loader.Dynamic(typ) != loader.NotDynamic {
+		return "Any", nil
+	}
+
+	switch fldT := typ.Underlying().(type) {
+	case *types.Slice:
+		if basic, ok := fldT.Elem().Underlying().(*types.Basic); ok && basic.Kind() == types.Byte {
+			return "str", nil
+		}
+		elemType, err := pythonType(fldT.Elem(), known, mappings, imports)
+		if err != nil {
+			return "", err
+		}
+		return "List[" + elemType + "]", nil
+	case *types.Array:
+		elemType, err := pythonType(fldT.Elem(), known, mappings, imports)
+		if err != nil {
+			return "", err
+		}
+		return "List[" + elemType + "]", nil
+	case *types.Map:
+		elemType, err := pythonType(fldT.Elem(), known, mappings, imports)
+		if err != nil {
+			return "", err
+		}
+		return "Dict[str, " + elemType + "]", nil
+	case *types.Struct:
+		return "Dict[str, Any]", nil
+	case *types.Pointer:
+		return pythonType(fldT.Elem(), known, mappings, imports)
+	case *types.Interface:
+		return "Any", nil
+	case *types.Basic:
+		return pythonTypeBasic(fldT.Kind())
+	default:
+		return "", errors.Errorf("unknown field type %s", fldT.String())
+	}
+}
+
+func pythonTypeBasic(kind types.BasicKind) (string, error) {
+	switch kind {
+	case types.Bool:
+		return "bool", nil
+	case types.Int, types.Int8, types.Int16, types.Int32, types.Int64,
+		types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+		return "int", nil
+	case types.Float32, types.Float64:
+		return "float", nil
+	case types.String:
+		return "str", nil
+	default:
+		return "", errors.Errorf("unknown basic type %d", kind)
+	}
+}
+
+// literal returns the Python literal for an enum value.
+func literal(typ types.Type, value string) string {
+	if basic, ok := typ.(*types.Basic); ok && basic.Info()&types.IsString != 0 {
+		return quote(value)
+	}
+	return value
+}
+
+func quote(s string) string {
+	return strconv.Quote(s)
+}
+
+var keywords = map[string]struct{}{
+	"False": {}, "None": {}, "True": {}, "and": {}, "as": {}, "assert": {}, "async": {},
+	"await": {}, "break": {}, "class": {}, "continue": {}, "def": {}, "del": {}, "elif": {},
+	"else": {}, "except": {}, "finally": {}, "for": {}, "from": {}, "global": {}, "if": {},
+	"import": {}, "in": {}, "is": {}, "lambda": {}, "nonlocal": {}, "not": {}, "or": {},
+	"pass": {}, "raise": {}, "return": {}, "try": {}, "while": {}, "with": {}, "yield": {},
+	// Names that would shadow the generated model's own machinery.
+	"model_config": {}, "field": {},
+}
+
+// attributeName converts a JSON property such as apiVersion into a snake_case
+// Python attribute such as api_version.
+func attributeName(property string) string {
+	var b strings.Builder
+	runes := []rune(property)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	name := nonIdentifierRegexp.ReplaceAllString(b.String(), "_")
+	// Pydantic takes attributes with a leading underscore for private ones, so
+	// properties such as $ref lose their leading characters and are marked
+	// with a trailing underscore instead, as keywords are. Properties starting
+	// with a digit get a prefix, as a trailing underscore cannot help them.
+	if trimmed := strings.TrimLeft(name, "_"); trimmed != name {
+		name = trimmed + "_"
+	}
+	if name == "" || name == "_" || unicode.IsDigit(rune(name[0])) {
+		return "field_" + strings.TrimSuffix(name, "_")
+	}
+	if _, ok := keywords[name]; ok {
+		name += "_"
+	}
+	return name
+}
+
+var startOfLineRegexp = regexp.MustCompile(`(?m:^)`)
+
+func docstring(doc string, indent string) string {
+	doc = strings.Replace(doc, `\`, `\\`, -1)
+	doc = strings.Replace(doc, `"""`, `\"\"\"`, -1)
+	if !strings.Contains(doc, "\n") {
+		return indent + `"""` + doc + `"""`
+	}
+	return indent + `"""` + startOfLineRegexp.ReplaceAllString(doc, indent)[len(indent):] + "\n" + indent + `"""`
+}
@@ -0,0 +1,281 @@
+package schema
+
+import (
+	"encoding/json"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
+)
+
+// Schema is the subset of JSON Schema shared by draft-07 JSON Schema and
+// OpenAPI 3.0 schema objects.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	MaxLength            *int64             `json:"maxLength,omitempty"`
+	MinLength            *int64             `json:"minLength,omitempty"`
+	MaxItems             *int64             `json:"maxItems,omitempty"`
+	MinItems             *int64             `json:"minItems,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	UniqueItems          bool               `json:"uniqueItems,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+
+	GroupVersionKind []GroupVersionKind `json:"x-kubernetes-group-version-kind,omitempty"`
+}
+
+type GroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// DefaultTypeMappings are the schemas for Go types that are not generated as
+// definitions.
+var DefaultTypeMappings = typemap.Table{
+	"k8s.io/kubernetes/pkg/api/unversioned.Time":    {Type: "string", Format: "date-time"},
+	"k8s.io/kubernetes/pkg/util/intstr.IntOrString": {Schema: json.RawMessage(`{"oneOf":[{"type":"integer"},{"type":"string"}]}`)},
+	"k8s.io/kubernetes/pkg/runtime.RawExtension":    {Type: "object"},
+}
+
+// Converter converts loaded types to schemas, referring to other loaded
+// types and enums with refPrefix followed by their definition name.
+type Converter struct {
+	refPrefix string
+	known     map[string]struct{}
+	mappings  typemap.Table
+}
+
+func NewConverter(refPrefix string, pkgs []loader.Package, mappings typemap.Table) *Converter {
+	known := map[string]struct{}{}
+	for _, pkg := range pkgs {
+		for _, typ := range pkg.Types {
+			known[DefinitionName(pkg.Path, typ.Name)] = struct{}{}
+		}
+		for _, enum := range pkg.Enums {
+			known[DefinitionName(pkg.Path, enum.Name)] = struct{}{}
+		}
+	}
+	return &Converter{refPrefix: refPrefix, known: known, mappings: mappings}
+}
+
+// Maps reports whether the type typeName of pkgPath has a type mapping,
+// which is used instead of generating a definition for it.
+func (c *Converter) Maps(pkgPath, typeName string) bool {
+	return c.mappings.Maps(pkgPath, typeName)
+}
+
+func DefinitionName(pkgPath, typeName string) string {
+	return strings.Replace(pkgPath, "/", ".", -1) + "." + typeName
+}
+
+func (c *Converter) Ref(pkgPath, typeName string) string {
+	return c.refPrefix + DefinitionName(pkgPath, typeName)
+}
+
+// TypeSchema returns the schema of a type, or nil if any of its fields has a
+// type without a schema, which is recorded in diags.
+func (c *Converter) TypeSchema(typ loader.Type, diags *generator.Diagnostics) *Schema {
+	s := &Schema{
+		Type:        "object",
+		Description: typ.Doc,
+		Properties:  map[string]*Schema{},
+	}
+	problems := diags.Len()
+
+	for _, fld := range typ.Fields {
+		fldSchema, err := c.FieldSchema(fld.Type)
+		if err != nil {
+			diags.Addf(fld.Position, "field %s.%s: %v", typ.Name, fld.Name, err)
+			continue
+		}
+
+		property := fld.JSONProperty
+		if property == "" {
+			if fld.Anonymous {
+				s.AllOf = append(s.AllOf, fldSchema)
+				continue
+			}
+			property = fld.Name
+		}
+
+		if fldSchema.Ref == "" {
+			fldSchema.Description = fld.Doc
+			applyValidation(fldSchema, fld.Markers)
+		} else {
+			// Keywords next to $ref are ignored, so the reference is wrapped
+			// to keep the description and validation.
+			wrapper := &Schema{Description: fld.Doc}
+			applyValidation(wrapper, fld.Markers)
+			if !reflect.DeepEqual(wrapper, &Schema{}) {
+				wrapper.AllOf = []*Schema{fldSchema}
+				fldSchema = wrapper
+			}
+		}
+		s.Properties[property] = fldSchema
+		if fld.JSONRequired || fld.Markers.Required() {
+			s.Required = append(s.Required, property)
+		}
+	}
+
+	if diags.Len() > problems {
+		return nil
+	}
+	return s
+}
+
+// EnumSchema returns the schema of an enum, or nil if its type has no schema,
+// which is recorded in diags.
+func (c *Converter) EnumSchema(pkgPath string, enum loader.Enum, diags *generator.Diagnostics) *Schema {
+	s, err := c.FieldSchema(enum.Type)
+	if err != nil {
+		diags.Addf(token.Position{}, "enum %s.%s: %v", pkgPath, enum.Name, err)
+		return nil
+	}
+	s.Description = enum.Doc
+	for _, v := range enum.Values {
+		if s.Type == "string" {
+			s.Enum = append(s.Enum, v.Value)
+		} else {
+			s.Enum = append(s.Enum, json.Number(v.Value))
+		}
+	}
+	return s
+}
+
+func applyValidation(s *Schema, markers loader.Markers) {
+	v := markers.Validation()
+	s.MaxLength, s.MinLength = v.MaxLength, v.MinLength
+	s.MaxItems, s.MinItems = v.MaxItems, v.MinItems
+	if v.Maximum != nil {
+		s.Maximum = v.Maximum
+	}
+	if v.Minimum != nil {
+		s.Minimum = v.Minimum
+	}
+	s.Pattern = v.Pattern
+	s.UniqueItems = v.UniqueItems
+	if v.Format != "" {
+		s.Format = v.Format
+	}
+	for _, e := range v.Enum {
+		s.Enum = append(s.Enum, e)
+	}
+	if def, ok := markers.Default(); ok {
+		if json.Valid([]byte(def)) {
+			s.Default = json.RawMessage(def)
+		} else {
+			s.Default = def
+		}
+	}
+}
+
+func (c *Converter) FieldSchema(typ types.Type) (*Schema, error) {
+	if m, ok := c.mappings.Lookup(typ); ok {
+		return mappedSchema(m)
+	}
+	if named, ok := typ.(*types.Named); ok && named.Obj().Pkg() != nil {
+		pkgPath := loader.StripVendor(named.Obj().Pkg().Path())
+		if _, ok := c.known[DefinitionName(pkgPath, named.Obj().Name())]; ok {
+			return &Schema{Ref: c.Ref(pkgPath, named.Obj().Name())}, nil
+		}
+	}
+	switch loader.Dynamic(typ) {
+	case loader.ArbitraryJSON:
+		return &Schema{}, nil
+	case loader.EmbeddedObject:
+		return &Schema{Type: "object"}, nil
+	}
+
+	switch fldT := typ.Underlying().(type) {
+	case *types.Slice:
+		if basic, ok := fldT.Elem().Underlying().(*types.Basic); ok && basic.Kind() == types.Byte {
+			return &Schema{Type: "string", Format: "byte"}, nil
+		}
+		elemSchema, err := c.FieldSchema(fldT.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: elemSchema}, nil
+	case *types.Array:
+		elemSchema, err := c.FieldSchema(fldT.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: elemSchema}, nil
+	case *types.Map:
+		elemSchema, err := c.FieldSchema(fldT.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: elemSchema}, nil
+	case *types.Struct:
+		return &Schema{Type: "object"}, nil
+	case *types.Pointer:
+		return c.FieldSchema(fldT.Elem())
+	case *types.Interface:
+		return &Schema{}, nil
+	case *types.Basic:
+		return basicSchema(fldT.Kind())
+	default:
+		return nil, errors.Errorf("unknown field type %s", fldT.String())
+	}
+}
+
+func mappedSchema(m typemap.Mapping) (*Schema, error) {
+	if len(m.Schema) == 0 {
+		return &Schema{Type: m.Type, Format: m.Format}, nil
+	}
+	var s Schema
+	if err := json.Unmarshal(m.Schema, &s); err != nil {
+		return nil, errors.Wrap(err, "invalid mapped schema")
+	}
+	return &s, nil
+}
+
+// zero is the minimum of unsigned integers.
+var zero = 0.0
+
+func basicSchema(kind types.BasicKind) (*Schema, error) {
+	switch kind {
+	case types.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case types.Int, types.Int8, types.Int16, types.Int32:
+		return &Schema{Type: "integer", Format: "int32"}, nil
+	case types.Uint, types.Uint8, types.Uint16:
+		return &Schema{Type: "integer", Format: "int32", Minimum: &zero}, nil
+	case types.Int64:
+		return &Schema{Type: "integer", Format: "int64"}, nil
+	case types.Uint32, types.Uint64:
+		// uint32 values above the int32 maximum need the int64 format.
+		return &Schema{Type: "integer", Format: "int64", Minimum: &zero}, nil
+	case types.String:
+		return &Schema{Type: "string"}, nil
+	case types.Float32:
+		return &Schema{Type: "number", Format: "float"}, nil
+	case types.Float64:
+		return &Schema{Type: "number", Format: "double"}, nil
+	default:
+		return nil, errors.Errorf("unknown basic type %d", kind)
+	}
+}
@@ -0,0 +1,29 @@
+package unversioned
+
+import "time"
+
+// Time is a wrapper around time.Time which supports correct marshaling to
+// YAML and JSON.
+type Time struct {
+	time.Time `protobuf:"-"`
+}
+
+// TypeMeta describes an individual object in an API response or request.
+type TypeMeta struct {
+	Kind       string `json:"kind,omitempty" protobuf:"bytes,1,opt,name=kind"`
+	APIVersion string `json:"apiVersion,omitempty" protobuf:"bytes,2,opt,name=apiVersion"`
+}
+
+// ListMeta describes metadata that synthetic resources must have.
+type ListMeta struct {
+	ResourceVersion string `json:"resourceVersion,omitempty" protobuf:"bytes,2,opt,name=resourceVersion"`
+}
+
+type GroupVersion struct {
+	Group   string
+	Version string
+}
+
+type Scheme struct{}
+
+func (s *Scheme) AddKnownTypes(gv GroupVersion, types ...interface{}) {}
@@ -0,0 +1,10 @@
+package v1
+
+import "k8s.io/kubernetes/pkg/api/unversioned"
+
+var SchemeGroupVersion = unversioned.GroupVersion{Group: "", Version: "v1"}
+
+func addKnownTypes(s *unversioned.Scheme) error {
+	s.AddKnownTypes(SchemeGroupVersion, &Pod{}, &PodList{}, &Node{}, &NodeList{}, &Endpoints{}, &EndpointsList{})
+	return nil
+}
@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"k8s.io/kubernetes/pkg/api/unversioned"
+)
+
+// ObjectMeta is metadata that all persisted resources must have.
+type ObjectMeta struct {
+	Name              string            `json:"name,omitempty" protobuf:"bytes,1,opt,name=name"`
+	Namespace         string            `json:"namespace,omitempty" protobuf:"bytes,3,opt,name=namespace"`
+	ResourceVersion   string            `json:"resourceVersion,omitempty" protobuf:"bytes,6,opt,name=resourceVersion"`
+	CreationTimestamp unversioned.Time  `json:"creationTimestamp,omitempty" protobuf:"bytes,8,opt,name=creationTimestamp"`
+	Labels            map[string]string `json:"labels,omitempty" protobuf:"bytes,11,rep,name=labels"`
+}
+
+// PodPhase is a label for the condition of a pod at the current time.
+type PodPhase string
+
+const (
+	// PodPending means the pod has been accepted but is not running yet.
+	PodPending PodPhase = "Pending"
+	// PodRunning means the pod has been bound to a node.
+	PodRunning PodPhase = "Running"
+)
+
+// ConditionStatus is the status of a condition.
+type ConditionStatus string
+
+const (
+	ConditionTrue  ConditionStatus = "True"
+	ConditionFalse ConditionStatus = "False"
+	Unknown        ConditionStatus = "Unknown"
+)
+
+// Pod is a collection of containers that can run on a host.
+// +genclient=true
+type Pod struct {
+	unversioned.TypeMeta `json:",inline"`
+	ObjectMeta           `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+	// Spec is the desired behavior of the pod.
+	Spec   PodSpec   `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+	Status PodStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// PodSpec is a description of a pod.
+type PodSpec struct {
+	Containers []Container `json:"containers" protobuf:"bytes,2,rep,name=containers"`
+	NodeName   string      `json:"nodeName,omitempty" protobuf:"bytes,10,opt,name=nodeName"`
+}
+
+// Container is a single application container.
+type Container struct {
+	Name  string  `json:"name" protobuf:"bytes,1,opt,name=name"`
+	Image string  `json:"image,omitempty" protobuf:"bytes,2,opt,name=image"`
+	Ports []int32 `json:"ports,omitempty" protobuf:"varint,3,rep,name=ports"`
+}
+
+// PodStatus is information about the status of a pod.
+type PodStatus struct {
+	Phase PodPhase `json:"phase,omitempty" protobuf:"bytes,1,opt,name=phase,casttype=PodPhase"`
+}
+
+// PodList is a list of Pods.
+type PodList struct {
+	unversioned.TypeMeta `json:",inline"`
+	unversioned.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+	Items                []Pod `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// PodLogOptions is the query options for the logs of a pod.
+type PodLogOptions struct {
+	unversioned.TypeMeta `json:",inline"`
+	// Container is the container to return the logs of.
+	Container string `json:"container,omitempty" protobuf:"bytes,1,opt,name=container"`
+}
+
+// Node is a worker node.
+// +genclient=true,nonNamespaced=true
+type Node struct {
+	unversioned.TypeMeta `json:",inline"`
+	ObjectMeta           `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+}
+
+// NodeList is a list of Nodes.
+type NodeList struct {
+	unversioned.TypeMeta `json:",inline"`
+	unversioned.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+	Items                []Node `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// Endpoints is a collection of endpoints that implement a service.
+// +genclient=true
+type Endpoints struct {
+	unversioned.TypeMeta `json:",inline"`
+	ObjectMeta           `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+}
+
+// EndpointsList is a list of endpoints.
+type EndpointsList struct {
+	unversioned.TypeMeta `json:",inline"`
+	unversioned.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+	Items                []Endpoints `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
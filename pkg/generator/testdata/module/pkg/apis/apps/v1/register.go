@@ -0,0 +1,12 @@
+package v1
+
+import "k8s.io/kubernetes/pkg/api/unversioned"
+
+const GroupName = "apps"
+
+var SchemeGroupVersion = unversioned.GroupVersion{Group: GroupName, Version: "v1"}
+
+func addKnownTypes(s *unversioned.Scheme) error {
+	s.AddKnownTypes(SchemeGroupVersion, &Deployment{}, &DeploymentList{})
+	return nil
+}
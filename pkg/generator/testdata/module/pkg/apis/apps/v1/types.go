@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// Deployment enables declarative updates for Pods.
+// +genclient=true
+type Deployment struct {
+	unversioned.TypeMeta `json:",inline"`
+	apiv1.ObjectMeta     `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+	Spec                 DeploymentSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}
+
+// DeploymentSpec is the specification of the desired behavior of a
+// Deployment.
+type DeploymentSpec struct {
+	// Replicas is the number of desired pods.
+	Replicas *int32 `json:"replicas,omitempty" protobuf:"varint,1,opt,name=replicas"`
+	// MinReadySeconds is the minimum number of seconds for which a newly
+	// created pod should be ready.
+	MinReadySeconds uint32        `json:"minReadySeconds,omitempty" protobuf:"varint,5,opt,name=minReadySeconds"`
+	Template        apiv1.PodSpec `json:"template" protobuf:"bytes,3,opt,name=template"`
+}
+
+// DeploymentList is a list of Deployments.
+type DeploymentList struct {
+	unversioned.TypeMeta `json:",inline"`
+	unversioned.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+	Items                []Deployment `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
@@ -0,0 +1,132 @@
+// Package typeref helps generators that write a module per Go package, such
+// as the Python and TypeScript generators, refer to the types of other
+// packages and track the imports that takes.
+package typeref
+
+import (
+	"go/types"
+	"sort"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+// KnownTypes returns the package qualified names, such as
+// k8s.io/kubernetes/pkg/api/v1.Pod, of the types and enums of pkgs.
+func KnownTypes(pkgs []loader.Package) map[string]struct{} {
+	known := map[string]struct{}{}
+	for _, pkg := range pkgs {
+		for _, typ := range pkg.Types {
+			known[pkg.Path+"."+typ.Name] = struct{}{}
+		}
+		for _, enum := range pkg.Enums {
+			known[pkg.Path+"."+enum.Name] = struct{}{}
+		}
+	}
+	return known
+}
+
+// QualifiedName returns the package qualified name of a named type, or of the
+// type a pointer points to, and an empty string for other types.
+func QualifiedName(typ types.Type) string {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := typ.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return ""
+	}
+	return loader.StripVendor(named.Obj().Pkg().Path()) + "." + named.Obj().Name()
+}
+
+// TypeName returns the name of a named type, or of the type a pointer points
+// to, and an empty string for other types.
+func TypeName(typ types.Type) string {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	if named, ok := typ.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+// Import is a module imported under an alias.
+type Import struct {
+	Module string
+	Alias  string
+}
+
+// ImportSet tracks the modules a module imports, keyed by Go package path,
+// and the import statements of mapped types it uses.
+type ImportSet struct {
+	pkgPath    string
+	importFor  func(pkgPath string) Import
+	imports    map[string]Import
+	statements map[string]struct{}
+}
+
+// NewImportSet returns an ImportSet for the module of the package pkgPath.
+// importFor names the module of another package and its alias.
+func NewImportSet(pkgPath string, importFor func(pkgPath string) Import) *ImportSet {
+	return &ImportSet{
+		pkgPath:    pkgPath,
+		importFor:  importFor,
+		imports:    map[string]Import{},
+		statements: map[string]struct{}{},
+	}
+}
+
+// Reference returns the name the module refers to a known named type by,
+// qualified with the alias of its module if it belongs to another package,
+// and whether the type is known.
+func (s *ImportSet) Reference(typ types.Type, known map[string]struct{}) (string, bool) {
+	named, ok := typ.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return "", false
+	}
+	pkgPath := loader.StripVendor(named.Obj().Pkg().Path())
+	if _, ok := known[pkgPath+"."+named.Obj().Name()]; !ok {
+		return "", false
+	}
+	if pkgPath == s.pkgPath {
+		return named.Obj().Name(), true
+	}
+	return s.Add(pkgPath) + "." + named.Obj().Name(), true
+}
+
+// Add records an import of the module for pkgPath and returns its alias.
+func (s *ImportSet) Add(pkgPath string) string {
+	if imp, ok := s.imports[pkgPath]; ok {
+		return imp.Alias
+	}
+	imp := s.importFor(pkgPath)
+	s.imports[pkgPath] = imp
+	return imp.Alias
+}
+
+// List returns the imported modules, ordered by module.
+func (s *ImportSet) List() []Import {
+	imports := make([]Import, 0, len(s.imports))
+	for _, imp := range s.imports {
+		imports = append(imports, imp)
+	}
+	sort.Slice(imports, func(i, j int) bool { return imports[i].Module < imports[j].Module })
+	return imports
+}
+
+// AddStatements records the import statements of a mapped type.
+func (s *ImportSet) AddStatements(statements []string) {
+	for _, statement := range statements {
+		s.statements[statement] = struct{}{}
+	}
+}
+
+// Statements returns the recorded import statements, sorted.
+func (s *ImportSet) Statements() []string {
+	statements := make([]string, 0, len(s.statements))
+	for statement := range s.statements {
+		statements = append(statements, statement)
+	}
+	sort.Strings(statements)
+	return statements
+}
@@ -0,0 +1,211 @@
+package typescript
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/generator/typeref"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
+)
+
+const moduleTemplateText = `{{if .Doc}}{{tsdoc .Doc ""}}
+{{end}}{{range .ImportStatements}}{{.}}
+{{end}}{{range .Imports}}import type * as {{.Alias}} from "{{.Module}}";
+{{end}}{{range .Enums}}
+{{if .Doc}}{{tsdoc .Doc ""}}
+{{end}}export type {{.Name}} = {{.Values}};
+{{end}}{{range .Interfaces}}
+{{if .Doc}}{{tsdoc .Doc ""}}
+{{end}}export interface {{.Name}}{{if .Extends}} extends {{join .Extends ", "}}{{end}} {{"{"}}{{range .Properties}}
+{{if .Doc}}{{tsdoc .Doc "  "}}
+{{end}}  {{.Name}}{{if .Optional}}?{{end}}: {{.Type}};{{end}}
+}
+{{end}}`
+
+var moduleTemplate = template.Must(template.New("module").
+	Funcs(
+		template.FuncMap{
+			"tsdoc": tsdoc,
+			"join":  strings.Join,
+		},
+	).
+	Parse(moduleTemplateText))
+
+func New(c Config) generator.Generator {
+	c.Logger.Debug("creating generator", "type", "typescript")
+	return &typeScriptGenerator{
+		config: c,
+	}
+}
+
+type Config struct {
+	generator.Config
+
+	// Declarations writes ambient .d.ts declaration files rather than .ts
+	// modules.
+	Declarations bool
+}
+
+type typeScriptGenerator struct {
+	config   Config
+	mappings typemap.Table
+	diags    generator.Diagnostics
+}
+
+var _ generator.Generator = &typeScriptGenerator{}
+
+type module struct {
+	Doc     string
+	Imports []typeref.Import
+	// ImportStatements are the imports of mapped types.
+	ImportStatements []string
+	Enums            []enum
+	Interfaces       []iface
+}
+
+type enum struct {
+	Name   string
+	Doc    string
+	Values string
+}
+
+type iface struct {
+	Name       string
+	Doc        string
+	Extends    []string
+	Properties []property
+}
+
+type property struct {
+	Name     string
+	Doc      string
+	Optional bool
+	Type     string
+}
+
+func (g *typeScriptGenerator) Generate(pkgs []loader.Package) error {
+	g.config.Logger.Debug("generating")
+
+	known := typeref.KnownTypes(pkgs)
+	g.mappings = typemap.Merge(defaultTypeMappings, g.config.TypeMappings["typescript"])
+	g.diags = generator.Diagnostics{}
+
+	for _, pkg := range pkgs {
+		name := pkg.Path + g.extension()
+		g.config.Logger.Debug("generating for package", "package", pkg.Path, "file", name)
+
+		m := g.module(pkg, known)
+
+		var buf bytes.Buffer
+		if err := moduleTemplate.Execute(&buf, m); err != nil {
+			return errors.Wrapf(err, "failed to render module for package %s", pkg.Path)
+		}
+
+		contents := g.config.WithHeader(generator.BlockComment, pkg.Path, pkg.APIVersion(), bytes.TrimLeft(buf.Bytes(), "\n"))
+		if err := g.config.Output.WriteFile(name, contents); err != nil {
+			return err
+		}
+	}
+
+	return g.diags.Err()
+}
+
+func (g *typeScriptGenerator) extension() string {
+	if g.config.Declarations {
+		return ".d.ts"
+	}
+	return ".ts"
+}
+
+// module builds the module for a package, recording problems with types and
+// leaving out those that have any.
+func (g *typeScriptGenerator) module(pkg loader.Package, known map[string]struct{}) *module {
+	imports := typeref.NewImportSet(pkg.Path, moduleImport(pkg.Path))
+
+	m := &module{Doc: pkg.Doc}
+
+	for _, e := range pkg.Enums {
+		values := make([]string, 0, len(e.Values))
+		for _, v := range e.Values {
+			values = append(values, literal(e.Type, v.Value))
+		}
+		m.Enums = append(m.Enums, enum{Name: e.Name, Doc: e.Doc, Values: strings.Join(values, " | ")})
+	}
+
+	for _, typ := range pkg.Types {
+		if g.mappings.Maps(pkg.Path, typ.Name) {
+			continue
+		}
+		i := iface{Name: typ.Name, Doc: typ.Doc}
+		problems := g.diags.Len()
+		properties := map[string]string{}
+
+		if typ.Kind != "" {
+			i.Properties = append(i.Properties,
+				property{Name: "apiVersion", Optional: true, Type: quote(typ.APIVersion())},
+				property{Name: "kind", Optional: true, Type: quote(typ.Kind)},
+			)
+		}
+
+		for _, fld := range typ.Fields {
+			embedded := fld.Anonymous && fld.JSONProperty == ""
+			if embedded && typeref.TypeName(fld.Type) == "TypeMeta" {
+				if typ.Kind == "" {
+					// Types without a registered kind still have an apiVersion
+					// and kind, only not known ones.
+					i.Properties = append(i.Properties,
+						property{Name: "apiVersion", Optional: true, Type: "string"},
+						property{Name: "kind", Optional: true, Type: "string"},
+					)
+				}
+				continue
+			}
+
+			tsType, err := tsType(fld.Type, known, g.mappings, imports)
+			if err != nil {
+				g.diags.Addf(fld.Position, "field %s.%s: %v", typ.Name, fld.Name, err)
+				continue
+			}
+
+			if embedded {
+				if _, ok := known[typeref.QualifiedName(fld.Type)]; ok {
+					i.Extends = append(i.Extends, tsType)
+				} else {
+					g.config.Logger.Debug("ignoring embedded field of unknown type", "type", typ.Name, "field", fld.Name)
+				}
+				continue
+			}
+
+			name := fld.JSONProperty
+			if name == "" {
+				name = fld.Name
+			}
+			if other, ok := properties[name]; ok {
+				g.diags.Addf(fld.Position, "field %s.%s has property %q, as does field %s", typ.Name, fld.Name, name, other)
+				continue
+			}
+			properties[name] = fld.Name
+			i.Properties = append(i.Properties, property{
+				Name:     propertyName(name),
+				Doc:      fld.Doc,
+				Optional: !fld.JSONRequired && !fld.Markers.Required(),
+				Type:     tsType,
+			})
+		}
+
+		if g.diags.Len() > problems {
+			continue
+		}
+		m.Interfaces = append(m.Interfaces, i)
+	}
+
+	m.Imports = imports.List()
+	m.ImportStatements = imports.Statements()
+
+	return m
+}
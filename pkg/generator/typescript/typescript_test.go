@@ -0,0 +1,94 @@
+package typescript_test
+
+import (
+	"github.com/inconshreveable/log15"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	. "github.com/jimmidyson/kube-client-gen/pkg/generator/typescript"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+var _ = Describe("TypeScript", func() {
+	var (
+		logger log15.Logger
+		out    *generator.MemoryOutput
+	)
+
+	BeforeEach(func() {
+		logger = log15.New()
+		logger.SetHandler(log15.DiscardHandler())
+
+		out = generator.NewMemoryOutput()
+	})
+
+	generate := func(declarations bool) {
+		pkgs, err := loader.New([]string{
+			"k8s.io/kubernetes/pkg/api/unversioned",
+			"k8s.io/kubernetes/pkg/api/v1",
+			"k8s.io/kubernetes/pkg/apis/apps/v1",
+		}, logger, loader.WithDir("../testdata/module")).Load()
+		Expect(err).NotTo(HaveOccurred())
+
+		err = New(Config{Config: generator.Config{Logger: logger, Output: out}, Declarations: declarations}).Generate(pkgs)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	readFile := func(name string) string {
+		Expect(out.Files).To(HaveKey(name))
+		return string(out.Files[name])
+	}
+
+	It("writes a module per package", func() {
+		generate(false)
+		Expect(readFile("k8s.io/kubernetes/pkg/api/v1.ts")).To(ContainSubstring("export interface Pod {"))
+		Expect(readFile("k8s.io/kubernetes/pkg/api/unversioned.ts")).To(ContainSubstring("export interface TypeMeta {"))
+		Expect(readFile("k8s.io/kubernetes/pkg/apis/apps/v1.ts")).To(ContainSubstring("export interface Deployment {"))
+	})
+
+	It("imports other packages under an alias", func() {
+		generate(false)
+		v1 := readFile("k8s.io/kubernetes/pkg/api/v1.ts")
+		Expect(v1).To(ContainSubstring(`import type * as k8s_io_kubernetes_pkg_api_unversioned from "./unversioned";`))
+		Expect(v1).To(ContainSubstring("export interface PodList {\n  apiVersion?: \"v1\";\n  kind?: \"PodList\";\n  metadata?: k8s_io_kubernetes_pkg_api_unversioned.ListMeta;\n"))
+
+		apps := readFile("k8s.io/kubernetes/pkg/apis/apps/v1.ts")
+		Expect(apps).To(ContainSubstring(`import type * as k8s_io_kubernetes_pkg_api_v1 from "../../api/v1";`))
+		Expect(apps).To(ContainSubstring("  template: k8s_io_kubernetes_pkg_api_v1.PodSpec;\n"))
+	})
+
+	It("writes enums as unions of their values", func() {
+		generate(false)
+		v1 := readFile("k8s.io/kubernetes/pkg/api/v1.ts")
+		Expect(v1).To(ContainSubstring(`export type PodPhase = "Pending" | "Running";`))
+		Expect(v1).To(ContainSubstring("  phase?: PodPhase;\n"))
+	})
+
+	It("replaces TypeMeta with literal apiVersion and kind properties", func() {
+		generate(false)
+		Expect(readFile("k8s.io/kubernetes/pkg/api/v1.ts")).To(ContainSubstring("export interface Pod {\n  apiVersion?: \"v1\";\n  kind?: \"Pod\";\n  metadata?: ObjectMeta;\n"))
+		Expect(readFile("k8s.io/kubernetes/pkg/apis/apps/v1.ts")).To(ContainSubstring("export interface Deployment {\n  apiVersion?: \"apps/v1\";\n  kind?: \"Deployment\";\n"))
+	})
+
+	It("keeps the apiVersion and kind of types without a registered kind as strings", func() {
+		generate(false)
+		Expect(readFile("k8s.io/kubernetes/pkg/api/v1.ts")).To(ContainSubstring("export interface PodLogOptions {\n  apiVersion?: string;\n  kind?: string;\n  /**\n   * Container is the container to return the logs of.\n   */\n  container?: string;\n}"))
+	})
+
+	It("maps fields to TypeScript types, required without omitempty", func() {
+		generate(false)
+		v1 := readFile("k8s.io/kubernetes/pkg/api/v1.ts")
+		Expect(v1).To(ContainSubstring("  containers: Container[];\n"))
+		Expect(v1).To(ContainSubstring("  ports?: number[];\n"))
+		Expect(v1).To(ContainSubstring("  creationTimestamp?: string;\n"))
+		Expect(v1).To(ContainSubstring("  labels?: { [key: string]: string };\n"))
+	})
+
+	It("writes declaration files", func() {
+		generate(true)
+		Expect(readFile("k8s.io/kubernetes/pkg/apis/apps/v1.d.ts")).To(ContainSubstring("export interface Deployment {"))
+		Expect(out.Files).NotTo(HaveKey("k8s.io/kubernetes/pkg/apis/apps/v1.ts"))
+	})
+})
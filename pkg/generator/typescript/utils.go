@@ -0,0 +1,142 @@
+package typescript
+
+import (
+	"go/types"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator/typeref"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
+)
+
+var nonIdentifierRegexp = regexp.MustCompile(`[^A-Za-z0-9_$]`)
+
+// moduleImport returns how the module of the package fromPkgPath imports the
+// modules of other packages: by their path relative to it, under an alias
+// made of their package path.
+func moduleImport(fromPkgPath string) func(pkgPath string) typeref.Import {
+	return func(pkgPath string) typeref.Import {
+		rel, err := filepath.Rel(filepath.FromSlash(filepath.Dir(fromPkgPath)), filepath.FromSlash(pkgPath))
+		if err != nil {
+			rel = pkgPath
+		}
+		rel = filepath.ToSlash(rel)
+		if !strings.HasPrefix(rel, ".") {
+			rel = "./" + rel
+		}
+		return typeref.Import{
+			Module: rel,
+			Alias:  nonIdentifierRegexp.ReplaceAllString(pkgPath, "_"),
+		}
+	}
+}
+
+// defaultTypeMappings are the TypeScript types for Go types that are not
+// generated as interfaces.
+var defaultTypeMappings = typemap.Table{
+	"k8s.io/kubernetes/pkg/api/unversioned.Time":    {Type: "string"},
+	"k8s.io/kubernetes/pkg/util/intstr.IntOrString": {Type: "number | string"},
+	"k8s.io/kubernetes/pkg/runtime.RawExtension":    {Type: "{ [key: string]: unknown }"},
+}
+
+func tsType(typ types.Type, known map[string]struct{}, mappings typemap.Table, imports *typeref.ImportSet) (string, error) {
+	if m, ok := mappings.Lookup(typ); ok {
+		imports.AddStatements(m.Imports)
+		return m.Type, nil
+	}
+	if ref, ok := imports.Reference(typ, known); ok {
+		return ref, nil
+	}
+	if loader.Dynamic(typ) != loader.NotDynamic {
+		return "unknown", nil
+	}
+
+	switch fldT := typ.Underlying().(type) {
+	case *types.Slice:
+		if basic, ok := fldT.Elem().Underlying().(*types.Basic); ok && basic.Kind() == types.Byte {
+			return "string", nil
+		}
+		elemType, err := tsType(fldT.Elem(), known, mappings, imports)
+		if err != nil {
+			return "", err
+		}
+		return arrayType(elemType), nil
+	case *types.Array:
+		elemType, err := tsType(fldT.Elem(), known, mappings, imports)
+		if err != nil {
+			return "", err
+		}
+		return arrayType(elemType), nil
+	case *types.Map:
+		elemType, err := tsType(fldT.Elem(), known, mappings, imports)
+		if err != nil {
+			return "", err
+		}
+		return "{ [key: string]: " + elemType + " }", nil
+	case *types.Struct:
+		return "{ [key: string]: unknown }", nil
+	case *types.Pointer:
+		return tsType(fldT.Elem(), known, mappings, imports)
+	case *types.Interface:
+		return "unknown", nil
+	case *types.Basic:
+		return tsTypeBasic(fldT.Kind())
+	default:
+		return "", errors.Errorf("unknown field type %s", fldT.String())
+	}
+}
+
+func arrayType(elemType string) string {
+	if strings.ContainsAny(elemType, " |{") {
+		return "Array<" + elemType + ">"
+	}
+	return elemType + "[]"
+}
+
+func tsTypeBasic(kind types.BasicKind) (string, error) {
+	switch kind {
+	case types.Bool:
+		return "boolean", nil
+	case types.Int, types.Int8, types.Int16, types.Int32, types.Int64,
+		types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64,
+		types.Float32, types.Float64:
+		return "number", nil
+	case types.String:
+		return "string", nil
+	default:
+		return "", errors.Errorf("unknown basic type %d", kind)
+	}
+}
+
+// literal returns the TypeScript literal type for an enum value.
+func literal(typ types.Type, value string) string {
+	if basic, ok := typ.(*types.Basic); ok && basic.Info()&types.IsString != 0 {
+		return quote(value)
+	}
+	return value
+}
+
+func quote(s string) string {
+	return strconv.Quote(s)
+}
+
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func propertyName(name string) string {
+	if identifierRegexp.MatchString(name) {
+		return name
+	}
+	return quote(name)
+}
+
+var startOfLineRegexp = regexp.MustCompile(`(?m:^)`)
+
+func tsdoc(doc string, indent string) string {
+	doc = strings.Replace(doc, "*/", "*\\/", -1)
+	return indent + "/**\n" + startOfLineRegexp.ReplaceAllString(doc, indent+" * ") + "\n" + indent + " */"
+}
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/diff"
+)
+
+// Verify compares generated files with the files in dir, writing a unified
+// diff of every changed, added and removed file to w. It returns whether dir
+// is up to date.
+//
+// A file in dir counts as removed if it is in the previous manifest but was
+// not generated. Without a previous manifest, it counts as removed if it was
+// not generated, but sits in a directory below dir that generated files were
+// written to and has the same extension as one of them.
+func Verify(files map[string][]byte, previous *Manifest, dir string, w io.Writer) (bool, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	upToDate := true
+	report := func(oldName, newName string, old, new []byte) error {
+		upToDate = false
+		d := diff.Unified(oldName, newName, string(old), string(new))
+		if d == "" {
+			// Empty files that are added or removed have no lines to show.
+			d = fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName)
+		}
+		_, err := io.WriteString(w, d)
+		return errors.Wrap(err, "failed to write diff")
+	}
+
+	extensions := map[string]map[string]struct{}{}
+	for _, name := range names {
+		if d := path.Dir(name); d != "." {
+			if extensions[d] == nil {
+				extensions[d] = map[string]struct{}{}
+			}
+			extensions[d][path.Ext(name)] = struct{}{}
+		}
+
+		fp := filepath.Join(dir, filepath.FromSlash(name))
+		existing, err := os.ReadFile(fp)
+		if os.IsNotExist(err) {
+			if err := report("/dev/null", "b/"+name, nil, files[name]); err != nil {
+				return false, err
+			}
+			continue
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to read file %s", fp)
+		}
+		if bytes.Equal(existing, files[name]) {
+			continue
+		}
+		if err := report("a/"+name, "b/"+name, existing, files[name]); err != nil {
+			return false, err
+		}
+	}
+
+	if previous != nil {
+		current := make(map[string]string, len(files))
+		for name := range files {
+			current[name] = ""
+		}
+		for _, name := range previous.Stale(current) {
+			fp := filepath.Join(dir, filepath.FromSlash(name))
+			existing, err := os.ReadFile(fp)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return false, errors.Wrapf(err, "failed to read file %s", fp)
+			}
+			if err := report("a/"+name, "/dev/null", existing, nil); err != nil {
+				return false, err
+			}
+		}
+		return upToDate, nil
+	}
+
+	dirs := make([]string, 0, len(extensions))
+	for d := range extensions {
+		dirs = append(dirs, d)
+	}
+	sort.Strings(dirs)
+
+	for _, d := range dirs {
+		fp := filepath.Join(dir, filepath.FromSlash(d))
+		entries, err := os.ReadDir(fp)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to read directory %s", fp)
+		}
+		for _, entry := range entries {
+			name := path.Join(d, entry.Name())
+			if !entry.Type().IsRegular() {
+				continue
+			}
+			if _, ok := files[name]; ok {
+				continue
+			}
+			if _, ok := extensions[d][path.Ext(name)]; !ok {
+				continue
+			}
+			existing, err := os.ReadFile(filepath.Join(fp, entry.Name()))
+			if err != nil {
+				return false, errors.Wrapf(err, "failed to read file %s", filepath.Join(fp, entry.Name()))
+			}
+			if err := report("a/"+name, "/dev/null", existing, nil); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	return upToDate, nil
+}
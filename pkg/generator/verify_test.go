@@ -0,0 +1,66 @@
+package generator_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/jimmidyson/kube-client-gen/pkg/generator"
+)
+
+var _ = Describe("Verify", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "verify")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	write := func(files map[string]string) {
+		for name, content := range files {
+			fp := filepath.Join(dir, filepath.FromSlash(name))
+			Expect(os.MkdirAll(filepath.Dir(fp), 0755)).To(Succeed())
+			Expect(os.WriteFile(fp, []byte(content), 0644)).To(Succeed())
+		}
+	}
+
+	It("reports nothing when the files are up to date", func() {
+		write(map[string]string{"a/Kept.java": "kept\n"})
+
+		var buf bytes.Buffer
+		upToDate, err := Verify(map[string][]byte{"a/Kept.java": []byte("kept\n")}, nil, dir, &buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(upToDate).To(BeTrue())
+		Expect(buf.String()).To(BeEmpty())
+	})
+
+	It("reports changed, added and removed files without a previous manifest", func() {
+		write(map[string]string{
+			"a/Kept.java":    "kept\n",
+			"a/Changed.java": "old\n",
+			"a/Removed.java": "removed\n",
+			"a/notes.txt":    "mine\n",
+			"b/Other.java":   "other\n",
+		})
+
+		var buf bytes.Buffer
+		upToDate, err := Verify(map[string][]byte{
+			"a/Kept.java":    []byte("kept\n"),
+			"a/Changed.java": []byte("new\n"),
+			"a/Added.java":   []byte("added\n"),
+		}, nil, dir, &buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(upToDate).To(BeFalse())
+		Expect(buf.String()).To(Equal("--- /dev/null\n+++ b/a/Added.java\n@@ -0,0 +1 @@\n+added\n" +
+			"--- a/a/Changed.java\n+++ b/a/Changed.java\n@@ -1 +1 @@\n-old\n+new\n" +
+			"--- a/a/Removed.java\n+++ /dev/null\n@@ -1 +0,0 @@\n-removed\n"))
+	})
+})
@@ -0,0 +1,385 @@
+package ir
+
+import (
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+// FromLoader converts loaded packages to an IR document.
+func FromLoader(pkgs []loader.Package) *Document {
+	known := map[string]struct{}{}
+	for _, pkg := range pkgs {
+		for _, typ := range pkg.Types {
+			known[pkg.Path+"."+typ.Name] = struct{}{}
+		}
+		for _, enum := range pkg.Enums {
+			known[pkg.Path+"."+enum.Name] = struct{}{}
+		}
+	}
+	c := &fromConverter{known: known, visiting: map[*types.Named]bool{}}
+
+	doc := &Document{Version: Version, Packages: make([]Package, 0, len(pkgs))}
+	for _, pkg := range pkgs {
+		p := Package{Path: pkg.Path, Doc: pkg.Doc, Group: pkg.Group, Version: pkg.Version, Transitive: pkg.Transitive}
+		for _, typ := range pkg.Types {
+			t := Type{
+				Name:           typ.Name,
+				Package:        typ.Package,
+				Doc:            typ.Doc,
+				GenerateClient: typ.GenerateClient,
+				Namespaced:     typ.Namespaced,
+				Markers:        fromMarkers(typ.Markers),
+				Group:          typ.Group,
+				Version:        typ.Version,
+				Kind:           typ.Kind,
+				Position:       fromPosition(typ.Position),
+			}
+			for _, fld := range typ.Fields {
+				f := Field{
+					Name:         fld.Name,
+					Doc:          fld.Doc,
+					Anonymous:    fld.Anonymous,
+					JSONRequired: fld.JSONRequired,
+					JSONProperty: fld.JSONProperty,
+					Type:         c.typeRef(fld.Type),
+					Markers:      fromMarkers(fld.Markers),
+					Position:     fromPosition(fld.Position),
+				}
+				if fld.Enum != nil {
+					f.Enum = fld.Enum.Package + "." + fld.Enum.Name
+				}
+				for _, tag := range fld.Tags {
+					f.Tags = append(f.Tags, Tag{Name: tag.Name, Value: tag.Value})
+				}
+				t.Fields = append(t.Fields, f)
+			}
+			p.Types = append(p.Types, t)
+		}
+		for _, enum := range pkg.Enums {
+			e := Enum{
+				Name:    enum.Name,
+				Package: enum.Package,
+				Doc:     enum.Doc,
+				Type:    c.typeRef(enum.Type),
+			}
+			for _, v := range enum.Values {
+				e.Values = append(e.Values, EnumValue{Name: v.Name, Value: v.Value, Doc: v.Doc})
+			}
+			p.Enums = append(p.Enums, e)
+		}
+		doc.Packages = append(doc.Packages, p)
+	}
+	return doc
+}
+
+func fromMarkers(markers loader.Markers) []Marker {
+	var res []Marker
+	for _, m := range markers {
+		res = append(res, Marker{Name: m.Name, Value: m.Value})
+	}
+	return res
+}
+
+func fromPosition(pos token.Position) *Position {
+	if !pos.IsValid() {
+		return nil
+	}
+	return &Position{File: pos.Filename, Line: pos.Line, Column: pos.Column}
+}
+
+type fromConverter struct {
+	known    map[string]struct{}
+	visiting map[*types.Named]bool
+}
+
+func (c *fromConverter) typeRef(typ types.Type) TypeRef {
+	switch t := typ.(type) {
+	case *types.Named:
+		if t.Obj().Pkg() == nil {
+			// Predeclared named types such as error.
+			return c.typeRef(t.Underlying())
+		}
+		ref := TypeRef{Name: t.Obj().Name(), Package: t.Obj().Pkg().Path()}
+		if _, ok := c.known[ref.Package+"."+ref.Name]; ok {
+			ref.Kind = KindNamed
+			return ref
+		}
+		ref.Kind = KindExternal
+		if !c.visiting[t] {
+			c.visiting[t] = true
+			underlying := c.typeRef(t.Underlying())
+			ref.Underlying = &underlying
+			delete(c.visiting, t)
+		}
+		return ref
+	case *types.Alias:
+		if t.Obj().Pkg() == nil {
+			// Predeclared aliases such as any.
+			return c.typeRef(types.Unalias(t))
+		}
+		// Aliases are referred to by their own name, as generators recognize
+		// types such as json.RawMessage by it.
+		underlying := c.typeRef(types.Unalias(t).Underlying())
+		return TypeRef{Kind: KindExternal, Name: t.Obj().Name(), Package: t.Obj().Pkg().Path(), Underlying: &underlying}
+	case *types.Basic:
+		return TypeRef{Kind: KindBasic, Name: t.Name()}
+	case *types.Slice:
+		elem := c.typeRef(t.Elem())
+		return TypeRef{Kind: KindSlice, Elem: &elem}
+	case *types.Array:
+		elem := c.typeRef(t.Elem())
+		return TypeRef{Kind: KindArray, Elem: &elem, Len: t.Len()}
+	case *types.Map:
+		key, elem := c.typeRef(t.Key()), c.typeRef(t.Elem())
+		return TypeRef{Kind: KindMap, Key: &key, Elem: &elem}
+	case *types.Pointer:
+		elem := c.typeRef(t.Elem())
+		return TypeRef{Kind: KindPointer, Elem: &elem}
+	case *types.Struct:
+		return TypeRef{Kind: KindStruct}
+	case *types.Interface:
+		ref := TypeRef{Kind: KindInterface}
+		for i := 0; i < t.NumMethods(); i++ {
+			ref.Methods = append(ref.Methods, t.Method(i).Name())
+		}
+		return ref
+	default:
+		return TypeRef{Kind: KindInterface}
+	}
+}
+
+// ToLoader converts an IR document back to loaded packages, reconstructing
+// go/types types for fields so that generators can run from a saved IR.
+func (d *Document) ToLoader() ([]loader.Package, error) {
+	if d.Version < 1 || d.Version > Version {
+		return nil, errors.Errorf("unsupported IR version %d, expected at most %d", d.Version, Version)
+	}
+
+	c := &toConverter{packages: map[string]*types.Package{}, named: map[string]*types.Named{}}
+
+	// Declare every type and enum first so fields can refer to them
+	// regardless of order.
+	for _, pkg := range d.Packages {
+		for _, typ := range pkg.Types {
+			c.declare(typ.Package, typ.Name)
+		}
+		for _, enum := range pkg.Enums {
+			c.declare(enum.Package, enum.Name)
+		}
+	}
+
+	pkgs := make([]loader.Package, 0, len(d.Packages))
+	for _, pkg := range d.Packages {
+		p := loader.Package{Path: pkg.Path, Doc: pkg.Doc, Group: pkg.Group, Version: pkg.Version, Transitive: pkg.Transitive}
+
+		for _, enum := range pkg.Enums {
+			typ, err := c.goType(enum.Type)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid type for enum %s.%s", enum.Package, enum.Name)
+			}
+			c.named[enum.Package+"."+enum.Name].SetUnderlying(typ)
+
+			e := loader.Enum{Name: enum.Name, Package: enum.Package, Doc: enum.Doc, Type: typ}
+			for _, v := range enum.Values {
+				e.Values = append(e.Values, loader.EnumValue{Name: v.Name, Value: v.Value, Doc: v.Doc})
+			}
+			p.Enums = append(p.Enums, e)
+		}
+
+		for _, typ := range pkg.Types {
+			t := loader.Type{
+				Name:           typ.Name,
+				Package:        typ.Package,
+				Doc:            typ.Doc,
+				GenerateClient: typ.GenerateClient,
+				Namespaced:     typ.Namespaced,
+				Markers:        toMarkers(typ.Markers),
+				Group:          typ.Group,
+				Version:        typ.Version,
+				Kind:           typ.Kind,
+				Position:       toPosition(typ.Position),
+			}
+
+			vars := make([]*types.Var, 0, len(typ.Fields))
+			tags := make([]string, 0, len(typ.Fields))
+			for _, fld := range typ.Fields {
+				fldType, err := c.goType(fld.Type)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid type for field %s.%s.%s", typ.Package, typ.Name, fld.Name)
+				}
+				f := loader.Field{
+					Name:         fld.Name,
+					Doc:          fld.Doc,
+					Anonymous:    fld.Anonymous,
+					JSONRequired: fld.JSONRequired,
+					JSONProperty: fld.JSONProperty,
+					Type:         fldType,
+					TypeName:     fldType.String(),
+					Markers:      toMarkers(fld.Markers),
+					Position:     toPosition(fld.Position),
+				}
+				for _, tag := range fld.Tags {
+					f.Tags = append(f.Tags, loader.StructTag{Name: tag.Name, Value: tag.Value})
+				}
+				t.Fields = append(t.Fields, f)
+
+				vars = append(vars, types.NewField(token.NoPos, c.pkg(typ.Package), fld.Name, fldType, fld.Anonymous))
+				tagStrings := make([]string, 0, len(f.Tags))
+				for _, tag := range f.Tags {
+					tagStrings = append(tagStrings, tag.String())
+				}
+				tags = append(tags, strings.Join(tagStrings, " "))
+			}
+			c.named[typ.Package+"."+typ.Name].SetUnderlying(types.NewStruct(vars, tags))
+
+			p.Types = append(p.Types, t)
+		}
+
+		pkgs = append(pkgs, p)
+	}
+
+	linkEnums(d, pkgs)
+
+	return pkgs, nil
+}
+
+func toMarkers(markers []Marker) loader.Markers {
+	var res loader.Markers
+	for _, m := range markers {
+		res = append(res, loader.Marker{Name: m.Name, Value: m.Value})
+	}
+	return res
+}
+
+func toPosition(pos *Position) token.Position {
+	if pos == nil {
+		return token.Position{}
+	}
+	return token.Position{Filename: pos.File, Line: pos.Line, Column: pos.Column}
+}
+
+// linkEnums points fields at the enums they refer to, as the loader does.
+func linkEnums(d *Document, pkgs []loader.Package) {
+	enums := map[string]*loader.Enum{}
+	for i := range pkgs {
+		for j := range pkgs[i].Enums {
+			enum := &pkgs[i].Enums[j]
+			enums[enum.Package+"."+enum.Name] = enum
+		}
+	}
+
+	for i, pkg := range d.Packages {
+		for j, typ := range pkg.Types {
+			for k, fld := range typ.Fields {
+				if fld.Enum != "" {
+					pkgs[i].Types[j].Fields[k].Enum = enums[fld.Enum]
+				}
+			}
+		}
+	}
+}
+
+type toConverter struct {
+	packages map[string]*types.Package
+	named    map[string]*types.Named
+}
+
+func (c *toConverter) pkg(path string) *types.Package {
+	if pkg, ok := c.packages[path]; ok {
+		return pkg
+	}
+	pkg := types.NewPackage(path, path[strings.LastIndex(path, "/")+1:])
+	c.packages[path] = pkg
+	return pkg
+}
+
+func (c *toConverter) declare(pkgPath, name string) *types.Named {
+	if named, ok := c.named[pkgPath+"."+name]; ok {
+		return named
+	}
+	pkg := c.pkg(pkgPath)
+	named := types.NewNamed(types.NewTypeName(token.NoPos, pkg, name, nil), nil, nil)
+	c.named[pkgPath+"."+name] = named
+	return named
+}
+
+func (c *toConverter) goType(ref TypeRef) (types.Type, error) {
+	switch ref.Kind {
+	case KindBasic:
+		obj, ok := types.Universe.Lookup(ref.Name).(*types.TypeName)
+		if !ok {
+			return nil, errors.Errorf("unknown basic type %s", ref.Name)
+		}
+		if _, ok := obj.Type().(*types.Basic); !ok {
+			return nil, errors.Errorf("unknown basic type %s", ref.Name)
+		}
+		return obj.Type(), nil
+	case KindNamed:
+		named, ok := c.named[ref.Package+"."+ref.Name]
+		if !ok {
+			return nil, errors.Errorf("unknown type %s.%s", ref.Package, ref.Name)
+		}
+		return named, nil
+	case KindExternal:
+		if named, ok := c.named[ref.Package+"."+ref.Name]; ok {
+			return named, nil
+		}
+		named := c.declare(ref.Package, ref.Name)
+		underlying := types.Type(types.NewStruct(nil, nil))
+		if ref.Underlying != nil {
+			var err error
+			if underlying, err = c.goType(*ref.Underlying); err != nil {
+				return nil, err
+			}
+			underlying = underlying.Underlying()
+		}
+		named.SetUnderlying(underlying)
+		return named, nil
+	case KindSlice, KindArray, KindPointer:
+		if ref.Elem == nil {
+			return nil, errors.Errorf("%s type without element type", ref.Kind)
+		}
+		elem, err := c.goType(*ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		switch ref.Kind {
+		case KindSlice:
+			return types.NewSlice(elem), nil
+		case KindArray:
+			return types.NewArray(elem, ref.Len), nil
+		default:
+			return types.NewPointer(elem), nil
+		}
+	case KindMap:
+		if ref.Key == nil || ref.Elem == nil {
+			return nil, errors.New("map type without key or element type")
+		}
+		key, err := c.goType(*ref.Key)
+		if err != nil {
+			return nil, err
+		}
+		elem, err := c.goType(*ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewMap(key, elem), nil
+	case KindInterface:
+		// Only the method names are kept, so methods take no arguments and
+		// return nothing.
+		methods := make([]*types.Func, 0, len(ref.Methods))
+		for _, name := range ref.Methods {
+			methods = append(methods, types.NewFunc(token.NoPos, nil, name, types.NewSignatureType(nil, nil, nil, nil, nil, false)))
+		}
+		return types.NewInterfaceType(methods, nil).Complete(), nil
+	case KindStruct:
+		return types.NewStruct(nil, nil), nil
+	default:
+		return nil, errors.Errorf("unknown type kind %q", ref.Kind)
+	}
+}
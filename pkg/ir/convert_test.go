@@ -0,0 +1,116 @@
+package ir_test
+
+import (
+	"go/token"
+
+	"github.com/inconshreveable/log15"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	. "github.com/jimmidyson/kube-client-gen/pkg/ir"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+var _ = Describe("IR", func() {
+	var pkgs []loader.Package
+
+	BeforeEach(func() {
+		logger := log15.New()
+		logger.SetHandler(log15.DiscardHandler())
+
+		var err error
+		pkgs, err = loader.New([]string{
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg1",
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg2",
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg3",
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg4",
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg5",
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg7",
+		}, logger).Load()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("describes type references structurally", func() {
+		doc := FromLoader(pkgs)
+		Expect(doc.Version).To(Equal(Version))
+
+		widget := doc.Packages[1].Types[0]
+		Expect(widget.Fields[0].Type).To(Equal(TypeRef{Kind: KindNamed, Name: "Phase", Package: "github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg2"}))
+		Expect(widget.Fields[0].Enum).To(Equal("github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg2.Phase"))
+		Expect(widget.Fields[2].Type).To(Equal(TypeRef{
+			Kind:       KindExternal,
+			Name:       "Name",
+			Package:    "github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg2",
+			Underlying: &TypeRef{Kind: KindBasic, Name: "string"},
+		}))
+
+		pod := doc.Packages[3].Types[0]
+		Expect(pod.Fields[1].Type.String()).To(Equal("map[string]string"))
+		Expect(pod.Fields[3].Type.String()).To(Equal("*int32"))
+		Expect(pod.Fields[4].Type).To(Equal(TypeRef{Kind: KindSlice, Elem: &TypeRef{Kind: KindBasic, Name: "byte"}}))
+	})
+
+	It("keeps free-form JSON and embedded objects recognizable", func() {
+		logger := log15.New()
+		logger.SetHandler(log15.DiscardHandler())
+		loaded, err := loader.New([]string{"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg7"}, logger).Load()
+		Expect(err).NotTo(HaveOccurred())
+
+		doc := FromLoader(loaded)
+		Expect(doc.Packages[0].Types[0].Fields[2].Type.Underlying).To(Equal(&TypeRef{Kind: KindInterface, Methods: []string{"GetObjectKind"}}))
+
+		pkgs, err := doc.ToLoader()
+		Expect(err).NotTo(HaveOccurred())
+		kinds := map[string]loader.DynamicKind{}
+		for _, fld := range pkgs[0].Types[0].Fields {
+			kinds[fld.Name] = loader.Dynamic(fld.Type)
+		}
+		Expect(kinds).To(HaveKeyWithValue("Object", loader.EmbeddedObject))
+		Expect(kinds).To(HaveKeyWithValue("Raw", loader.EmbeddedObject))
+		Expect(kinds).To(HaveKeyWithValue("Kinded", loader.EmbeddedObject))
+		Expect(kinds).To(HaveKeyWithValue("Value", loader.ArbitraryJSON))
+		Expect(kinds).To(HaveKeyWithValue("Payload", loader.ArbitraryJSON))
+		Expect(kinds).To(HaveKeyWithValue("Name", loader.NotDynamic))
+	})
+
+	DescribeTable("round trips through loaded packages", func(format string) {
+		doc := FromLoader(pkgs)
+		data, err := doc.Marshal(format)
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded, err := Unmarshal(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decoded).To(Equal(doc))
+
+		loaded, err := decoded.ToLoader()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(FromLoader(loaded)).To(Equal(doc))
+
+		withoutOffset := func(pos token.Position) token.Position {
+			pos.Offset = 0
+			return pos
+		}
+		for i := range pkgs {
+			for j, typ := range pkgs[i].Types {
+				Expect(typ.Position.IsValid()).To(BeTrue())
+				Expect(loaded[i].Types[j].Position).To(Equal(withoutOffset(typ.Position)))
+				for k, fld := range typ.Fields {
+					Expect(loaded[i].Types[j].Fields[k].TypeName).To(Equal(fld.TypeName))
+					Expect(loaded[i].Types[j].Fields[k].Tags).To(Equal(fld.Tags))
+					Expect(loaded[i].Types[j].Fields[k].Position).To(Equal(withoutOffset(fld.Position)))
+				}
+			}
+		}
+		Expect(loaded[1].Types[0].Fields[0].Enum).To(BeIdenticalTo(&loaded[1].Enums[0]))
+	},
+		Entry("as JSON", FormatJSON),
+		Entry("as YAML", FormatYAML),
+	)
+
+	It("rejects unsupported versions", func() {
+		_, err := Unmarshal([]byte(`{"version": 2, "packages": []}`))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,56 @@
+package ir
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/yamlutil"
+)
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Marshal encodes the document in the given format.
+func (d *Document) Marshal(format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case FormatYAML:
+		return yamlutil.Marshal(d)
+	default:
+		return nil, errors.Errorf("unknown IR format %s", format)
+	}
+}
+
+// Unmarshal decodes a document in either JSON or YAML format.
+func Unmarshal(data []byte) (*Document, error) {
+	var d Document
+	if err := yamlutil.Unmarshal(data, &d); err != nil {
+		return nil, errors.Wrap(err, "failed to decode IR")
+	}
+	if d.Version < 1 || d.Version > Version {
+		return nil, errors.Errorf("unsupported IR version %d, expected at most %d", d.Version, Version)
+	}
+	return &d, nil
+}
+
+// ReadFile reads a document from a JSON or YAML file.
+func ReadFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read IR file %s", path)
+	}
+	d, err := Unmarshal(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid IR file %s", path)
+	}
+	return d, nil
+}
@@ -0,0 +1,160 @@
+// Package ir defines a serializable, language neutral intermediate
+// representation of loaded API packages. Unlike the loader's model, which
+// refers to live go/types types, type references in the IR are described
+// structurally so the model can be saved, cached and read by tools written in
+// other languages.
+package ir
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Version is the schema version of the IR. It is incremented on incompatible
+// changes, and documents with a newer version are rejected.
+const Version = 1
+
+type Document struct {
+	Version  int       `json:"version"`
+	Packages []Package `json:"packages"`
+}
+
+type Package struct {
+	Path  string `json:"path"`
+	Doc   string `json:"doc,omitempty"`
+	Types []Type `json:"types,omitempty"`
+	Enums []Enum `json:"enums,omitempty"`
+	// Group and Version are the API group and version the package registers.
+	Group   string `json:"group,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Transitive is set for packages that were not requested but contain
+	// types referred to by requested packages.
+	Transitive bool `json:"transitive,omitempty"`
+}
+
+type Type struct {
+	Name           string   `json:"name"`
+	Package        string   `json:"package"`
+	Doc            string   `json:"doc,omitempty"`
+	GenerateClient bool     `json:"generateClient,omitempty"`
+	Namespaced     bool     `json:"namespaced,omitempty"`
+	Markers        []Marker `json:"markers,omitempty"`
+	Fields         []Field  `json:"fields,omitempty"`
+	Group          string   `json:"group,omitempty"`
+	Version        string   `json:"version,omitempty"`
+	// Kind is set for types registered with a scheme.
+	Kind     string    `json:"kind,omitempty"`
+	Position *Position `json:"position,omitempty"`
+}
+
+type Field struct {
+	Name         string  `json:"name"`
+	Doc          string  `json:"doc,omitempty"`
+	Anonymous    bool    `json:"anonymous,omitempty"`
+	JSONRequired bool    `json:"jsonRequired,omitempty"`
+	JSONProperty string  `json:"jsonProperty"`
+	Type         TypeRef `json:"type"`
+	// Enum is the package qualified name of the field's enum, if it has one.
+	Enum     string    `json:"enum,omitempty"`
+	Markers  []Marker  `json:"markers,omitempty"`
+	Tags     []Tag     `json:"tags,omitempty"`
+	Position *Position `json:"position,omitempty"`
+}
+
+// Position is where a type or field is declared, so that problems found by
+// generators running from the IR can point at the source.
+type Position struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column,omitempty"`
+}
+
+type Enum struct {
+	Name    string      `json:"name"`
+	Package string      `json:"package"`
+	Doc     string      `json:"doc,omitempty"`
+	Type    TypeRef     `json:"type"`
+	Values  []EnumValue `json:"values,omitempty"`
+}
+
+type EnumValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Doc   string `json:"doc,omitempty"`
+}
+
+type Marker struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+type Tag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Kind string
+
+const (
+	// KindBasic is a predeclared type such as string or int32.
+	KindBasic Kind = "basic"
+	// KindNamed refers to a type or enum in the document.
+	KindNamed Kind = "named"
+	// KindExternal refers to a named type outside of the document, described
+	// by its underlying type.
+	KindExternal  Kind = "external"
+	KindSlice     Kind = "slice"
+	KindArray     Kind = "array"
+	KindMap       Kind = "map"
+	KindPointer   Kind = "pointer"
+	KindInterface Kind = "interface"
+	// KindStruct is an anonymous struct, or the underlying type of an external
+	// struct type. Its fields are not described.
+	KindStruct Kind = "struct"
+)
+
+// TypeRef is a structural reference to a type.
+type TypeRef struct {
+	Kind Kind `json:"kind"`
+	// Name is the name of a basic, named or external type.
+	Name string `json:"name,omitempty"`
+	// Package is the package path of a named or external type.
+	Package string `json:"package,omitempty"`
+	// Elem is the element type of a slice, array, map or pointer.
+	Elem *TypeRef `json:"elem,omitempty"`
+	// Key is the key type of a map.
+	Key *TypeRef `json:"key,omitempty"`
+	// Len is the length of an array.
+	Len int64 `json:"len,omitempty"`
+	// Underlying is the underlying type of an external type.
+	Underlying *TypeRef `json:"underlying,omitempty"`
+	// Methods are the method names of an interface, which tell interfaces
+	// such as runtime.Object apart from free-form JSON.
+	Methods []string `json:"methods,omitempty"`
+}
+
+// String returns the Go syntax for the type, with named types qualified by
+// their package path.
+func (t TypeRef) String() string {
+	switch t.Kind {
+	case KindNamed, KindExternal:
+		return t.Package + "." + t.Name
+	case KindSlice:
+		return "[]" + t.Elem.String()
+	case KindArray:
+		return "[" + strconv.FormatInt(t.Len, 10) + "]" + t.Elem.String()
+	case KindMap:
+		return "map[" + t.Key.String() + "]" + t.Elem.String()
+	case KindPointer:
+		return "*" + t.Elem.String()
+	case KindInterface:
+		if len(t.Methods) == 0 {
+			return "interface{}"
+		}
+		return "interface{" + strings.Join(t.Methods, "(); ") + "()}"
+	case KindStruct:
+		return "struct{}"
+	default:
+		return t.Name
+	}
+}
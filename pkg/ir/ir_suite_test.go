@@ -1,4 +1,4 @@
-package tmp
+package ir_test
 
 import (
 	. "github.com/onsi/ginkgo"
@@ -7,7 +7,7 @@ import (
 	"testing"
 )
 
-func TestTmp(t *testing.T) {
+func TestIR(t *testing.T) {
 	RegisterFailHandler(Fail)
-	RunSpecs(t, "Tmp Suite")
+	RunSpecs(t, "IR Suite")
 }
@@ -14,7 +14,9 @@ func PackageDoc(pkgPath string, files []*ast.File, fset *token.FileSet) *doc.Pac
 		fileMap[strconv.Itoa(i)] = astFile
 	}
 	astPkg, _ := ast.NewPackage(fset, fileMap, nil, nil)
-	return doc.New(astPkg, pkgPath, 0)
+	// AllDecls stops doc.New removing unexported declarations from the files,
+	// which it does even with PreserveAST.
+	return doc.New(astPkg, pkgPath, doc.PreserveAST|doc.AllDecls)
 }
 
 func TypeDoc(pkgDoc *doc.Package, typeName string) string {
@@ -12,11 +12,11 @@ import (
 )
 
 var sortedCommentGroup = []*ast.CommentGroup{
-	&ast.CommentGroup{[]*ast.Comment{{Slash: token.Pos(1)}}},
-	&ast.CommentGroup{[]*ast.Comment{{Slash: token.Pos(2)}}},
-	&ast.CommentGroup{[]*ast.Comment{{Slash: token.Pos(3)}}},
-	&ast.CommentGroup{[]*ast.Comment{{Slash: token.Pos(4)}}},
-	&ast.CommentGroup{[]*ast.Comment{{Slash: token.Pos(5)}}},
+	&ast.CommentGroup{List: []*ast.Comment{{Slash: token.Pos(1)}}},
+	&ast.CommentGroup{List: []*ast.Comment{{Slash: token.Pos(2)}}},
+	&ast.CommentGroup{List: []*ast.Comment{{Slash: token.Pos(3)}}},
+	&ast.CommentGroup{List: []*ast.Comment{{Slash: token.Pos(4)}}},
+	&ast.CommentGroup{List: []*ast.Comment{{Slash: token.Pos(5)}}},
 }
 
 var _ = Describe("SortComments", func() {
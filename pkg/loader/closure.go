@@ -0,0 +1,139 @@
+package loader
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// addReferencedTypes adds the named types referred to by fields of loaded types
+// from packages outside of the model, repeating until every referenced type
+// is part of the model. Types from the standard library are never added.
+func (l *ASTLoader) addReferencedTypes(loaded []Package, roots []*packages.Package) ([]Package, error) {
+	all := map[string]*packages.Package{}
+	packages.Visit(roots, nil, func(pkg *packages.Package) {
+		all[pkg.PkgPath] = pkg
+	})
+
+	// seen holds every type that is, or has been requested to be, part of
+	// the model, so that types the loader skips are not requested again.
+	seen := map[string]bool{}
+	indexes := map[string]int{}
+	for i, pkg := range loaded {
+		indexes[pkg.Path] = i
+		for _, typ := range pkg.Types {
+			seen[pkg.Path+"."+typ.Name] = true
+		}
+		for _, enum := range pkg.Enums {
+			seen[pkg.Path+"."+enum.Name] = true
+		}
+	}
+
+	pending := loaded
+	for len(pending) > 0 {
+		wanted := map[string]map[string]bool{}
+		visited := map[types.Type]bool{}
+		for _, pkg := range pending {
+			for _, typ := range pkg.Types {
+				if !l.included(pkg.Path, typ.Name) {
+					// Types that are filtered out do not need the types
+					// they refer to.
+					continue
+				}
+				for _, fld := range typ.Fields {
+					referencedTypes(fld.Type, visited, func(obj *types.TypeName) {
+						key := obj.Pkg().Path() + "." + obj.Name()
+						if seen[key] || !l.included(obj.Pkg().Path(), obj.Name()) {
+							return
+						}
+						seen[key] = true
+						if wanted[obj.Pkg().Path()] == nil {
+							wanted[obj.Pkg().Path()] = map[string]bool{}
+						}
+						wanted[obj.Pkg().Path()][obj.Name()] = true
+					})
+				}
+			}
+		}
+
+		paths := make([]string, 0, len(wanted))
+		for pkgPath := range wanted {
+			paths = append(paths, pkgPath)
+		}
+		sort.Strings(paths)
+
+		pending = nil
+		for _, pkgPath := range paths {
+			pkg, ok := all[pkgPath]
+			if !ok {
+				l.logger.Warn("cannot find package of referenced types", "package", pkgPath)
+				continue
+			}
+			l.logger.Debug("loading referenced types", "package", pkgPath, "types", len(wanted[pkgPath]))
+			added, err := l.loadPackage(pkg, wanted[pkgPath])
+			if err != nil {
+				return nil, err
+			}
+			if len(added.Types) == 0 && len(added.Enums) == 0 {
+				continue
+			}
+			pending = append(pending, added)
+
+			if i, ok := indexes[pkgPath]; ok {
+				loaded[i].Types = append(loaded[i].Types, added.Types...)
+				loaded[i].Enums = append(loaded[i].Enums, added.Enums...)
+				continue
+			}
+			added.Transitive = true
+			indexes[pkgPath] = len(loaded)
+			loaded = append(loaded, added)
+		}
+	}
+
+	return loaded, nil
+}
+
+// referencedTypes calls add for each exported named type outside of the
+// standard library that typ refers to, looking through pointers, slices,
+// arrays and maps, and through the underlying types of named types that are
+// not structs.
+func referencedTypes(typ types.Type, visited map[types.Type]bool, add func(*types.TypeName)) {
+	if visited[typ] {
+		return
+	}
+	visited[typ] = true
+
+	switch t := typ.(type) {
+	case *types.Named:
+		obj := t.Obj()
+		if obj.Pkg() == nil || !obj.Exported() || isStandardLibrary(obj.Pkg().Path()) {
+			return
+		}
+		add(obj)
+		if _, ok := t.Underlying().(*types.Struct); !ok {
+			referencedTypes(t.Underlying(), visited, add)
+		}
+	case *types.Pointer:
+		referencedTypes(t.Elem(), visited, add)
+	case *types.Slice:
+		referencedTypes(t.Elem(), visited, add)
+	case *types.Array:
+		referencedTypes(t.Elem(), visited, add)
+	case *types.Map:
+		referencedTypes(t.Key(), visited, add)
+		referencedTypes(t.Elem(), visited, add)
+	}
+}
+
+// isStandardLibrary reports whether a package path belongs to the standard
+// library, whose first path element never contains a dot.
+func isStandardLibrary(pkgPath string) bool {
+	pkgPath = StripVendor(pkgPath)
+	first := pkgPath
+	if idx := strings.Index(pkgPath, "/"); idx > -1 {
+		first = pkgPath[:idx]
+	}
+	return !strings.Contains(first, ".")
+}
@@ -0,0 +1,61 @@
+package loader
+
+import (
+	"go/types"
+	"path"
+)
+
+// DynamicKind classifies types whose JSON is not described by their Go type,
+// which generators map to the dynamic types of their target languages.
+type DynamicKind int
+
+const (
+	// NotDynamic is a type whose JSON follows its Go type.
+	NotDynamic DynamicKind = iota
+	// ArbitraryJSON is any JSON value, such as an interface{} or a
+	// json.RawMessage.
+	ArbitraryJSON
+	// EmbeddedObject is a Kubernetes object with its own apiVersion and kind,
+	// such as a runtime.Object or a runtime.RawExtension.
+	EmbeddedObject
+)
+
+func (k DynamicKind) String() string {
+	switch k {
+	case ArbitraryJSON:
+		return "arbitrary JSON"
+	case EmbeddedObject:
+		return "embedded object"
+	default:
+		return "not dynamic"
+	}
+}
+
+// Dynamic returns the dynamic kind of typ, without looking through pointers
+// or the element types of slices and maps. It relies on type names as well as
+// method sets, as types loaded from IR have no methods.
+func Dynamic(typ types.Type) DynamicKind {
+	// Named types are matched first, as json.RawMessage is a []byte that
+	// would otherwise be taken for base64 encoded bytes. Aliases, which also
+	// have an Obj, are matched by their own name.
+	if named, ok := typ.(interface{ Obj() *types.TypeName }); ok && named.Obj().Pkg() != nil {
+		pkgPath := StripVendor(named.Obj().Pkg().Path())
+		switch name := named.Obj().Name(); {
+		case pkgPath == "encoding/json" && name == "RawMessage":
+			return ArbitraryJSON
+		case path.Base(pkgPath) == "runtime" && (name == "Object" || name == "RawExtension"):
+			return EmbeddedObject
+		}
+	}
+
+	iface, ok := typ.Underlying().(*types.Interface)
+	if !ok {
+		return NotDynamic
+	}
+	for i := 0; i < iface.NumMethods(); i++ {
+		if iface.Method(i).Name() == "GetObjectKind" {
+			return EmbeddedObject
+		}
+	}
+	return ArbitraryJSON
+}
@@ -0,0 +1,168 @@
+package loader
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/doc"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/loader/astutils"
+)
+
+// Enum is a named basic type, such as PodPhase, along with the constants
+// declared for it in the same package.
+type Enum struct {
+	Name    string
+	Package string
+	Doc     string
+	Type    types.Type
+	Values  []EnumValue
+}
+
+type EnumValue struct {
+	Name  string
+	Value string
+	Doc   string
+}
+
+func namedBasicType(pkg *packages.Package, pkgDoc *doc.Package, t *ast.TypeSpec) (Enum, bool) {
+	obj, ok := pkg.TypesInfo.Defs[t.Name].(*types.TypeName)
+	if !ok || obj.IsAlias() {
+		return Enum{}, false
+	}
+	basic, ok := obj.Type().Underlying().(*types.Basic)
+	if !ok {
+		return Enum{}, false
+	}
+	return Enum{
+		Name:    t.Name.Name,
+		Package: pkg.PkgPath,
+		Doc:     strings.TrimSpace(astutils.TypeDoc(pkgDoc, t.Name.Name)),
+		Type:    basic,
+	}, true
+}
+
+// enumValues adds the exported constants declared in pkg to the matching
+// enums, dropping any enum that has no constants.
+func enumValues(pkg *packages.Package, enums []Enum) []Enum {
+	if len(enums) == 0 {
+		return enums
+	}
+
+	enumIndex := make(map[string]int, len(enums))
+	for i, enum := range enums {
+		enumIndex[enum.Name] = i
+	}
+
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				valueDoc := valueSpec.Doc.Text()
+				if valueDoc == "" {
+					valueDoc = valueSpec.Comment.Text()
+				}
+				if valueDoc == "" && len(genDecl.Specs) == 1 {
+					valueDoc = genDecl.Doc.Text()
+				}
+
+				for _, name := range valueSpec.Names {
+					if !name.IsExported() {
+						continue
+					}
+					c, ok := pkg.TypesInfo.Defs[name].(*types.Const)
+					if !ok {
+						continue
+					}
+					named, ok := c.Type().(*types.Named)
+					if !ok || named.Obj().Pkg() != pkg.Types {
+						continue
+					}
+					i, ok := enumIndex[named.Obj().Name()]
+					if !ok {
+						continue
+					}
+					enums[i].Values = append(enums[i].Values, EnumValue{
+						Name:  name.Name,
+						Value: constantValue(c.Val()),
+						Doc:   strings.TrimSpace(valueDoc),
+					})
+				}
+			}
+		}
+	}
+
+	withValues := enums[:0]
+	for _, enum := range enums {
+		if len(enum.Values) > 0 {
+			withValues = append(withValues, enum)
+		}
+	}
+	return withValues
+}
+
+func constantValue(v constant.Value) string {
+	switch v.Kind() {
+	case constant.String:
+		return constant.StringVal(v)
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	default:
+		return v.ExactString()
+	}
+}
+
+// linkEnums points every field whose type, or element type for slices and
+// maps, is a loaded enum at that enum.
+func linkEnums(pkgs []Package) {
+	enums := map[string]*Enum{}
+	for i := range pkgs {
+		for j := range pkgs[i].Enums {
+			enum := &pkgs[i].Enums[j]
+			enums[enum.Package+"."+enum.Name] = enum
+		}
+	}
+	if len(enums) == 0 {
+		return
+	}
+
+	for i := range pkgs {
+		for j := range pkgs[i].Types {
+			fields := pkgs[i].Types[j].Fields
+			for k := range fields {
+				named, ok := elemType(fields[k].Type).(*types.Named)
+				if !ok || named.Obj().Pkg() == nil {
+					continue
+				}
+				if enum, ok := enums[named.Obj().Pkg().Path()+"."+named.Obj().Name()]; ok {
+					fields[k].Enum = enum
+				}
+			}
+		}
+	}
+}
+
+func elemType(typ types.Type) types.Type {
+	for {
+		switch t := typ.(type) {
+		case *types.Pointer:
+			typ = t.Elem()
+		case *types.Slice:
+			typ = t.Elem()
+		case *types.Map:
+			typ = t.Elem()
+		default:
+			return typ
+		}
+	}
+}
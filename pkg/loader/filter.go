@@ -0,0 +1,209 @@
+package loader
+
+import (
+	"go/types"
+	"regexp"
+	"strings"
+)
+
+// newPatterns compiles globs matched against package qualified type names
+// such as k8s.io/kubernetes/pkg/api/v1.Pod. A * matches any sequence of
+// characters, including / and ., and a ? matches any single character.
+func newPatterns(globs []string) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		var expr strings.Builder
+		expr.WriteString("^")
+		for _, r := range glob {
+			switch r {
+			case '*':
+				expr.WriteString(".*")
+			case '?':
+				expr.WriteString(".")
+			default:
+				expr.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		expr.WriteString("$")
+		patterns = append(patterns, regexp.MustCompile(expr.String()))
+	}
+	return patterns
+}
+
+func matchAny(patterns []*regexp.Regexp, name string) bool {
+	for _, p := range patterns {
+		if p.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// included reports whether the include and exclude patterns keep the named
+// type or enum.
+func (l *ASTLoader) included(pkgPath, name string) bool {
+	qualifiedName := pkgPath + "." + name
+	if len(l.includes) > 0 && !matchAny(l.includes, qualifiedName) {
+		return false
+	}
+	return !matchAny(l.excludes, qualifiedName)
+}
+
+// filterTypes drops the types and enums that are not included, and then, if
+// roots are set, those that cannot be reached from a root by following field
+// types. Packages left without types or enums are dropped.
+func (l *ASTLoader) filterTypes(pkgs []Package) []Package {
+	keep := map[string]bool{}
+	for _, pkg := range pkgs {
+		for _, typ := range pkg.Types {
+			keep[pkg.Path+"."+typ.Name] = l.included(pkg.Path, typ.Name)
+		}
+		for _, enum := range pkg.Enums {
+			keep[pkg.Path+"."+enum.Name] = l.included(pkg.Path, enum.Name)
+		}
+	}
+
+	if len(l.roots) > 0 {
+		keep = l.reachable(pkgs, keep)
+	}
+
+	filtered := make([]Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		var typs []Type
+		for _, typ := range pkg.Types {
+			if keep[pkg.Path+"."+typ.Name] {
+				typs = append(typs, typ)
+			} else {
+				l.logger.Debug("filtering out type", "package", pkg.Path, "type", typ.Name)
+			}
+		}
+		var enums []Enum
+		for _, enum := range pkg.Enums {
+			if keep[pkg.Path+"."+enum.Name] {
+				enums = append(enums, enum)
+			} else {
+				l.logger.Debug("filtering out enum", "package", pkg.Path, "enum", enum.Name)
+			}
+		}
+		if len(typs) == 0 && len(enums) == 0 {
+			l.logger.Debug("skipping package - no types left after filtering", "package", pkg.Path)
+			continue
+		}
+		pkg.Types, pkg.Enums = typs, enums
+		filtered = append(filtered, pkg)
+	}
+	return filtered
+}
+
+// reachable returns the kept types and enums that are roots, or that are
+// referred to by the fields of reachable types. Roots are types whose kind, or
+// package qualified name, matches a root pattern.
+func (l *ASTLoader) reachable(pkgs []Package, keep map[string]bool) map[string]bool {
+	typesByName := map[string]Type{}
+	var queue []string
+	for _, pkg := range pkgs {
+		for _, typ := range pkg.Types {
+			name := pkg.Path + "." + typ.Name
+			typesByName[name] = typ
+			if !keep[name] {
+				continue
+			}
+			if (typ.Kind != "" && matchAny(l.roots, typ.Kind)) || matchAny(l.roots, name) {
+				queue = append(queue, name)
+			}
+		}
+	}
+
+	reached := map[string]bool{}
+	for _, name := range queue {
+		reached[name] = true
+	}
+	visited := map[types.Type]bool{}
+	for len(queue) > 0 {
+		typ := typesByName[queue[0]]
+		queue = queue[1:]
+		for _, fld := range typ.Fields {
+			referencedTypes(fld.Type, visited, func(obj *types.TypeName) {
+				name := obj.Pkg().Path() + "." + obj.Name()
+				if !keep[name] || reached[name] {
+					return
+				}
+				reached[name] = true
+				if _, ok := typesByName[name]; ok {
+					queue = append(queue, name)
+				}
+			})
+		}
+	}
+	return reached
+}
+
+// Select returns the packages with the given paths, along with the types and
+// enums of transitive packages that their types refer to, directly or through
+// other types. Transitive packages left without types or enums are dropped.
+func Select(pkgs []Package, paths []string) []Package {
+	selected := map[string]bool{}
+	for _, pkgPath := range paths {
+		selected[pkgPath] = true
+	}
+
+	typesByName := map[string]Type{}
+	var queue []string
+	for _, pkg := range pkgs {
+		for _, typ := range pkg.Types {
+			name := pkg.Path + "." + typ.Name
+			typesByName[name] = typ
+			if selected[pkg.Path] {
+				queue = append(queue, name)
+			}
+		}
+	}
+
+	reached := map[string]bool{}
+	visited := map[types.Type]bool{}
+	for len(queue) > 0 {
+		typ := typesByName[queue[0]]
+		queue = queue[1:]
+		for _, fld := range typ.Fields {
+			referencedTypes(fld.Type, visited, func(obj *types.TypeName) {
+				name := StripVendor(obj.Pkg().Path()) + "." + obj.Name()
+				if reached[name] {
+					return
+				}
+				reached[name] = true
+				if _, ok := typesByName[name]; ok {
+					queue = append(queue, name)
+				}
+			})
+		}
+	}
+
+	var res []Package
+	for _, pkg := range pkgs {
+		if selected[pkg.Path] {
+			res = append(res, pkg)
+			continue
+		}
+		if !pkg.Transitive {
+			continue
+		}
+		var typs []Type
+		for _, typ := range pkg.Types {
+			if reached[pkg.Path+"."+typ.Name] {
+				typs = append(typs, typ)
+			}
+		}
+		var enums []Enum
+		for _, enum := range pkg.Enums {
+			if reached[pkg.Path+"."+enum.Name] {
+				enums = append(enums, enum)
+			}
+		}
+		if len(typs) == 0 && len(enums) == 0 {
+			continue
+		}
+		pkg.Types, pkg.Enums = typs, enums
+		res = append(res, pkg)
+	}
+	return res
+}
@@ -3,16 +3,17 @@ package loader
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
 	"go/types"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
-	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/go/packages"
 
 	"github.com/jimmidyson/kube-client-gen/pkg/loader/astutils"
 )
@@ -20,17 +21,95 @@ import (
 type ASTLoader struct {
 	requestedPackages []string
 	logger            log15.Logger
-	prog              *loader.Program
+	dir               string
+	buildFlags        []string
+	typeClosure       bool
+	includes          []*regexp.Regexp
+	excludes          []*regexp.Regexp
+	roots             []*regexp.Regexp
 }
 
-func New(packages []string, logger log15.Logger) *ASTLoader {
-	return &ASTLoader{requestedPackages: packages, logger: logger}
+// Option configures optional behaviour of an ASTLoader.
+type Option func(*ASTLoader)
+
+// WithDir sets the directory packages are resolved from. The Go module
+// containing the directory, and its vendor directory if present, is used
+// to locate the requested packages.
+func WithDir(dir string) Option {
+	return func(l *ASTLoader) {
+		l.dir = dir
+	}
+}
+
+// WithBuildFlags sets additional flags, such as -mod=vendor or -tags, passed
+// to the go command when resolving packages.
+func WithBuildFlags(flags ...string) Option {
+	return func(l *ASTLoader) {
+		l.buildFlags = flags
+	}
+}
+
+// WithTypeClosure adds the types that loaded types refer to from packages that
+// were not requested, so that only root packages need to be requested for the
+// model to be self-contained.
+func WithTypeClosure() Option {
+	return func(l *ASTLoader) {
+		l.typeClosure = true
+	}
+}
+
+// WithIncludes keeps only the types and enums whose package qualified name,
+// such as k8s.io/kubernetes/pkg/api/v1.Pod, matches one of the glob patterns.
+func WithIncludes(globs ...string) Option {
+	return func(l *ASTLoader) {
+		l.includes = newPatterns(globs)
+	}
+}
+
+// WithExcludes drops the types and enums whose package qualified name matches
+// one of the glob patterns.
+func WithExcludes(globs ...string) Option {
+	return func(l *ASTLoader) {
+		l.excludes = newPatterns(globs)
+	}
+}
+
+// WithRoots keeps only the types and enums reachable from root types, which
+// are the types whose kind, such as Deployment, or package qualified name
+// matches one of the glob patterns.
+func WithRoots(globs ...string) Option {
+	return func(l *ASTLoader) {
+		l.roots = newPatterns(globs)
+	}
+}
+
+func New(packages []string, logger log15.Logger, opts ...Option) *ASTLoader {
+	l := &ASTLoader{requestedPackages: packages, logger: logger}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
 }
 
 type Package struct {
 	Path  string
 	Types []Type
+	Enums []Enum
 	Doc   string
+	// Group and Version are the API group and version the package registers,
+	// with the empty group being the core group.
+	Group   string
+	Version string
+	// Transitive is set for packages that were not requested but contain
+	// types referred to by requested packages. Only the referred to types, and
+	// the types they refer to, are loaded from such packages.
+	Transitive bool
+}
+
+// APIVersion returns the apiVersion of objects in the package, such as v1 or
+// apps/v1.
+func (p Package) APIVersion() string {
+	return apiVersion(p.Group, p.Version)
 }
 
 type Type struct {
@@ -40,6 +119,79 @@ type Type struct {
 	Doc            string
 	GenerateClient bool
 	Namespaced     bool
+	Markers        Markers
+	Group          string
+	Version        string
+	// Kind is set for types registered with a scheme, which are the top-level
+	// objects with an apiVersion and kind.
+	Kind string
+	// Position is where the type is declared. Types loaded from IR have no
+	// offset.
+	Position token.Position
+}
+
+// APIVersion returns the apiVersion of the type, such as v1 or apps/v1.
+func (t Type) APIVersion() string {
+	return apiVersion(t.Group, t.Version)
+}
+
+// Resource returns the name of the REST resource of the type. It is the
+// path given by a +resourceName or +kubebuilder:resource:path marker, and
+// otherwise the lower case plural of the name, such as pods or ingresses.
+func (t Type) Resource() string {
+	if resource, ok := t.Markers.ResourceName(); ok {
+		return resource
+	}
+	return strings.ToLower(Plural(t.Name))
+}
+
+// irregularPlurals holds the plurals of the words, in lower case, that do not
+// follow the English suffix rules, such as Endpoints which is already plural.
+var irregularPlurals = map[string]string{
+	"child":                      "children",
+	"endpoints":                  "endpoints",
+	"person":                     "people",
+	"resourceclaimparameters":    "resourceclaimparameters",
+	"resourceclassparameters":    "resourceclassparameters",
+	"securitycontextconstraints": "securitycontextconstraints",
+}
+
+// Plural returns the plural of a type name, cased as the name, such as
+// NetworkPolicies for NetworkPolicy. Irregular plurals apply to the whole
+// name or to its last words, so ServiceEndpoints stays as it is.
+func Plural(name string) string {
+	lower := strings.ToLower(name)
+	for singular, plural := range irregularPlurals {
+		i := len(name) - len(singular)
+		if strings.HasSuffix(lower, singular) && (i == 0 || unicode.IsUpper(rune(name[i]))) {
+			return name[:i+1] + plural[1:]
+		}
+	}
+	switch {
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return name + "es"
+	case strings.HasSuffix(lower, "y") && !strings.HasSuffix(lower, "ay") && !strings.HasSuffix(lower, "ey") && !strings.HasSuffix(lower, "oy"):
+		return name[:len(name)-1] + "ies"
+	default:
+		return name + "s"
+	}
+}
+
+// StripVendor removes everything up to and including the vendor directory
+// from a package path, or from a type name qualified with one, so vendored
+// packages are named as their upstream packages.
+func StripVendor(pkgPath string) string {
+	if idx := strings.Index(pkgPath, "vendor/"); idx > -1 {
+		return pkgPath[idx+len("vendor/"):]
+	}
+	return pkgPath
+}
+
+func apiVersion(group, version string) string {
+	if group == "" {
+		return version
+	}
+	return group + "/" + version
 }
 
 type Field struct {
@@ -50,220 +202,267 @@ type Field struct {
 	JSONProperty string
 	Type         types.Type
 	TypeName     string
+	Enum         *Enum
+	Markers      Markers
+	Tags         StructTags
+	// Position is where the field is declared. Fields loaded from IR have no
+	// offset.
+	Position token.Position
 }
 
-func (l *ASTLoader) Load() ([]Package, error) {
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+	packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps
 
-	var conf loader.Config
-	conf.ParserMode = parser.ParseComments
-
-	for _, pkg := range l.requestedPackages {
-		conf.Import(pkg)
+func (l *ASTLoader) Load() ([]Package, error) {
+	cfg := &packages.Config{
+		Mode:       loadMode,
+		Dir:        l.dir,
+		BuildFlags: l.buildFlags,
+		Logf: func(format string, args ...interface{}) {
+			l.logger.Debug(fmt.Sprintf(format, args...))
+		},
 	}
 
-	prog, err := conf.Load()
+	pkgs, err := packages.Load(cfg, l.requestedPackages...)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot load requested packages")
 	}
-	l.prog = prog
+
+	var loadErrors []string
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, e := range pkg.Errors {
+			loadErrors = append(loadErrors, e.Error())
+		}
+	})
+	if len(loadErrors) > 0 {
+		return nil, errors.Errorf("cannot load requested packages: %s", strings.Join(loadErrors, "; "))
+	}
 
 	loadedPackages := make([]Package, 0, len(l.requestedPackages))
-	for _, pkg := range prog.InitialPackages() {
-		pkgPath := pkg.Pkg.Path()
+	for _, pkg := range pkgs {
+		loadedPackage, err := l.loadPackage(pkg, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(loadedPackage.Types) == 0 && len(loadedPackage.Enums) == 0 {
+			l.logger.Debug("skipping package - no exported types", "package", pkg.PkgPath)
+			continue
+		}
+		loadedPackages = append(loadedPackages, loadedPackage)
+	}
 
-		l.logger.Debug("parsing package", "package", pkgPath)
+	if l.typeClosure {
+		loadedPackages, err = l.addReferencedTypes(loadedPackages, pkgs)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-		l.logger.Debug("extracting package docs", "package", pkgPath)
-		pkgDoc := astutils.PackageDoc(pkgPath, pkg.Files, prog.Fset)
+	if len(l.includes) > 0 || len(l.excludes) > 0 || len(l.roots) > 0 {
+		loadedPackages = l.filterTypes(loadedPackages)
+	}
 
-		exportedTypes := []Type{}
-		for _, file := range pkg.Files {
-			filePos := prog.Fset.Position(file.Pos())
-			l.logger.Debug("parsing file", "package", pkgPath, "file", filePos.Filename)
+	linkEnums(loadedPackages)
 
-			parsedFile, err := parser.ParseFile(prog.Fset, filePos.Filename, nil, parser.ParseComments)
-			if err != nil {
-				return nil, errors.Wrapf(err, "failed to parse file %s", filePos.Filename)
-			}
-			l.logger.Debug("sorting comments", "package", pkgPath, "file", filePos.Filename)
-			sortedComments := astutils.SortCommentsByPos(parsedFile.Comments)
-			l.logger.Debug("sorting objects", "package", pkgPath, "file", filePos.Filename)
-			sortedObjects := astutils.SortObjectsByPos(file.Scope.Objects)
-
-			for i, currentObj := range sortedObjects {
-				t, ok := currentObj.Decl.(*ast.TypeSpec)
-				if !ok || !t.Name.IsExported() {
-					continue
-				}
-				astStructType, ok := t.Type.(*ast.StructType)
-				if !ok {
-					continue
-				}
+	return loadedPackages, nil
+}
 
-				typ, ok := pkg.Types[t.Type]
-				if !ok {
-					return nil, errors.Errorf("unable to load struct type: %s", t.Name.Name)
-				}
-				structType, ok := typ.Type.(*types.Struct)
-				if !ok {
-					continue
+// loadPackage loads the exported struct types and enums of a package, or only
+// those named in wanted if it is not nil.
+func (l *ASTLoader) loadPackage(pkg *packages.Package, wanted map[string]bool) (Package, error) {
+	pkgPath := pkg.PkgPath
+
+	l.logger.Debug("parsing package", "package", pkgPath)
+
+	l.logger.Debug("extracting package docs", "package", pkgPath)
+	pkgDoc := astutils.PackageDoc(pkgPath, pkg.Syntax, pkg.Fset)
+	reg := parseRegistration(pkg)
+	l.logger.Debug("parsed registration", "package", pkgPath, "group", reg.Group, "version", reg.Version, "kinds", len(reg.Kinds))
+
+	exportedTypes := []Type{}
+	var enums []Enum
+	for _, file := range pkg.Syntax {
+		filePos := pkg.Fset.Position(file.Pos())
+		l.logger.Debug("parsing file", "package", pkgPath, "file", filePos.Filename)
+
+		l.logger.Debug("sorting comments", "package", pkgPath, "file", filePos.Filename)
+		sortedComments := astutils.SortCommentsByPos(file.Comments)
+		l.logger.Debug("sorting objects", "package", pkgPath, "file", filePos.Filename)
+		sortedObjects := astutils.SortObjectsByPos(file.Scope.Objects)
+
+		for i, currentObj := range sortedObjects {
+			t, ok := currentObj.Decl.(*ast.TypeSpec)
+			if !ok || !t.Name.IsExported() {
+				continue
+			}
+			if wanted != nil && !wanted[t.Name.Name] {
+				continue
+			}
+			if t.TypeParams != nil && t.TypeParams.NumFields() > 0 {
+				l.logger.Debug("skipping generic type", "name", t.Name.Name)
+				continue
+			}
+			astStructType, ok := t.Type.(*ast.StructType)
+			if !ok {
+				if enum, ok := namedBasicType(pkg, pkgDoc, t); ok {
+					enums = append(enums, enum)
 				}
-				l.logger.Debug("loaded struct type", "name", t.Name.Name)
-
-				structFields := make([]Field, 0, structType.NumFields())
+				continue
+			}
 
-				for j := 0; j < structType.NumFields(); j++ {
-					fld := structType.Field(j)
-					if !fld.IsField() || !fld.Exported() {
-						continue
-					}
+			typ, ok := pkg.TypesInfo.Types[t.Type]
+			if !ok {
+				return Package{}, errors.Errorf("unable to load struct type: %s", t.Name.Name)
+			}
+			structType, ok := typ.Type.(*types.Struct)
+			if !ok {
+				continue
+			}
+			l.logger.Debug("loaded struct type", "name", t.Name.Name)
 
-					jsonProperty := fld.Name()
-					required := true
-					fldTag := structType.Tag(j)
-					tags, err := ParseStructTags(fldTag)
-					if err != nil {
-						return nil, errors.Wrapf(err, "failed to parse struct tag `%s`", fldTag)
-					}
+			structFields := make([]Field, 0, structType.NumFields())
 
-					for _, t := range tags {
-						if t.Name == "json" {
-							split := strings.Split(t.Value, ",")
-							jsonProperty = split[0]
-							for _, tagValue := range split[1:] {
-								if tagValue == "omitempty" {
-									required = false
-									break
-								}
-							}
-							break
-						}
-					}
+			for j := 0; j < structType.NumFields(); j++ {
+				fld := structType.Field(j)
+				if !fld.IsField() || !fld.Exported() {
+					continue
+				}
 
-					if jsonProperty == "-" {
-						l.logger.Debug("ignoring struct field as not serialized", "struct", t.Name.Name, "field", fld.Name())
-						continue
-					}
+				jsonProperty := fld.Name()
+				required := true
+				fldTag := structType.Tag(j)
+				tags, err := ParseStructTags(fldTag)
+				if err != nil {
+					return Package{}, errors.Wrapf(err, "failed to parse struct tag `%s`", fldTag)
+				}
 
-					l.logger.Debug("adding struct field", "struct", t.Name.Name, "field", fld.Name(), "type", fld.Type().String())
-					fldDoc := ""
-					if j < astStructType.Fields.NumFields() {
-						fldDoc = strings.TrimSpace(astStructType.Fields.List[j].Doc.Text())
-						docLines := strings.Split(fldDoc, "\n")
-						for i := len(docLines) - 1; i >= 0; i-- {
-							if strings.HasPrefix(strings.TrimSpace(docLines[i]), "+optional") {
+				for _, t := range tags {
+					if t.Name == "json" {
+						split := strings.Split(t.Value, ",")
+						jsonProperty = split[0]
+						for _, tagValue := range split[1:] {
+							if tagValue == "omitempty" {
 								required = false
+								break
 							}
 						}
+						break
 					}
-
-					typeName := fld.Type().String()
-					if idx := strings.Index(typeName, "vendor/"); idx > -1 {
-						typeName = typeName[idx+len("vendor/"):]
-					}
-					f := Field{
-						Name:         fld.Name(),
-						Doc:          fldDoc,
-						Type:         fld.Type(),
-						TypeName:     typeName,
-						Anonymous:    fld.Anonymous(),
-						JSONProperty: jsonProperty,
-						JSONRequired: required,
-					}
-					structFields = append(structFields, f)
-					l.logger.Debug("added struct field definition", "struct", t.Name.Name, "field", f)
 				}
 
-				if len(structFields) == 0 {
+				if jsonProperty == "-" {
+					l.logger.Debug("ignoring struct field as not serialized", "struct", t.Name.Name, "field", fld.Name())
 					continue
 				}
 
-				var previousObj *ast.Object
-				if 0 < i {
-					previousObj = sortedObjects[i-1]
+				l.logger.Debug("adding struct field", "struct", t.Name.Name, "field", fld.Name(), "type", fld.Type().String())
+				fldDoc := ""
+				var fldMarkers Markers
+				if j < astStructType.Fields.NumFields() {
+					fldDoc = strings.TrimSpace(astStructType.Fields.List[j].Doc.Text())
+					fldMarkers = ParseMarkers(fldDoc)
+					if fldMarkers.Optional() {
+						required = false
+					}
+				}
+
+				typeName := StripVendor(fld.Type().String())
+				f := Field{
+					Name:         fld.Name(),
+					Doc:          fldDoc,
+					Type:         fld.Type(),
+					TypeName:     typeName,
+					Anonymous:    fld.Anonymous(),
+					JSONProperty: jsonProperty,
+					JSONRequired: required,
+					Markers:      fldMarkers,
+					Position:     pkg.Fset.Position(fld.Pos()),
 				}
-				shouldGenerateClient, namespacedType := extractGenerateClient(currentObj, previousObj, prog.Fset, sortedComments)
-
-				apiType := Type{
-					Name:           currentObj.Name,
-					Package:        pkgPath,
-					Doc:            strings.TrimSpace(astutils.TypeDoc(pkgDoc, currentObj.Name)),
-					GenerateClient: shouldGenerateClient,
-					Namespaced:     namespacedType,
-					Fields:         structFields,
+				if len(tags) > 0 {
+					f.Tags = tags
 				}
-				exportedTypes = append(exportedTypes, apiType)
+				structFields = append(structFields, f)
+				l.logger.Debug("added struct field definition", "struct", t.Name.Name, "field", f)
 			}
-		}
 
-		if len(exportedTypes) == 0 {
-			l.logger.Debug("skipping package - no exported types", "package", pkgPath)
-			continue
-		}
+			if len(structFields) == 0 {
+				continue
+			}
 
-		loadedPackage := Package{
-			Path:  pkg.Pkg.Path(),
-			Types: exportedTypes,
-			Doc:   pkgDoc.Doc,
+			var previousObj *ast.Object
+			if 0 < i {
+				previousObj = sortedObjects[i-1]
+			}
+			typeMarkers := extractMarkers(currentObj, previousObj, file, pkg.Fset, sortedComments)
+			client := typeMarkers.Client()
+
+			apiType := Type{
+				Name:           currentObj.Name,
+				Package:        pkgPath,
+				Doc:            strings.TrimSpace(astutils.TypeDoc(pkgDoc, currentObj.Name)),
+				GenerateClient: client.Generate,
+				Namespaced:     client.Generate && !client.NonNamespaced,
+				Markers:        typeMarkers,
+				Fields:         structFields,
+				Group:          reg.Group,
+				Version:        reg.Version,
+				Kind:           reg.Kinds[currentObj.Name],
+				Position:       pkg.Fset.Position(t.Name.Pos()),
+			}
+			exportedTypes = append(exportedTypes, apiType)
 		}
-		loadedPackages = append(loadedPackages, loadedPackage)
 	}
 
-	return loadedPackages, nil
+	enums = enumValues(pkg, enums)
+
+	return Package{
+		Path:    pkgPath,
+		Types:   exportedTypes,
+		Enums:   enums,
+		Doc:     pkgDoc.Doc,
+		Group:   reg.Group,
+		Version: reg.Version,
+	}, nil
 }
 
-func extractGenerateClient(current *ast.Object, previous *ast.Object, fset *token.FileSet, comments []*ast.CommentGroup) (bool, bool) {
-	previousLineNumber := 0
+// extractMarkers parses the markers from the comment groups right above the
+// declaration of current: its doc comment, and the groups that each end at
+// most one blank line above the next, such as +genclient. Comments before the
+// previous object, the package clause or the imports are never taken, so the
+// first type of a file does not take the markers of the file header.
+func extractMarkers(current *ast.Object, previous *ast.Object, file *ast.File, fset *token.FileSet, comments []*ast.CommentGroup) Markers {
+	previousLineNumber := fset.Position(file.Name.End()).Line
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT && gen.End() < current.Pos() {
+			previousLineNumber = max(previousLineNumber, fset.Position(gen.End()).Line)
+		}
+	}
 	if previous != nil {
 		if n, ok := previous.Decl.(ast.Node); ok {
-			previousLineNumber = fset.Position(n.End()).Line
+			previousLineNumber = max(previousLineNumber, fset.Position(n.End()).Line)
 		}
 	}
 
-	previousCommentIndex := sort.Search(len(comments), func(i int) bool {
-		commentLineNumber := fset.Position(comments[i].Pos()).Line
-		return previousLineNumber < commentLineNumber
+	lineNumber := fset.Position(current.Pos()).Line
+	i := sort.Search(len(comments), func(i int) bool {
+		return fset.Position(comments[i].Pos()).Line >= lineNumber
 	})
 
-	if previousCommentIndex >= len(comments) {
-		return false, false
-	}
-
-	currentPos := fset.Position(current.Pos())
-	currentLineNumber := currentPos.Line
-	i := previousCommentIndex
-	for {
-		if i == len(comments) {
-			return false, false
-		}
-		commentLineNumber := fset.Position(comments[i].Pos()).Line
-		if commentLineNumber >= currentLineNumber {
-			return false, false
-		}
-		if strings.HasPrefix(strings.TrimSpace(comments[i].Text()), "+genclient") {
-			previousCommentIndex = i
+	var groups []*ast.CommentGroup
+	for i--; i >= 0; i-- {
+		start, end := fset.Position(comments[i].Pos()).Line, fset.Position(comments[i].End()).Line
+		if start <= previousLineNumber || end < lineNumber-2 {
 			break
 		}
-		i++
+		groups = append(groups, comments[i])
+		lineNumber = start
 	}
 
-	spl := strings.Split(strings.TrimSpace(comments[previousCommentIndex].Text()[1:]), ",")
-
-	var (
-		genClient  = false
-		namespaced = true
-	)
-
-	if len(spl) > 0 {
-		genClientSpl := strings.Split(spl[0], "=")
-		genClient = len(genClientSpl) == 2 && genClientSpl[0] == "genclient" && genClientSpl[1] == "true"
-	}
-	if len(spl) > 1 {
-		nonNamespacedSpl := strings.Split(spl[1], "=")
-		namespaced = len(nonNamespacedSpl) != 2 || nonNamespacedSpl[0] != "nonNamespaced" || nonNamespacedSpl[1] != "true"
+	var markers Markers
+	for i := len(groups) - 1; i >= 0; i-- {
+		markers = append(markers, ParseMarkers(groups[i].Text())...)
 	}
-
-	return genClient, namespaced
+	return markers
 }
 
 type StructTag struct {
@@ -294,6 +493,16 @@ func (tags StructTags) Has(name string) bool {
 	return false
 }
 
+// Get returns the value of the named tag and whether it is present.
+func (tags StructTags) Get(name string) (string, bool) {
+	for i := range tags {
+		if tags[i].Name == name {
+			return tags[i].Value, true
+		}
+	}
+	return "", false
+}
+
 // ParseStructTags returns the full set of fields in a struct tag in the order they appear in
 // the struct tag.
 func ParseStructTags(tag string) (StructTags, error) {
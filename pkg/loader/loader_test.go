@@ -1,11 +1,15 @@
 package loader_test
 
 import (
+	"go/token"
 	"go/types"
+	"path"
+	"path/filepath"
 
 	"github.com/inconshreveable/log15"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
 	. "github.com/jimmidyson/kube-client-gen/pkg/loader"
@@ -24,6 +28,20 @@ func typeFromPackage(pkg Package, typeName, fieldName string) types.Type {
 	return nil
 }
 
+// withoutPositions clears the source positions of types and fields, which are
+// checked separately.
+func withoutPositions(pkgs []Package) []Package {
+	for i := range pkgs {
+		for j := range pkgs[i].Types {
+			pkgs[i].Types[j].Position = token.Position{}
+			for k := range pkgs[i].Types[j].Fields {
+				pkgs[i].Types[j].Fields[k].Position = token.Position{}
+			}
+		}
+	}
+	return pkgs
+}
+
 var _ = Describe("Loader", func() {
 	var logger log15.Logger
 
@@ -43,7 +61,7 @@ var _ = Describe("Loader", func() {
 		pkgs, err := loader.Load()
 		Expect(err).NotTo(HaveOccurred())
 		Expect(pkgs).To(HaveLen(1))
-		Expect(pkgs).To(Equal([]Package{
+		Expect(withoutPositions(pkgs)).To(Equal([]Package{
 			{
 				Path: "github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg1",
 				Types: []Type{
@@ -52,29 +70,370 @@ var _ = Describe("Loader", func() {
 						Package: "github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg1",
 						Fields: []Field{
 							{Name: "Field1", Doc: "Some doc.", Anonymous: false, JSONRequired: true, JSONProperty: "Field1", Type: types.Typ[types.Int], TypeName: "int"},
-							{Name: "Field2", Doc: "", Anonymous: false, JSONRequired: true, JSONProperty: "f2", Type: types.Typ[types.String], TypeName: "string"},
-							{Name: "Field4", Doc: "Even more doc.", Anonymous: false, JSONRequired: false, JSONProperty: "", Type: types.NewSlice(types.Typ[types.String]), TypeName: "[]string"},
-							{Name: "Field5", Doc: "And some\nmore doc.", Anonymous: false, JSONRequired: false, JSONProperty: "f5", Type: types.NewMap(types.Typ[types.String], types.Typ[types.Bool]), TypeName: "map[string]bool"},
-							{Name: "Type5", Doc: "", Anonymous: true, JSONRequired: false, JSONProperty: "", Type: typeFromPackage(pkgs[0], "Type1", "Type5"), TypeName: "github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg1.Type5"},
-							{Name: "Type5s", Doc: "", JSONRequired: false, JSONProperty: "t5s", Type: typeFromPackage(pkgs[0], "Type1", "Type5s"), TypeName: "[]github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg1.Type5"},
+							{Name: "Field2", Doc: "", Anonymous: false, JSONRequired: true, JSONProperty: "f2", Type: types.Typ[types.String], TypeName: "string", Tags: StructTags{{Name: "json", Value: "f2"}}},
+							{Name: "Field4", Doc: "Even more doc.", Anonymous: false, JSONRequired: false, JSONProperty: "", Type: types.NewSlice(types.Typ[types.String]), TypeName: "[]string", Tags: StructTags{{Name: "json", Value: ",omitempty"}}},
+							{Name: "Field5", Doc: "And some\nmore doc.", Anonymous: false, JSONRequired: false, JSONProperty: "f5", Type: types.NewMap(types.Typ[types.String], types.Typ[types.Bool]), TypeName: "map[string]bool", Tags: StructTags{{Name: "json", Value: "f5,omitempty"}}},
+							{Name: "Type5", Doc: "", Anonymous: true, JSONRequired: false, JSONProperty: "", Type: typeFromPackage(pkgs[0], "Type1", "Type5"), TypeName: "github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg1.Type5", Tags: StructTags{{Name: "json", Value: ",omitempty"}}},
+							{Name: "Type5s", Doc: "", JSONRequired: false, JSONProperty: "t5s", Type: typeFromPackage(pkgs[0], "Type1", "Type5s"), TypeName: "[]github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg1.Type5", Tags: StructTags{{Name: "json", Value: "t5s,omitempty"}}},
 						},
 						Doc:            "Type1 is a normal type\nwith a single field and a description.",
 						GenerateClient: true,
 						Namespaced:     true,
+						Markers:        Markers{{Name: "genclient", Value: "true"}},
 					},
 					{
 						Name:    "Type5",
 						Package: "github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg1",
 						Fields: []Field{
-							{Name: "Type5Field", Doc: "Something.", Anonymous: false, JSONRequired: true, JSONProperty: "t5", Type: types.Typ[types.Uint32], TypeName: "uint32"},
-							{Name: "Type5Field2", Doc: "Something else.", Anonymous: false, JSONRequired: true, JSONProperty: "t6", Type: types.NewSlice(types.Typ[types.Uint32]), TypeName: "[]uint32"},
+							{Name: "Type5Field", Doc: "Something.", Anonymous: false, JSONRequired: true, JSONProperty: "t5", Type: types.Typ[types.Uint32], TypeName: "uint32", Tags: StructTags{{Name: "json", Value: "t5"}}},
+							{Name: "Type5Field2", Doc: "Something else.", Anonymous: false, JSONRequired: true, JSONProperty: "t6", Type: types.NewSlice(types.Typ[types.Uint32]), TypeName: "[]uint32", Tags: StructTags{{Name: "json", Value: "t6"}}},
 						},
 						Doc:            "",
 						GenerateClient: true,
 						Namespaced:     false,
+						Markers:        Markers{{Name: "genclient", Value: "true,nonNamespaced=true"}},
+					},
+				},
+			},
+		}))
+	})
+
+	It("records where types and fields are declared", func() {
+		loader := New([]string{"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg1"}, logger)
+		pkgs, err := loader.Load()
+		Expect(err).NotTo(HaveOccurred())
+		typ := pkgs[0].Types[0]
+		Expect(filepath.Base(typ.Position.Filename)).To(Equal("file1.go"))
+		Expect(typ.Position.Line).To(Equal(7))
+		Expect(filepath.Base(typ.Fields[0].Position.Filename)).To(Equal("file1.go"))
+		Expect(typ.Fields[0].Position.Line).To(Equal(9))
+	})
+
+	It("parses packages from other Go modules that use generics", func() {
+		loader := New([]string{"example.com/module/apis/v1"}, logger, WithDir("testdata/module"))
+		pkgs, err := loader.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(withoutPositions(pkgs)).To(Equal([]Package{
+			{
+				Path: "example.com/module/apis/v1",
+				Types: []Type{
+					{
+						Name:    "Widget",
+						Package: "example.com/module/apis/v1",
+						Fields: []Field{
+							{Name: "Name", Doc: "Name of the widget.", Anonymous: false, JSONRequired: true, JSONProperty: "name", Type: types.Typ[types.String], TypeName: "string", Tags: StructTags{{Name: "json", Value: "name"}}},
+							{Name: "Labels", Doc: "", Anonymous: false, JSONRequired: false, JSONProperty: "labels", Type: types.NewMap(types.Typ[types.String], types.Typ[types.String]), TypeName: "map[string]string", Tags: StructTags{{Name: "json", Value: "labels,omitempty"}}},
+						},
+						Doc:        "Widget is a type loaded from a separate Go module.",
+						Namespaced: false,
 					},
 				},
 			},
 		}))
 	})
+
+	It("parses enums and their constants", func() {
+		loader := New([]string{"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg2"}, logger)
+		pkgs, err := loader.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		phase := Enum{
+			Name:    "Phase",
+			Package: "github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg2",
+			Doc:     "Phase is the phase of a widget.",
+			Type:    types.Typ[types.String],
+			Values: []EnumValue{
+				{Name: "PhasePending", Value: "Pending", Doc: "PhasePending means the widget has not started."},
+				{Name: "PhaseRunning", Value: "Running", Doc: "PhaseRunning means the widget is running."},
+			},
+		}
+		priority := Enum{
+			Name:    "Priority",
+			Package: "github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg2",
+			Doc:     "Priority is the priority of a widget.",
+			Type:    types.Typ[types.Int32],
+			Values: []EnumValue{
+				{Name: "PriorityHigh", Value: "10", Doc: "PriorityHigh is the highest priority."},
+			},
+		}
+		Expect(pkgs[0].Enums).To(Equal([]Enum{phase, priority}))
+
+		Expect(pkgs[0].Types).To(HaveLen(1))
+		fields := pkgs[0].Types[0].Fields
+		Expect(fields).To(HaveLen(3))
+		Expect(fields[0].Enum).To(Equal(&phase))
+		Expect(fields[1].Enum).To(Equal(&priority))
+		Expect(fields[2].Enum).To(BeNil())
+	})
+
+	It("loads markers for types and fields", func() {
+		loader := New([]string{"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg3"}, logger)
+		pkgs, err := loader.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		cluster := pkgs[0].Types[0]
+		Expect(cluster.Name).To(Equal("Cluster"))
+		Expect(cluster.GenerateClient).To(BeTrue())
+		Expect(cluster.Namespaced).To(BeFalse())
+		Expect(cluster.Markers.Client().OnlyVerbs).To(Equal([]string{"get", "list"}))
+		scope, ok := cluster.Markers.Get("kubebuilder:resource:scope")
+		Expect(ok).To(BeTrue())
+		Expect(scope).To(Equal("Cluster"))
+
+		Expect(cluster.Fields).To(HaveLen(3))
+		name := cluster.Fields[0].Markers.Validation()
+		Expect(name.MaxLength).To(Equal(int64Ptr(63)))
+		Expect(name.Pattern).To(Equal("^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"))
+		Expect(name.Required).To(BeTrue())
+
+		nodes := cluster.Fields[1]
+		Expect(nodes.JSONRequired).To(BeFalse())
+		Expect(nodes.Markers.ListType()).To(Equal("map"))
+		Expect(nodes.Markers.ListMapKeys()).To(Equal([]string{"name"}))
+		Expect(nodes.Markers.PatchStrategy()).To(Equal("merge"))
+		Expect(nodes.Markers.PatchMergeKey()).To(Equal("name"))
+
+		replicas := cluster.Fields[2].Markers
+		Expect(replicas.Validation().Minimum).To(Equal(float64Ptr(1)))
+		Expect(replicas.Validation().Maximum).To(Equal(float64Ptr(10)))
+		def, ok := replicas.Default()
+		Expect(ok).To(BeTrue())
+		Expect(def).To(Equal("3"))
+	})
+
+	It("takes type markers only from the comments right above a type", func() {
+		loader := New([]string{"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg3"}, logger)
+		pkgs, err := loader.Load()
+		Expect(err).NotTo(HaveOccurred())
+
+		markers := map[string]Markers{}
+		for _, typ := range pkgs[0].Types {
+			markers[typ.Name] = typ.Markers
+		}
+		Expect(markers).To(HaveKeyWithValue("Machine", Markers{{Name: "kubebuilder:resource:path", Value: "machines"}}))
+		Expect(markers).To(HaveKeyWithValue("MachineSet", Markers{{Name: "kubebuilder:resource:scope", Value: "Cluster"}}))
+	})
+
+	It("keeps all struct tags on fields", func() {
+		loader := New([]string{"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg4"}, logger)
+		pkgs, err := loader.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		pod := pkgs[0].Types[0]
+		Expect(pod.Name).To(Equal("Pod"))
+		Expect(pod.Fields).To(HaveLen(6))
+		Expect(pod.Fields[0].Tags).To(Equal(StructTags{
+			{Name: "json", Value: "name"},
+			{Name: "protobuf", Value: "bytes,1,opt,name=name"},
+		}))
+		protobuf, ok := pod.Fields[5].Tags.Get("protobuf")
+		Expect(ok).To(BeTrue())
+		Expect(protobuf).To(Equal("-"))
+		_, ok = pod.Fields[5].Tags.Get("protobuf_key")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("parses group, version and kinds from scheme registrations", func() {
+		loader := New([]string{"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg5"}, logger)
+		pkgs, err := loader.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		pkg := pkgs[0]
+		Expect(pkg.Group).To(Equal("apps.example.com"))
+		Expect(pkg.Version).To(Equal("v1beta1"))
+		Expect(pkg.APIVersion()).To(Equal("apps.example.com/v1beta1"))
+
+		kinds := map[string]string{}
+		for _, typ := range pkg.Types {
+			Expect(typ.APIVersion()).To(Equal("apps.example.com/v1beta1"))
+			kinds[typ.Name] = typ.Kind
+		}
+		Expect(kinds).To(Equal(map[string]string{
+			"Deployment":     "Deployment",
+			"DeploymentList": "DeploymentList",
+			"Options":        "DeploymentOptions",
+			"DeploymentSpec": "",
+		}))
+	})
+
+	DescribeTable("names REST resources after their types",
+		func(name, resource string) {
+			Expect(Type{Name: name}.Resource()).To(Equal(resource))
+		},
+		Entry("regular", "Pod", "pods"),
+		Entry("s", "Ingress", "ingresses"),
+		Entry("ch", "Patch", "patches"),
+		Entry("y", "NetworkPolicy", "networkpolicies"),
+		Entry("vowel y", "Gateway", "gateways"),
+		Entry("already plural", "Endpoints", "endpoints"),
+		Entry("already plural last word", "ServiceEndpoints", "serviceendpoints"),
+		Entry("plural only", "SecurityContextConstraints", "securitycontextconstraints"),
+		Entry("irregular", "Person", "people"),
+		Entry("irregular last word", "SalesPerson", "salespeople"),
+		Entry("irregular inside a word", "Salesperson", "salespersons"),
+	)
+
+	DescribeTable("pluralizes type names keeping their case",
+		func(name, plural string) {
+			Expect(Plural(name)).To(Equal(plural))
+		},
+		Entry("regular", "Pod", "Pods"),
+		Entry("y", "NetworkPolicy", "NetworkPolicies"),
+		Entry("already plural", "Endpoints", "Endpoints"),
+		Entry("irregular last word", "SalesPerson", "SalesPeople"),
+	)
+
+	DescribeTable("names vendored packages as their upstream packages",
+		func(pkgPath, stripped string) {
+			Expect(StripVendor(pkgPath)).To(Equal(stripped))
+		},
+		Entry("not vendored", "k8s.io/kubernetes/pkg/api/v1", "k8s.io/kubernetes/pkg/api/v1"),
+		Entry("vendored", "github.com/openshift/origin/vendor/k8s.io/kubernetes/pkg/api/v1", "k8s.io/kubernetes/pkg/api/v1"),
+		Entry("qualified type name", "example.com/app/vendor/k8s.io/api/core/v1.Pod", "k8s.io/api/core/v1.Pod"),
+	)
+
+	It("names REST resources after resource markers", func() {
+		Expect(Type{Name: "Endpoint", Markers: ParseMarkers("+resourceName=endpoints")}.Resource()).To(Equal("endpoints"))
+		Expect(Type{Name: "Fox", Markers: ParseMarkers("+kubebuilder:resource:path=foxen")}.Resource()).To(Equal("foxen"))
+	})
+
+	It("parses group and version from package markers", func() {
+		loader := New([]string{"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg3"}, logger)
+		pkgs, err := loader.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+		Expect(pkgs[0].APIVersion()).To(Equal("markers.example.com/v1alpha1"))
+		Expect(pkgs[0].Types[0].Kind).To(BeEmpty())
+	})
+
+	It("only loads requested packages without the type closure", func() {
+		loader := New([]string{"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg6"}, logger)
+		pkgs, err := loader.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+		Expect(pkgs[0].Path).To(Equal("github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg6"))
+	})
+
+	It("adds referenced types from other packages with the type closure", func() {
+		loader := New([]string{"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg6"}, logger, WithTypeClosure())
+		pkgs, err := loader.Load()
+		Expect(err).NotTo(HaveOccurred())
+
+		loaded := map[string][]string{}
+		transitive := map[string]bool{}
+		for _, pkg := range pkgs {
+			transitive[pkg.Path] = pkg.Transitive
+			for _, typ := range pkg.Types {
+				Expect(typ.Package).To(Equal(pkg.Path))
+				loaded[pkg.Path] = append(loaded[pkg.Path], typ.Name)
+			}
+		}
+		Expect(loaded).To(Equal(map[string][]string{
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg4": {"Pod", "Container"},
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg5": {"DeploymentList", "Deployment"},
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg6": {"Cluster"},
+		}))
+		Expect(transitive).To(Equal(map[string]bool{
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg4": true,
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg5": true,
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg6": false,
+		}))
+
+		for _, pkg := range pkgs {
+			if pkg.Path == "github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg5" {
+				Expect(pkg.APIVersion()).To(Equal("apps.example.com/v1beta1"))
+				Expect(pkg.Types[0].Kind).To(Equal("DeploymentList"))
+			}
+		}
+	})
+
+	It("selects packages with the transitive types they refer to", func() {
+		loader := New([]string{
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg6",
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg7",
+		}, logger, WithTypeClosure())
+		pkgs, err := loader.Load()
+		Expect(err).NotTo(HaveOccurred())
+
+		selected := func(paths ...string) map[string][]string {
+			res := map[string][]string{}
+			for _, pkg := range Select(pkgs, paths) {
+				for _, typ := range pkg.Types {
+					res[path.Base(pkg.Path)] = append(res[path.Base(pkg.Path)], typ.Name)
+				}
+			}
+			return res
+		}
+		Expect(selected("github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg6")).To(Equal(map[string][]string{
+			"pkg4": {"Pod", "Container"},
+			"pkg5": {"DeploymentList", "Deployment"},
+			"pkg6": {"Cluster"},
+		}))
+		Expect(selected("github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg7")).To(Equal(map[string][]string{
+			"pkg7": {"Event"},
+		}))
+	})
+
+	DescribeTable("filters types by pattern",
+		func(opts []Option, expected []string) {
+			loader := New([]string{"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg5"}, logger, opts...)
+			pkgs, err := loader.Load()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pkgs).To(HaveLen(1))
+
+			var names []string
+			for _, typ := range pkgs[0].Types {
+				names = append(names, typ.Name)
+			}
+			Expect(names).To(Equal(expected))
+		},
+		Entry("include", []Option{WithIncludes("*/pkg5.Deployment*")}, []string{"Deployment", "DeploymentList", "DeploymentSpec"}),
+		Entry("exclude", []Option{WithExcludes("*.Deployment*")}, []string{"Options"}),
+		Entry("include and exclude", []Option{WithIncludes("*/pkg5.Deployment*"), WithExcludes("*.Deployment?*")}, []string{"Deployment"}),
+		Entry("root kind", []Option{WithRoots("DeploymentList")}, []string{"Deployment", "DeploymentList"}),
+		Entry("root kind pattern", []Option{WithRoots("Deployment*")}, []string{"Deployment", "DeploymentList", "Options"}),
+	)
+
+	It("classifies free-form JSON and embedded object fields", func() {
+		pkgs, err := New([]string{"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg7"}, logger).Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		kinds := map[string]DynamicKind{}
+		for _, fld := range pkgs[0].Types[0].Fields {
+			kinds[fld.Name] = Dynamic(fld.Type)
+		}
+		Expect(kinds).To(Equal(map[string]DynamicKind{
+			"Object":    EmbeddedObject,
+			"Raw":       EmbeddedObject,
+			"Kinded":    EmbeddedObject,
+			"Value":     ArbitraryJSON,
+			"Payload":   ArbitraryJSON,
+			"Props":     NotDynamic,
+			"Name":      NotDynamic,
+			"Reference": NotDynamic,
+		}))
+	})
+
+	It("keeps only types reachable from roots", func() {
+		loader := New([]string{"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg6"}, logger,
+			WithTypeClosure(), WithRoots("*/pkg6.Cluster"), WithExcludes("*/pkg4.Pod"))
+		pkgs, err := loader.Load()
+		Expect(err).NotTo(HaveOccurred())
+
+		loaded := map[string][]string{}
+		for _, pkg := range pkgs {
+			for _, typ := range pkg.Types {
+				loaded[pkg.Path] = append(loaded[pkg.Path], typ.Name)
+			}
+		}
+		Expect(loaded).To(Equal(map[string][]string{
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg5": {"DeploymentList", "Deployment"},
+			"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg6": {"Cluster"},
+		}))
+	})
 })
@@ -0,0 +1,297 @@
+package loader
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Marker is a single comment marker such as +optional, +listType=map or
+// +kubebuilder:validation:MaxLength=63. Value holds everything after the
+// first '=' and is empty for markers without a value.
+type Marker struct {
+	Name  string
+	Value string
+}
+
+type Markers []Marker
+
+// ParseMarkers returns the markers found in the given comment text, in the
+// order they appear. Each marker must be on its own line.
+func ParseMarkers(text string) Markers {
+	var markers Markers
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "+") {
+			continue
+		}
+		line = line[1:]
+		if r, _ := utf8.DecodeRuneInString(line); !unicode.IsLetter(r) {
+			continue
+		}
+		name, value := line, ""
+		if i := strings.Index(line, "="); i >= 0 {
+			name, value = line[:i], strings.TrimSpace(line[i+1:])
+		}
+		if strings.ContainsAny(name, " \t") {
+			continue
+		}
+		markers = append(markers, Marker{Name: name, Value: value})
+	}
+	return markers
+}
+
+func (m Markers) Has(name string) bool {
+	_, ok := m.Get(name)
+	return ok
+}
+
+// Get returns the value of the last marker with the given name.
+func (m Markers) Get(name string) (string, bool) {
+	for i := len(m) - 1; i >= 0; i-- {
+		if m[i].Name == name {
+			return m[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// All returns the values of every marker with the given name.
+func (m Markers) All(name string) []string {
+	var values []string
+	for _, marker := range m {
+		if marker.Name == name {
+			values = append(values, marker.Value)
+		}
+	}
+	return values
+}
+
+// Optional reports whether the markers flag a field as optional.
+func (m Markers) Optional() bool {
+	return m.Has("optional") || m.Has("kubebuilder:validation:Optional")
+}
+
+// Required reports whether the markers flag a field as required.
+func (m Markers) Required() bool {
+	return m.Has("required") || m.Has("kubebuilder:validation:Required")
+}
+
+// Default returns the raw default value from a +default or
+// +kubebuilder:default marker.
+func (m Markers) Default() (string, bool) {
+	if v, ok := m.Get("default"); ok {
+		return v, true
+	}
+	return m.Get("kubebuilder:default")
+}
+
+func (m Markers) ListType() string {
+	v, _ := m.Get("listType")
+	return v
+}
+
+func (m Markers) ListMapKeys() []string {
+	return m.All("listMapKey")
+}
+
+func (m Markers) PatchStrategy() string {
+	v, _ := m.Get("patchStrategy")
+	return v
+}
+
+func (m Markers) PatchMergeKey() string {
+	v, _ := m.Get("patchMergeKey")
+	return v
+}
+
+// Validation holds the constraints from +kubebuilder:validation markers.
+// Unset numeric constraints are nil.
+type Validation struct {
+	MaxLength        *int64
+	MinLength        *int64
+	MaxItems         *int64
+	MinItems         *int64
+	Maximum          *float64
+	Minimum          *float64
+	ExclusiveMaximum bool
+	ExclusiveMinimum bool
+	MultipleOf       *float64
+	Pattern          string
+	Format           string
+	Enum             []string
+	UniqueItems      bool
+	Nullable         bool
+	Required         bool
+	Optional         bool
+}
+
+const validationPrefix = "kubebuilder:validation:"
+
+func (m Markers) Validation() Validation {
+	v := Validation{
+		Required: m.Required(),
+		Optional: m.Optional(),
+	}
+	for _, marker := range m {
+		if !strings.HasPrefix(marker.Name, validationPrefix) {
+			continue
+		}
+		value := unquoteMarkerValue(marker.Value)
+		switch strings.TrimPrefix(marker.Name, validationPrefix) {
+		case "MaxLength":
+			v.MaxLength = parseInt(value)
+		case "MinLength":
+			v.MinLength = parseInt(value)
+		case "MaxItems":
+			v.MaxItems = parseInt(value)
+		case "MinItems":
+			v.MinItems = parseInt(value)
+		case "Maximum":
+			v.Maximum = parseFloat(value)
+		case "Minimum":
+			v.Minimum = parseFloat(value)
+		case "ExclusiveMaximum":
+			v.ExclusiveMaximum = value != "false"
+		case "ExclusiveMinimum":
+			v.ExclusiveMinimum = value != "false"
+		case "MultipleOf":
+			v.MultipleOf = parseFloat(value)
+		case "Pattern":
+			v.Pattern = value
+		case "Format":
+			v.Format = value
+		case "Enum":
+			for _, e := range strings.Split(value, ";") {
+				v.Enum = append(v.Enum, unquoteMarkerValue(strings.TrimSpace(e)))
+			}
+		case "UniqueItems":
+			v.UniqueItems = value != "false"
+		case "Nullable":
+			v.Nullable = value != "false"
+		}
+	}
+	return v
+}
+
+// Client holds the client generation options from +genclient markers. Both
+// the +genclient:nonNamespaced form and the older
+// +genclient=true,nonNamespaced=true form are understood.
+type Client struct {
+	Generate      bool
+	NonNamespaced bool
+	NoVerbs       bool
+	NoStatus      bool
+	OnlyVerbs     []string
+	SkipVerbs     []string
+}
+
+func (m Markers) Client() Client {
+	var c Client
+	for _, marker := range m {
+		switch marker.Name {
+		case "genclient":
+			opts := strings.Split(marker.Value, ",")
+			c.Generate = opts[0] == "" || opts[0] == "true"
+			for _, opt := range opts[1:] {
+				if strings.TrimSpace(opt) == "nonNamespaced=true" {
+					c.NonNamespaced = true
+				}
+			}
+		case "genclient:nonNamespaced":
+			c.NonNamespaced = true
+		case "genclient:noVerbs":
+			c.NoVerbs = true
+		case "genclient:noStatus":
+			c.NoStatus = true
+		case "genclient:onlyVerbs":
+			c.OnlyVerbs = splitList(marker.Value)
+		case "genclient:skipVerbs":
+			c.SkipVerbs = splitList(marker.Value)
+		}
+	}
+	return c
+}
+
+// HasVerb reports whether a client should be generated with the given verb.
+func (c Client) HasVerb(verb string) bool {
+	if !c.Generate || c.NoVerbs {
+		return false
+	}
+	if len(c.OnlyVerbs) > 0 {
+		return containsString(c.OnlyVerbs, verb)
+	}
+	return !containsString(c.SkipVerbs, verb)
+}
+
+// ResourceName returns the name of the REST resource given by a
+// +resourceName marker or by the path argument of a +kubebuilder:resource
+// marker, such as +kubebuilder:resource:path=endpoints,scope=Namespaced.
+func (m Markers) ResourceName() (string, bool) {
+	if v, ok := m.Get("resourceName"); ok && v != "" {
+		return v, true
+	}
+	const resourcePrefix = "kubebuilder:resource:"
+	for i := len(m) - 1; i >= 0; i-- {
+		if !strings.HasPrefix(m[i].Name, resourcePrefix) {
+			continue
+		}
+		// The marker name holds the first argument, as in path=foos.
+		args := strings.TrimPrefix(m[i].Name, resourcePrefix) + "=" + m[i].Value
+		for _, arg := range strings.Split(args, ",") {
+			arg = strings.TrimSpace(arg)
+			if strings.HasPrefix(arg, "path=") && arg != "path=" {
+				return strings.TrimPrefix(arg, "path="), true
+			}
+		}
+	}
+	return "", false
+}
+
+func unquoteMarkerValue(v string) string {
+	if len(v) >= 2 && v[0] == '`' && v[len(v)-1] == '`' {
+		return v[1 : len(v)-1]
+	}
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		if s, err := strconv.Unquote(v); err == nil {
+			return s
+		}
+	}
+	return v
+}
+
+func parseInt(v string) *int64 {
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &i
+}
+
+func parseFloat(v string) *float64 {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+func splitList(v string) []string {
+	var list []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package loader_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	. "github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+var _ = Describe("Markers", func() {
+	DescribeTable("parsing markers",
+		func(text string, expected Markers) {
+			Expect(ParseMarkers(text)).To(Equal(expected))
+		},
+		Entry("no markers", "Some doc.\nMore doc.", nil),
+		Entry("marker without value", "Some doc.\n+optional", Markers{{Name: "optional"}}),
+		Entry("marker with value", "+listType=map", Markers{{Name: "listType", Value: "map"}}),
+		Entry("marker with namespaced name", "+genclient:onlyVerbs=get,list", Markers{{Name: "genclient:onlyVerbs", Value: "get,list"}}),
+		Entry("marker with = in value", "+kubebuilder:validation:Pattern=`^a=b$`", Markers{{Name: "kubebuilder:validation:Pattern", Value: "`^a=b$`"}}),
+		Entry("ignores non-marker plus lines", "+1 for this\n+ not a marker", nil),
+	)
+
+	It("extracts validation constraints", func() {
+		markers := ParseMarkers(`+kubebuilder:validation:MaxLength=63
++kubebuilder:validation:MinLength=1
++kubebuilder:validation:Pattern="^[a-z]+$"
++kubebuilder:validation:Minimum=0.5
++kubebuilder:validation:Maximum=10
++kubebuilder:validation:Enum=a;"b";c
++kubebuilder:validation:Required`)
+		Expect(markers.Validation()).To(Equal(Validation{
+			MaxLength: int64Ptr(63),
+			MinLength: int64Ptr(1),
+			Pattern:   "^[a-z]+$",
+			Minimum:   float64Ptr(0.5),
+			Maximum:   float64Ptr(10),
+			Enum:      []string{"a", "b", "c"},
+			Required:  true,
+		}))
+	})
+
+	DescribeTable("client markers",
+		func(text string, expected Client) {
+			Expect(ParseMarkers(text).Client()).To(Equal(expected))
+		},
+		Entry("no client", "Some doc.", Client{}),
+		Entry("genclient", "+genclient", Client{Generate: true}),
+		Entry("legacy genclient", "+genclient=true", Client{Generate: true}),
+		Entry("legacy non-namespaced genclient", "+genclient=true,nonNamespaced=true", Client{Generate: true, NonNamespaced: true}),
+		Entry("non-namespaced genclient", "+genclient\n+genclient:nonNamespaced", Client{Generate: true, NonNamespaced: true}),
+		Entry("no verbs", "+genclient\n+genclient:noVerbs", Client{Generate: true, NoVerbs: true}),
+		Entry("only verbs", "+genclient\n+genclient:onlyVerbs=create, get", Client{Generate: true, OnlyVerbs: []string{"create", "get"}}),
+	)
+
+	DescribeTable("resource name markers",
+		func(text string, resource string, ok bool) {
+			name, found := ParseMarkers(text).ResourceName()
+			Expect(found).To(Equal(ok))
+			Expect(name).To(Equal(resource))
+		},
+		Entry("no marker", "+genclient", "", false),
+		Entry("resourceName", "+genclient\n+resourceName=endpoints", "endpoints", true),
+		Entry("kubebuilder path first", "+kubebuilder:resource:path=foxes,scope=Cluster", "foxes", true),
+		Entry("kubebuilder path later", "+kubebuilder:resource:scope=Cluster,path=foxes,shortName=fx", "foxes", true),
+		Entry("kubebuilder without path", "+kubebuilder:resource:scope=Cluster", "", false),
+	)
+
+	It("filters client verbs", func() {
+		Expect(Client{Generate: true}.HasVerb("list")).To(BeTrue())
+		Expect(Client{Generate: true, NoVerbs: true}.HasVerb("list")).To(BeFalse())
+		Expect(Client{Generate: true, OnlyVerbs: []string{"get"}}.HasVerb("list")).To(BeFalse())
+		Expect(Client{Generate: true, SkipVerbs: []string{"list"}}.HasVerb("list")).To(BeFalse())
+		Expect(Client{}.HasVerb("list")).To(BeFalse())
+	})
+})
@@ -0,0 +1,157 @@
+package loader
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// registration is the API group, version and kinds a package registers with a
+// scheme, usually in its register.go.
+type registration struct {
+	Group   string
+	Version string
+	// Kinds maps the names of registered types to their kinds.
+	Kinds map[string]string
+}
+
+// parseRegistration reads the GroupName constant, the SchemeGroupVersion (or
+// controller-runtime style GroupVersion) variable and the types passed to
+// AddKnownTypes, AddKnownTypeWithName and scheme builder Register calls. The
+// +groupName and +versionName package markers are used when a package has no
+// such declarations.
+func parseRegistration(pkg *packages.Package) registration {
+	reg := registration{Kinds: map[string]string{}}
+	groupFound, versionFound := false, false
+
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || (gen.Tok != token.CONST && gen.Tok != token.VAR) {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if i >= len(vs.Values) {
+						break
+					}
+					switch name.Name {
+					case "GroupName":
+						if group, ok := stringValue(pkg.TypesInfo, vs.Values[i]); ok {
+							reg.Group, groupFound = group, true
+						}
+					case "SchemeGroupVersion", "GroupVersion":
+						lit, ok := vs.Values[i].(*ast.CompositeLit)
+						if !ok {
+							continue
+						}
+						for _, elt := range lit.Elts {
+							kv, ok := elt.(*ast.KeyValueExpr)
+							if !ok {
+								continue
+							}
+							key, ok := kv.Key.(*ast.Ident)
+							if !ok {
+								continue
+							}
+							value, ok := stringValue(pkg.TypesInfo, kv.Value)
+							if !ok {
+								continue
+							}
+							switch key.Name {
+							case "Group":
+								reg.Group, groupFound = value, true
+							case "Version":
+								reg.Version, versionFound = value, true
+							}
+						}
+					}
+				}
+			}
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			switch sel.Sel.Name {
+			case "AddKnownTypes":
+				if len(call.Args) > 1 {
+					addKinds(pkg, reg.Kinds, call.Args[1:])
+				}
+			case "Register":
+				addKinds(pkg, reg.Kinds, call.Args)
+			case "AddKnownTypeWithName":
+				if len(call.Args) != 2 {
+					return true
+				}
+				name, ok := registeredTypeName(pkg, call.Args[1])
+				if !ok {
+					return true
+				}
+				if withKind, ok := call.Args[0].(*ast.CallExpr); ok && len(withKind.Args) == 1 {
+					if kind, ok := stringValue(pkg.TypesInfo, withKind.Args[0]); ok {
+						reg.Kinds[name] = kind
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	if !groupFound || !versionFound {
+		for _, file := range pkg.Syntax {
+			markers := ParseMarkers(file.Doc.Text())
+			if group, ok := markers.Get("groupName"); ok && !groupFound {
+				reg.Group, groupFound = group, true
+			}
+			if version, ok := markers.Get("versionName"); ok && !versionFound {
+				reg.Version, versionFound = version, true
+			}
+		}
+	}
+
+	return reg
+}
+
+func addKinds(pkg *packages.Package, kinds map[string]string, args []ast.Expr) {
+	for _, arg := range args {
+		if name, ok := registeredTypeName(pkg, arg); ok {
+			kinds[name] = name
+		}
+	}
+}
+
+// registeredTypeName returns the name of the package's own type that an
+// expression such as &Pod{} instantiates.
+func registeredTypeName(pkg *packages.Package, expr ast.Expr) (string, bool) {
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = unary.X
+	}
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return "", false
+	}
+	named, ok := pkg.TypesInfo.TypeOf(lit).(*types.Named)
+	if !ok || named.Obj().Pkg() != pkg.Types {
+		return "", false
+	}
+	return named.Obj().Name(), true
+}
+
+func stringValue(info *types.Info, expr ast.Expr) (string, bool) {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
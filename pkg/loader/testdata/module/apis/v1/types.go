@@ -0,0 +1,21 @@
+package v1
+
+// Widget is a type loaded from a separate Go module.
+type Widget struct {
+	// Name of the widget.
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Page is a generic type so is not part of the model.
+type Page[T any] struct {
+	Items []T `json:"items"`
+}
+
+func firstN[T any](items []T, n int) []T {
+	res := make([]T, 0, min(n, len(items)))
+	for i := range min(n, len(items)) {
+		res = append(res, items[i])
+	}
+	return res
+}
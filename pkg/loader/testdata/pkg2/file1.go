@@ -0,0 +1,28 @@
+package pkg2
+
+// Phase is the phase of a widget.
+type Phase string
+
+// These are the valid phases of a widget.
+const (
+	// PhasePending means the widget has not started.
+	PhasePending Phase = "Pending"
+	PhaseRunning Phase = "Running" // PhaseRunning means the widget is running.
+	phaseUnknown Phase = "Unknown"
+)
+
+// Priority is the priority of a widget.
+type Priority int32
+
+// PriorityHigh is the highest priority.
+const PriorityHigh Priority = 10
+
+// Name has no constants so is not an enum.
+type Name string
+
+// Widget refers to enums.
+type Widget struct {
+	Phase      Phase      `json:"phase"`
+	Priorities []Priority `json:"priorities,omitempty"`
+	Name       Name       `json:"name"`
+}
@@ -0,0 +1,4 @@
+// Package pkg3 has types with markers.
+// +groupName=markers.example.com
+// +versionName=v1alpha1
+package pkg3
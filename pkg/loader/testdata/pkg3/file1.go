@@ -0,0 +1,33 @@
+package pkg3
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:onlyVerbs=get,list
+
+// Cluster is a cluster-scoped type with markers.
+// +kubebuilder:resource:scope=Cluster
+type Cluster struct {
+	// Name of the cluster.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Nodes in the cluster.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +patchStrategy=merge
+	// +patchMergeKey=name
+	Nodes []Node `json:"nodes"`
+
+	// Replicas to run.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	// +default=3
+	Replicas int32 `json:"replicas,omitempty"`
+}
+
+type Node struct {
+	Name string `json:"name"`
+}
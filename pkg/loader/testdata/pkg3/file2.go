@@ -0,0 +1,21 @@
+// +kubebuilder:skip
+
+package pkg3
+
+// Machine takes the markers of its doc comment, but not those of the file.
+// +kubebuilder:resource:path=machines
+type Machine struct {
+	Name string `json:"name"`
+}
+
+// Reset takes the markers of this comment, which are not for MachineSet.
+// +genclient
+func (m *Machine) Reset() {}
+
+// +kubebuilder:resource:scope=Cluster
+
+// MachineSet takes the markers of its doc comment and of the comments that end
+// right above it.
+type MachineSet struct {
+	Machines []Machine `json:"machines"`
+}
@@ -0,0 +1,20 @@
+package pkg4
+
+// Pod is a type with protobuf struct tags.
+type Pod struct {
+	// Name of the pod.
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+	// Labels of the pod.
+	Labels map[string]string `json:"labels,omitempty" protobuf:"bytes,2,rep,name=labels"`
+	// Containers in the pod.
+	Containers []Container `json:"containers" protobuf:"bytes,3,rep,name=containers"`
+	Priority   *int32      `json:"priority,omitempty" protobuf:"varint,4,opt,name=priority"`
+	Data       []byte      `json:"data,omitempty" protobuf:"bytes,5,opt,name=data"`
+	Local      string      `json:"local,omitempty" protobuf:"-"`
+}
+
+// Container is a container in a pod.
+type Container struct {
+	Image string  `json:"image" protobuf:"bytes,1,opt,name=image"`
+	Ports []int32 `json:"ports,omitempty" protobuf:"varint,2,rep,name=ports"`
+}
@@ -0,0 +1,16 @@
+package pkg5
+
+import "github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/scheme"
+
+const GroupName = "apps.example.com"
+
+var SchemeGroupVersion = scheme.GroupVersion{Group: GroupName, Version: "v1beta1"}
+
+func addKnownTypes(s *scheme.Scheme) error {
+	s.AddKnownTypes(SchemeGroupVersion,
+		&Deployment{},
+		&DeploymentList{},
+	)
+	s.AddKnownTypeWithName(SchemeGroupVersion.WithKind("DeploymentOptions"), &Options{})
+	return nil
+}
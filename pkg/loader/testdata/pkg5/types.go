@@ -0,0 +1,21 @@
+package pkg5
+
+// Deployment is a registered kind.
+type Deployment struct {
+	Name string `json:"name"`
+}
+
+// DeploymentList is a list of deployments.
+type DeploymentList struct {
+	Items []Deployment `json:"items"`
+}
+
+// Options is registered under a different kind.
+type Options struct {
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// DeploymentSpec is not registered.
+type DeploymentSpec struct {
+	Replicas int32 `json:"replicas"`
+}
@@ -0,0 +1,15 @@
+package pkg6
+
+import (
+	"time"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg4"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/pkg5"
+)
+
+// Cluster refers to types from packages that are not requested.
+type Cluster struct {
+	Deployments pkg5.DeploymentList  `json:"deployments"`
+	Pods        map[string]*pkg4.Pod `json:"pods,omitempty"`
+	Created     time.Time            `json:"created"`
+}
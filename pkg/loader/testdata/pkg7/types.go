@@ -0,0 +1,24 @@
+package pkg7
+
+import (
+	"encoding/json"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/loader/testdata/runtime"
+)
+
+// Kinded is implemented by objects with a kind, like runtime.Object.
+type Kinded interface {
+	GetObjectKind() string
+}
+
+// Event holds free-form JSON and embedded objects.
+type Event struct {
+	Object    runtime.Object         `json:"object"`
+	Raw       runtime.RawExtension   `json:"raw"`
+	Kinded    Kinded                 `json:"kinded"`
+	Value     interface{}            `json:"value"`
+	Payload   json.RawMessage        `json:"payload"`
+	Props     map[string]interface{} `json:"props"`
+	Name      string                 `json:"name"`
+	Reference *runtime.RawExtension  `json:"reference"`
+}
@@ -0,0 +1,11 @@
+package runtime
+
+// Object is implemented by all API objects.
+type Object interface {
+	GetObjectKind() string
+}
+
+// RawExtension holds an object in its serialized form.
+type RawExtension struct {
+	Raw []byte `json:"-"`
+}
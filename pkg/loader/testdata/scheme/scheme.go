@@ -0,0 +1,23 @@
+// Package scheme is a minimal stand in for the Kubernetes runtime scheme.
+package scheme
+
+type GroupVersion struct {
+	Group   string
+	Version string
+}
+
+func (gv GroupVersion) WithKind(kind string) GroupVersionKind {
+	return GroupVersionKind{Group: gv.Group, Version: gv.Version, Kind: kind}
+}
+
+type GroupVersionKind struct {
+	Group   string
+	Version string
+	Kind    string
+}
+
+type Scheme struct{}
+
+func (s *Scheme) AddKnownTypes(gv GroupVersion, types ...interface{}) {}
+
+func (s *Scheme) AddKnownTypeWithName(gvk GroupVersionKind, obj interface{}) {}
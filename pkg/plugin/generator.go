@@ -0,0 +1,159 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/ir"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+)
+
+func New(c Config) generator.Generator {
+	c.Logger.Debug("creating generator", "type", "plugin", "name", c.Name, "executable", c.Executable)
+	return &pluginGenerator{
+		config: c,
+	}
+}
+
+type Config struct {
+	generator.Config
+
+	// Name is the name of the plugin, without the executable prefix.
+	Name string
+	// Executable is the path to the plugin executable.
+	Executable string
+	Parameters map[string]string
+}
+
+type pluginGenerator struct {
+	config Config
+}
+
+var _ generator.Generator = &pluginGenerator{}
+
+func (g *pluginGenerator) Generate(pkgs []loader.Package) error {
+	g.config.Logger.Debug("generating")
+
+	req := Request{
+		Version:    ProtocolVersion,
+		Generator:  g.config.Name,
+		Parameters: g.config.Parameters,
+		Config: RequestConfig{
+			OutputDirectory: g.config.OutputDirectory,
+			Force:           g.config.Force,
+			Header:          g.config.Header,
+			TypeMappings:    g.config.TypeMappings[g.config.Name],
+		},
+		IR: ir.FromLoader(pkgs),
+	}
+	in, err := json.Marshal(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode plugin request")
+	}
+
+	var out bytes.Buffer
+	cmd := exec.Command(g.config.Executable)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "failed to run plugin %s", g.config.Executable)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+		return errors.Wrapf(err, "failed to decode response from plugin %s", g.config.Executable)
+	}
+	if resp.Error != "" {
+		return errors.Errorf("plugin %s failed: %s", g.config.Name, resp.Error)
+	}
+
+	return g.writeFiles(resp.Files)
+}
+
+// writeFiles checks every file name before writing any, so a bad response
+// writes nothing.
+func (g *pluginGenerator) writeFiles(files []File) error {
+	names := make([]string, 0, len(files))
+	seen := map[string]struct{}{}
+	for _, f := range files {
+		name, err := generator.CleanName(f.Name)
+		if err != nil {
+			return errors.Errorf("plugin %s returned file %q outside of the output directory", g.config.Name, f.Name)
+		}
+		if _, ok := seen[name]; ok {
+			return errors.Errorf("plugin %s returned file %s more than once", g.config.Name, name)
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+
+	for i, f := range files {
+		g.config.Logger.Debug("writing file", "file", names[i])
+		if err := g.config.Output.WriteFile(names[i], []byte(f.Content)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Discover returns the plugins found on the PATH, keyed by name. The first
+// executable found for a name wins, as with command lookup.
+func Discover() map[string]string {
+	plugins := map[string]string{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			dir = "."
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, ExecutablePrefix) || entry.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				if !strings.EqualFold(filepath.Ext(name), ".exe") {
+					continue
+				}
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			pluginName := strings.TrimPrefix(name, ExecutablePrefix)
+			if pluginName == "" {
+				continue
+			}
+			if _, ok := plugins[pluginName]; ok {
+				continue
+			}
+			fp := filepath.Join(dir, entry.Name())
+			info, err := os.Stat(fp)
+			if err != nil || info.IsDir() || (runtime.GOOS != "windows" && info.Mode()&0111 == 0) {
+				continue
+			}
+			plugins[pluginName] = fp
+		}
+	}
+	return plugins
+}
+
+// Names returns the sorted names of discovered plugins.
+func Names(plugins map[string]string) []string {
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,23 @@
+package plugin_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"os"
+	"testing"
+)
+
+// TestMain runs the test binary as a plugin when the tests run it as one.
+func TestMain(m *testing.M) {
+	if behaviour := os.Getenv(pluginEnv); behaviour != "" {
+		runPlugin(behaviour)
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func TestPlugin(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Plugin Suite")
+}
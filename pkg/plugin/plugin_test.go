@@ -0,0 +1,123 @@
+package plugin_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/inconshreveable/log15"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	. "github.com/jimmidyson/kube-client-gen/pkg/plugin"
+)
+
+// pluginEnv selects how the test binary behaves when run as a plugin.
+const pluginEnv = "KUBE_CLIENT_GEN_TEST_PLUGIN"
+
+func runPlugin(behaviour string) {
+	switch behaviour {
+	case "exit":
+		os.Exit(3)
+	case "malformed":
+		fmt.Println("not a response")
+		return
+	}
+
+	Run(func(req *Request) ([]File, error) {
+		switch behaviour {
+		case "fail":
+			return nil, errors.New("cannot generate")
+		case "escape":
+			return []File{{Name: "../outside.txt"}}, nil
+		case "duplicate":
+			return []File{{Name: "a.txt"}, {Name: "./a.txt"}}, nil
+		}
+
+		var types []string
+		for _, pkg := range req.IR.Packages {
+			for _, typ := range pkg.Types {
+				types = append(types, pkg.Path+"."+typ.Name)
+			}
+		}
+		return []File{
+			{Name: "types.txt", Content: strings.Join(types, "\n")},
+			{Name: "request/params.txt", Content: req.Generator + " " + req.Parameters["style"] + " " + req.Config.Header},
+		}, nil
+	})
+}
+
+var _ = Describe("Plugin", func() {
+	var (
+		out    *generator.MemoryOutput
+		pkgs   []loader.Package
+		logger log15.Logger
+	)
+
+	BeforeEach(func() {
+		logger = log15.New()
+		logger.SetHandler(log15.DiscardHandler())
+		out = generator.NewMemoryOutput()
+		pkgs = []loader.Package{{
+			Path:  "example.com/api/v1",
+			Types: []loader.Type{{Name: "Pod", Package: "example.com/api/v1"}},
+		}}
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(pluginEnv)
+	})
+
+	generate := func(behaviour string) error {
+		os.Setenv(pluginEnv, behaviour)
+		executable, err := os.Executable()
+		Expect(err).NotTo(HaveOccurred())
+		return New(Config{
+			Config:     generator.Config{Logger: logger, Output: out, Header: "// header"},
+			Name:       "test",
+			Executable: executable,
+			Parameters: map[string]string{"style": "plain"},
+		}).Generate(pkgs)
+	}
+
+	It("sends the request and writes the files of the response", func() {
+		Expect(generate("echo")).To(Succeed())
+		Expect(out.Files).To(Equal(map[string][]byte{
+			"types.txt":          []byte("example.com/api/v1.Pod"),
+			"request/params.txt": []byte("test plain // header"),
+		}))
+	})
+
+	It("reports errors in the response", func() {
+		err := generate("fail")
+		Expect(err).To(MatchError("plugin test failed: cannot generate"))
+		Expect(out.Files).To(BeEmpty())
+	})
+
+	It("reports plugins that exit with an error", func() {
+		err := generate("exit")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("failed to run plugin"))
+		Expect(err.Error()).To(ContainSubstring("exit status 3"))
+	})
+
+	It("reports malformed responses", func() {
+		err := generate("malformed")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("failed to decode response from plugin"))
+	})
+
+	It("rejects files outside of the output directory", func() {
+		Expect(generate("escape")).To(MatchError(`plugin test returned file "../outside.txt" outside of the output directory`))
+		Expect(out.Files).To(BeEmpty())
+	})
+
+	It("rejects files returned more than once", func() {
+		Expect(generate("duplicate")).To(MatchError("plugin test returned file a.txt more than once"))
+		Expect(out.Files).To(BeEmpty())
+	})
+})
@@ -0,0 +1,56 @@
+// Package plugin implements the protocol between kube-client-gen and external
+// generators. A plugin is an executable named kube-client-gen-<name> that reads
+// a JSON encoded Request from stdin and writes a JSON encoded Response to
+// stdout, much like protoc plugins.
+package plugin
+
+import (
+	"github.com/jimmidyson/kube-client-gen/pkg/ir"
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
+)
+
+// ProtocolVersion is the version of the request and response messages.
+// Plugins should reject requests with a version they do not understand.
+const ProtocolVersion = "v1"
+
+// ExecutablePrefix is the prefix of plugin executables on the PATH.
+const ExecutablePrefix = "kube-client-gen-"
+
+// Request is sent to a plugin on stdin.
+type Request struct {
+	Version string `json:"version"`
+	// Generator is the name the plugin was invoked as.
+	Generator string `json:"generator"`
+	// Parameters are the plugin specific parameters passed on the command line.
+	Parameters map[string]string `json:"parameters,omitempty"`
+	Config     RequestConfig     `json:"config"`
+	// IR is the loaded model.
+	IR *ir.Document `json:"ir"`
+}
+
+// RequestConfig is the generator configuration. Files are written by
+// kube-client-gen itself, so plugins only need it to tailor their output.
+type RequestConfig struct {
+	OutputDirectory string `json:"outputDirectory"`
+	Force           bool   `json:"force"`
+	// Header is the license header plugins should add to the files they
+	// generate, in the comment syntax of their formats.
+	Header string `json:"header,omitempty"`
+	// TypeMappings are the type mappings configured for the plugin's name,
+	// keyed by the package qualified names of IR types.
+	TypeMappings typemap.Table `json:"typeMappings,omitempty"`
+}
+
+// Response is read from a plugin's stdout.
+type Response struct {
+	// Error reports a generation failure; no files are written if it is set.
+	Error string `json:"error,omitempty"`
+	Files []File `json:"files,omitempty"`
+}
+
+// File is a file to write, with Name being a slash separated path relative to
+// the output directory.
+type File struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
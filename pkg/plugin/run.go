@@ -0,0 +1,32 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Run implements the plugin side of the protocol for plugins written in Go:
+// it reads the request from stdin, calls generate and writes the response to
+// stdout. Errors from generate are reported in the response.
+func Run(generate func(*Request) ([]File, error)) {
+	var req Request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to decode request: %v\n", err)
+		os.Exit(1)
+	}
+
+	var resp Response
+	if req.Version != ProtocolVersion {
+		resp.Error = fmt.Sprintf("unsupported protocol version %q, expected %q", req.Version, ProtocolVersion)
+	} else if files, err := generate(&req); err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.Files = files
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode response: %v\n", err)
+		os.Exit(1)
+	}
+}
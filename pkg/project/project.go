@@ -0,0 +1,200 @@
+// Package project reads kube-client-gen.yaml project files, which hold the
+// packages to load, how to load them and the generators to run, so that a
+// single run can build several targets from one loaded model.
+package project
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/typemap"
+	"github.com/jimmidyson/kube-client-gen/pkg/yamlutil"
+)
+
+// Version is the schema version of project files.
+const Version = 1
+
+// DefaultFile is the project file read from the working directory when none
+// is given.
+const DefaultFile = "kube-client-gen.yaml"
+
+// Config is a project file. Paths are relative to the working directory.
+type Config struct {
+	Version int `json:"version"`
+	// Packages are named sets of packages to load. All sets are loaded, and
+	// targets can restrict themselves to some of them.
+	Packages map[string][]string `json:"packages,omitempty"`
+	// Dir is the directory within the Go module to resolve packages from.
+	Dir string `json:"dir,omitempty"`
+	// Mod is the module download mode, one of readonly, vendor or mod.
+	Mod string `json:"mod,omitempty"`
+	// FromIR is an IR file to load packages from instead of type-checking
+	// them.
+	FromIR string `json:"fromIR,omitempty"`
+	// Closure adds types that loaded types refer to from other packages.
+	Closure bool `json:"closure,omitempty"`
+	// Include, Exclude and Roots filter the loaded types, see the loader's
+	// WithIncludes, WithExcludes and WithRoots options.
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+	Roots   []string `json:"roots,omitempty"`
+	// TypeMappings are the type mappings for each target, as in a type
+	// mappings file.
+	TypeMappings map[string]typemap.Table `json:"typeMappings,omitempty"`
+	// HeaderFile is a license header to add to generated files.
+	HeaderFile string `json:"headerFile,omitempty"`
+	// OutputDirectory is the directory target output directories are
+	// relative to.
+	OutputDirectory string `json:"outputDirectory,omitempty"`
+	// Force overwrites existing files.
+	Force   bool     `json:"force,omitempty"`
+	Targets []Target `json:"targets,omitempty"`
+}
+
+// Target is a generator to run.
+type Target struct {
+	// Name identifies the target, defaulting to the generator name.
+	Name string `json:"name,omitempty"`
+	// Generator is the name of the generator command, such as typescript or
+	// a plugin name.
+	Generator       string `json:"generator"`
+	OutputDirectory string `json:"outputDirectory,omitempty"`
+	// Packages restricts the target to some of the package sets, along with
+	// the packages added by the type closure. All packages are used if it is
+	// empty.
+	Packages []string `json:"packages,omitempty"`
+	// Options are the generator's flags, without leading dashes. Lists are
+	// passed as repeated flags and maps as repeated key=value flags.
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// Unmarshal decodes a project file in either YAML or JSON format.
+func Unmarshal(data []byte) (*Config, error) {
+	var c Config
+	if err := yamlutil.Unmarshal(data, &c); err != nil {
+		return nil, errors.Wrap(err, "failed to decode project file")
+	}
+	if c.Version < 1 || c.Version > Version {
+		return nil, errors.Errorf("unsupported project file version %d, expected at most %d", c.Version, Version)
+	}
+
+	names := map[string]struct{}{}
+	for i := range c.Targets {
+		t := &c.Targets[i]
+		if t.Generator == "" {
+			return nil, errors.Errorf("target %d has no generator", i+1)
+		}
+		if t.Name == "" {
+			t.Name = t.Generator
+		}
+		if _, ok := names[t.Name]; ok {
+			return nil, errors.Errorf("duplicate target %s, give targets of the same generator a name", t.Name)
+		}
+		names[t.Name] = struct{}{}
+		for _, set := range t.Packages {
+			if _, ok := c.Packages[set]; !ok {
+				return nil, errors.Errorf("target %s refers to unknown package set %s", t.Name, set)
+			}
+		}
+	}
+
+	return &c, nil
+}
+
+// ReadFile reads a project file.
+func ReadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read project file %s", path)
+	}
+	c, err := Unmarshal(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid project file %s", path)
+	}
+	return c, nil
+}
+
+// PackagesOf returns the packages of the given sets, or of every set if none
+// are given, in set name order without duplicates.
+func (c *Config) PackagesOf(sets []string) []string {
+	if len(sets) == 0 {
+		for set := range c.Packages {
+			sets = append(sets, set)
+		}
+	}
+	sets = append([]string(nil), sets...)
+	sort.Strings(sets)
+
+	var pkgs []string
+	seen := map[string]struct{}{}
+	for _, set := range sets {
+		for _, pkg := range c.Packages[set] {
+			if _, ok := seen[pkg]; ok {
+				continue
+			}
+			seen[pkg] = struct{}{}
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs
+}
+
+// Args returns the target's options as command line flags.
+func (t Target) Args() ([]string, error) {
+	names := make([]string, 0, len(t.Options))
+	for name := range t.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var args []string
+	for _, name := range names {
+		switch v := t.Options[name].(type) {
+		case []interface{}:
+			for _, elem := range v {
+				s, err := optionValue(elem)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid option %s of target %s", name, t.Name)
+				}
+				args = append(args, "--"+name+"="+s)
+			}
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				s, err := optionValue(v[key])
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid option %s of target %s", name, t.Name)
+				}
+				args = append(args, "--"+name+"="+key+"="+s)
+			}
+		default:
+			s, err := optionValue(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid option %s of target %s", name, t.Name)
+			}
+			args = append(args, "--"+name+"="+s)
+		}
+	}
+	return args, nil
+}
+
+func optionValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", errors.Errorf("unsupported value %s", fmt.Sprint(v))
+	}
+}
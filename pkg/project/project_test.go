@@ -0,0 +1,81 @@
+package project_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	. "github.com/jimmidyson/kube-client-gen/pkg/project"
+)
+
+var _ = Describe("Project", func() {
+	It("reads package sets and targets", func() {
+		c, err := Unmarshal([]byte(`
+version: 1
+packages:
+  openshift:
+    - github.com/openshift/origin/pkg/route/api/v1
+    - k8s.io/kubernetes/pkg/api/v1
+  kubernetes:
+    - k8s.io/kubernetes/pkg/api/v1
+    - k8s.io/kubernetes/pkg/apis/apps/v1alpha1
+targets:
+  - generator: typescript
+    outputDirectory: web/src/api
+  - name: kubernetes-schema
+    generator: jsonschema
+    packages: [kubernetes]
+    options:
+      title: Kubernetes
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.PackagesOf(nil)).To(Equal([]string{
+			"k8s.io/kubernetes/pkg/api/v1",
+			"k8s.io/kubernetes/pkg/apis/apps/v1alpha1",
+			"github.com/openshift/origin/pkg/route/api/v1",
+		}))
+		Expect(c.PackagesOf([]string{"openshift"})).To(Equal([]string{
+			"github.com/openshift/origin/pkg/route/api/v1",
+			"k8s.io/kubernetes/pkg/api/v1",
+		}))
+		Expect(c.Targets).To(HaveLen(2))
+		Expect(c.Targets[0].Name).To(Equal("typescript"))
+		Expect(c.Targets[1].Name).To(Equal("kubernetes-schema"))
+	})
+
+	It("converts target options to flags", func() {
+		t := Target{Name: "t", Generator: "plugin", Options: map[string]interface{}{
+			"title":   "Kubernetes",
+			"paths":   true,
+			"retries": float64(3),
+			"tags":    []interface{}{"a", "b"},
+			"param":   map[string]interface{}{"file": "api.md", "depth": float64(2)},
+		}}
+		args, err := t.Args()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(Equal([]string{
+			"--param=depth=2",
+			"--param=file=api.md",
+			"--paths=true",
+			"--retries=3",
+			"--tags=a",
+			"--tags=b",
+			"--title=Kubernetes",
+		}))
+
+		t.Options = map[string]interface{}{"nested": []interface{}{map[string]interface{}{}}}
+		_, err = t.Args()
+		Expect(err).To(MatchError(ContainSubstring("invalid option nested of target t")))
+	})
+
+	DescribeTable("rejects invalid project files",
+		func(data, message string) {
+			_, err := Unmarshal([]byte(data))
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("unsupported version", `version: 2`, "unsupported project file version 2"),
+		Entry("target without generator", `{version: 1, targets: [{outputDirectory: out}]}`, "target 1 has no generator"),
+		Entry("duplicate target", `{version: 1, targets: [{generator: python}, {generator: python}]}`, "duplicate target python"),
+		Entry("unknown package set", `{version: 1, targets: [{generator: python, packages: [apps]}]}`, "unknown package set apps"),
+	)
+})
@@ -0,0 +1,121 @@
+// Package typemap maps Go types to the types generators emit for them in a
+// target language, such as unversioned.Time to java.util.Date. Every
+// generator has default mappings that a type mappings file can extend or
+// override per target, so that types such as resource.Quantity can be mapped
+// without patching generators.
+package typemap
+
+import (
+	"encoding/json"
+	"go/types"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/loader"
+	"github.com/jimmidyson/kube-client-gen/pkg/yamlutil"
+)
+
+// Version is the schema version of type mappings files.
+const Version = 1
+
+// Mapping is the target type for a Go type.
+type Mapping struct {
+	// Type is the target type, such as java.util.Date, or the JSON schema type
+	// for the jsonschema and openapi targets.
+	Type string `json:"type,omitempty"`
+	// Format is the JSON schema format for the jsonschema and openapi targets.
+	Format string `json:"format,omitempty"`
+	// Schema is a complete JSON schema for the jsonschema and openapi targets,
+	// used instead of Type and Format.
+	Schema json.RawMessage `json:"schema,omitempty"`
+	// Imports are added to files that use the type. They are import
+	// statements for the typescript and python targets, and import paths for
+	// the proto target.
+	Imports []string `json:"imports,omitempty"`
+	// Annotations are added to fields of the type, such as the Jackson
+	// serializer and deserializer to use for the immutables target.
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// Table maps package qualified Go type names, such as
+// k8s.io/kubernetes/pkg/api/unversioned.Time, to their target types.
+type Table map[string]Mapping
+
+// Lookup returns the mapping for a named type, ignoring any vendor directory
+// in its package path.
+func (t Table) Lookup(typ types.Type) (Mapping, bool) {
+	named, ok := typ.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return Mapping{}, false
+	}
+	return t.LookupName(named.Obj().Pkg().Path(), named.Obj().Name())
+}
+
+// LookupName returns the mapping for the type typeName of the package
+// pkgPath, ignoring any vendor directory in pkgPath.
+func (t Table) LookupName(pkgPath, typeName string) (Mapping, bool) {
+	m, ok := t[loader.StripVendor(pkgPath)+"."+typeName]
+	return m, ok
+}
+
+// Maps reports whether the type typeName of the package pkgPath is mapped.
+// Generators use the target types of mapped types rather than generating
+// them, whether or not the mapped types are loaded.
+func (t Table) Maps(pkgPath, typeName string) bool {
+	_, ok := t.LookupName(pkgPath, typeName)
+	return ok
+}
+
+// Merge returns a table with the mappings of defaults, replaced or extended by
+// those of overrides.
+func Merge(defaults, overrides Table) Table {
+	merged := make(Table, len(defaults)+len(overrides))
+	for name, m := range defaults {
+		merged[name] = m
+	}
+	for name, m := range overrides {
+		merged[name] = m
+	}
+	return merged
+}
+
+// File is a type mappings file, holding a table for each target. Targets
+// are named after the generator commands, such as immutables or typescript.
+type File struct {
+	Version int              `json:"version"`
+	Targets map[string]Table `json:"targets"`
+}
+
+// Unmarshal decodes a type mappings file in either JSON or YAML format.
+func Unmarshal(data []byte) (*File, error) {
+	var f File
+	if err := yamlutil.Unmarshal(data, &f); err != nil {
+		return nil, errors.Wrap(err, "failed to decode type mappings")
+	}
+	if f.Version < 1 || f.Version > Version {
+		return nil, errors.Errorf("unsupported type mappings version %d, expected at most %d", f.Version, Version)
+	}
+	for target, table := range f.Targets {
+		for name := range table {
+			if idx := strings.LastIndex(name, "."); idx <= 0 || idx == len(name)-1 || strings.LastIndex(name, "/") > idx {
+				return nil, errors.Errorf("invalid Go type %q for target %s, expected a package qualified name such as k8s.io/kubernetes/pkg/api/unversioned.Time", name, target)
+			}
+		}
+	}
+	return &f, nil
+}
+
+// ReadFile reads a type mappings file in either JSON or YAML format.
+func ReadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read type mappings file %s", path)
+	}
+	f, err := Unmarshal(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid type mappings file %s", path)
+	}
+	return f, nil
+}
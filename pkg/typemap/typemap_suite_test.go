@@ -0,0 +1,13 @@
+package typemap_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestTypemap(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Typemap Suite")
+}
@@ -0,0 +1,86 @@
+package typemap_test
+
+import (
+	"go/token"
+	"go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/jimmidyson/kube-client-gen/pkg/typemap"
+)
+
+func namedType(pkgPath, name string) *types.Named {
+	pkg := types.NewPackage(pkgPath, name)
+	return types.NewNamed(types.NewTypeName(token.NoPos, pkg, name, nil), types.NewStruct(nil, nil), nil)
+}
+
+var _ = Describe("Typemap", func() {
+	It("reads mappings per target", func() {
+		f, err := Unmarshal([]byte(`
+version: 1
+targets:
+  immutables:
+    k8s.io/kubernetes/pkg/api/resource.Quantity:
+      type: io.fabric8.kubernetes.types.common.Quantity
+      annotations:
+        - "@com.fasterxml.jackson.databind.annotation.JsonDeserialize(using = QuantityDeserializer.class)"
+  jsonschema:
+    k8s.io/kubernetes/pkg/api/resource.Quantity:
+      schema:
+        oneOf:
+          - type: integer
+          - type: string
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Targets).To(HaveLen(2))
+		Expect(f.Targets["immutables"]).To(Equal(Table{
+			"k8s.io/kubernetes/pkg/api/resource.Quantity": {
+				Type:        "io.fabric8.kubernetes.types.common.Quantity",
+				Annotations: []string{"@com.fasterxml.jackson.databind.annotation.JsonDeserialize(using = QuantityDeserializer.class)"},
+			},
+		}))
+		Expect(f.Targets["jsonschema"]["k8s.io/kubernetes/pkg/api/resource.Quantity"].Schema).To(MatchJSON(`{"oneOf":[{"type":"integer"},{"type":"string"}]}`))
+	})
+
+	It("rejects unsupported versions", func() {
+		_, err := Unmarshal([]byte(`{"version": 2}`))
+		Expect(err).To(MatchError(ContainSubstring("unsupported type mappings version 2")))
+	})
+
+	It("rejects unqualified Go types", func() {
+		_, err := Unmarshal([]byte(`{"version": 1, "targets": {"python": {"Quantity": {"type": "str"}}}}`))
+		Expect(err).To(MatchError(ContainSubstring(`invalid Go type "Quantity" for target python`)))
+	})
+
+	It("looks up named types ignoring vendor directories", func() {
+		table := Table{"k8s.io/kubernetes/pkg/api/resource.Quantity": {Type: "str"}}
+
+		m, ok := table.Lookup(namedType("github.com/openshift/origin/vendor/k8s.io/kubernetes/pkg/api/resource", "Quantity"))
+		Expect(ok).To(BeTrue())
+		Expect(m.Type).To(Equal("str"))
+
+		_, ok = table.Lookup(types.NewPointer(namedType("k8s.io/kubernetes/pkg/api/resource", "Quantity")))
+		Expect(ok).To(BeFalse())
+		_, ok = table.Lookup(types.Typ[types.String])
+		Expect(ok).To(BeFalse())
+	})
+
+	It("reports mapped types by package and name", func() {
+		table := Table{"k8s.io/kubernetes/pkg/api/unversioned.Time": {Type: "java.util.Date"}}
+		Expect(table.Maps("k8s.io/kubernetes/pkg/api/unversioned", "Time")).To(BeTrue())
+		Expect(table.Maps("github.com/openshift/origin/vendor/k8s.io/kubernetes/pkg/api/unversioned", "Time")).To(BeTrue())
+		Expect(table.Maps("k8s.io/kubernetes/pkg/api/unversioned", "Duration")).To(BeFalse())
+	})
+
+	It("merges overrides over defaults", func() {
+		defaults := Table{"a/b.Time": {Type: "string"}, "a/b.Duration": {Type: "string"}}
+		merged := Merge(defaults, Table{"a/b.Time": {Type: "Date"}, "c/d.Quantity": {Type: "number"}})
+		Expect(merged).To(Equal(Table{
+			"a/b.Time":     {Type: "Date"},
+			"a/b.Duration": {Type: "string"},
+			"c/d.Quantity": {Type: "number"},
+		}))
+		Expect(defaults["a/b.Time"].Type).To(Equal("string"))
+	})
+})
@@ -0,0 +1,59 @@
+// Package yamlutil converts between YAML and JSON so that types only need JSON
+// struct tags to be read from and written as YAML.
+package yamlutil
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Marshal encodes v as JSON and then converts it to block style YAML, keeping
+// the order of fields.
+func Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, errors.Wrap(err, "failed to convert JSON to YAML")
+	}
+	clearStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// clearStyle drops the flow and quoting styles from parsing JSON, leaving the
+// encoder to pick block style and quote only where needed.
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+// Unmarshal converts YAML, or JSON, to JSON and decodes it into v, so v's JSON
+// struct tags apply.
+func Unmarshal(data []byte, v interface{}) error {
+	var obj interface{}
+	if err := yaml.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return errors.Wrap(err, "failed to convert YAML to JSON")
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,75 @@
+// Command kube-client-gen-markdown is an example plugin that writes a Markdown
+// API reference for the loaded packages.
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jimmidyson/kube-client-gen/pkg/generator"
+	"github.com/jimmidyson/kube-client-gen/pkg/plugin"
+)
+
+func main() {
+	plugin.Run(generate)
+}
+
+func generate(req *plugin.Request) ([]plugin.File, error) {
+	file := req.Parameters["file"]
+	if file == "" {
+		file = "api.md"
+	}
+
+	var b strings.Builder
+	// Markdown has no comments of its own, but renders HTML comments as
+	// nothing.
+	if header := (generator.Config{Header: req.Config.Header}).HeaderComment(generator.XMLComment, "", ""); header != "" {
+		b.WriteString(header + "\n")
+	}
+	b.WriteString("# API Reference\n")
+	for _, pkg := range req.IR.Packages {
+		fmt.Fprintf(&b, "\n## %s\n", pkg.Path)
+		if pkg.Doc != "" {
+			fmt.Fprintf(&b, "\n%s\n", pkg.Doc)
+		}
+
+		for _, typ := range pkg.Types {
+			fmt.Fprintf(&b, "\n### %s\n", typ.Name)
+			if typ.Doc != "" {
+				fmt.Fprintf(&b, "\n%s\n", typ.Doc)
+			}
+			if len(typ.Fields) == 0 {
+				continue
+			}
+			b.WriteString("\n| Field | Type | Required | Description |\n|---|---|---|---|\n")
+			for _, fld := range typ.Fields {
+				name := fld.JSONProperty
+				if name == "" {
+					name = fld.Name
+				}
+				required := ""
+				if fld.JSONRequired {
+					required = "yes"
+				}
+				fmt.Fprintf(&b, "| `%s` | `%s` | %s | %s |\n", name, fld.Type, required, cell(fld.Doc))
+			}
+		}
+
+		for _, enum := range pkg.Enums {
+			fmt.Fprintf(&b, "\n### %s\n", enum.Name)
+			if enum.Doc != "" {
+				fmt.Fprintf(&b, "\n%s\n", enum.Doc)
+			}
+			b.WriteString("\n| Value | Description |\n|---|---|\n")
+			for _, v := range enum.Values {
+				fmt.Fprintf(&b, "| `%s` | %s |\n", v.Value, cell(v.Doc))
+			}
+		}
+	}
+
+	return []plugin.File{{Name: file, Content: b.String()}}, nil
+}
+
+func cell(s string) string {
+	return strings.Replace(strings.Replace(s, "|", "\\|", -1), "\n", " ", -1)
+}